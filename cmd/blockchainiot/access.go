@@ -0,0 +1,314 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Access Control
+//****Description: Attribute based access control of the BlockchainIOT functions by Participant Type and MSP
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//********************************************************************************************************
+//Struct for Access Control
+//********************************************************************************************************
+
+//Rule of the access policy table for one Invoke function.
+//An empty ParticipantTypes list lets any caller of the MSPs through, an empty MSPs list accepts every MSP.
+//A rule with both lists empty lets no caller through, unless it is Public.
+type AccessRule struct {
+	Asset_Type       string   `json:"Asset_Type,omitempty"`
+	Function         string   `json:"Function"`
+	Public           bool     `json:"Public,omitempty"` // Any caller, enrolled or not
+	ParticipantTypes []string `json:"ParticipantTypes,omitempty"`
+	MSPs             []string `json:"MSPs,omitempty"`
+}
+
+//Pseudo Participant Type matched by identities registered as admin by their CA
+const adminType = "ADMIN"
+
+//Participant Types accepted in an access rule
+var validParticipantTypes = []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER", adminType}
+
+//Composite key object types of the access control records, apart from the keys of the assets
+const (
+	accessRuleObjectType = "accesspolicy"
+	identityObjectType   = "identity"
+)
+
+//Resolves the X.509 identity of the transaction creator (replaced by unit tests)
+var getCreatorIdentity = common.GetIdentity
+
+//Rules applied when the ledger holds no rule for a function.
+//Functions absent from this table and from the ledger are denied to every caller.
+var defaultAccessRules = map[string]AccessRule{
+	"createParticipant":     {Public: true},
+	"createProduct":         {ParticipantTypes: []string{"GROWER", adminType}},
+	"registerMaterial":      {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER"}},
+	"createProductionOrder": {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER"}},
+	"cancelProductionOrder": {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER", adminType}},
+	"createPurchaseOrder":   {ParticipantTypes: []string{"IMPORTER", "DISTRIBUTOR", "RETAILER"}},
+	"cancelPurchaseOrder":   {ParticipantTypes: []string{"IMPORTER", "DISTRIBUTOR", "RETAILER", adminType}},
+	"createShipment":        {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR"}},
+	"trackShipment":         {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER"}},
+	"ingestReadings":        {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER"}},
+	"createGeofence":        {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER", adminType}},
+	"registerDevice":        {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER", adminType}},
+	"attachDevice":          {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER", adminType}},
+	"recordSensorReading":   {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER"}},
+	"setTemperatureLimits":  {ParticipantTypes: []string{"GROWER", adminType}},
+	"submitGoodsReceipt":    {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER"}},
+	"splitBatch":            {ParticipantTypes: []string{"IMPORTER", "DISTRIBUTOR"}},
+	"mergeBatches":          {ParticipantTypes: []string{"IMPORTER", "DISTRIBUTOR"}},
+	"reportContamination":   {ParticipantTypes: []string{"GROWER", "IMPORTER", "DISTRIBUTOR", "RETAILER"}},
+	"clearContamination":    {ParticipantTypes: []string{"GROWER", adminType}},
+	"deleteMaterial":        {ParticipantTypes: []string{adminType}},
+	"deleteAsset":           {ParticipantTypes: []string{adminType}},
+	"setGPSRetention":       {ParticipantTypes: []string{adminType}},
+	"migrateLineage":        {ParticipantTypes: []string{adminType}},
+	"setAccessPolicy":       {ParticipantTypes: []string{adminType}},
+	"approveParticipant":    {ParticipantTypes: []string{adminType}},
+
+	// Reads, limited to the records of the caller by the functions themselves where they are owned
+	"previewContamination": {Public: true},
+	"traceForward":         {Public: true},
+	"traceBackward":        {Public: true},
+	"getMaterial":          {Public: true},
+	"getAsset":             {Public: true},
+	"getHistory":           {Public: true},
+	"getAssetAsOf":         {Public: true},
+	"getExpiringBatches":   {Public: true},
+	"customQueries":        {Public: true},
+	"verifyGPSReading":     {Public: true},
+	"getAccessPolicy":      {Public: true},
+}
+
+//Key for fetching/storing the access rule of a function
+func accessRuleKey(stub shim.ChaincodeStubInterface, function string) (string, error) {
+	return stub.CreateCompositeKey(accessRuleObjectType, []string{strings.ToLower(function)})
+}
+
+//Key for fetching/storing the binding between an identity and a Participant
+func identityKey(stub shim.ChaincodeStubInterface, identity common.Identity) (string, error) {
+	return stub.CreateCompositeKey(identityObjectType, []string{strings.ToLower(identity.MSPID), strings.ToLower(identity.EnrollmentID)})
+}
+
+//Get the rule of a function from the ledger, falling back to the default table
+func getAccessRule(stub shim.ChaincodeStubInterface, function string) (AccessRule, bool, error) {
+	key, err := accessRuleKey(stub, function)
+	if err != nil {
+		return AccessRule{}, false, err
+	}
+	value, err := stub.GetState(key)
+	if err != nil {
+		return AccessRule{}, false, err
+	}
+	if value != nil {
+		rule := AccessRule{}
+		if err := json.Unmarshal(value, &rule); err != nil {
+			return AccessRule{}, false, err
+		}
+		if rule.Asset_Type != "ACCESS POLICY" {
+			return AccessRule{}, false, fmt.Errorf("Access Rule of %s is not an ACCESS POLICY", function)
+		}
+		return rule, true, nil
+	}
+	rule, found := defaultAccessRules[function]
+	rule.Function = function
+	return rule, found, nil
+}
+
+//Get the Participant bound to an identity, nil if the identity is not enrolled
+func getBoundParticipant(stub shim.ChaincodeStubInterface, identity common.Identity) (*Participant, error) {
+	key, err := identityKey(stub, identity)
+	if err != nil {
+		return nil, err
+	}
+	participantID, err := stub.GetState(key)
+	if err != nil || participantID == nil {
+		return nil, err
+	}
+	participantValue, err := stub.GetState(common.Key(string(participantID)))
+	if err != nil || participantValue == nil {
+		return nil, err
+	}
+	participant := Participant{}
+	json.Unmarshal(participantValue, &participant)
+	return &participant, nil
+}
+
+func containsFold(list []string, value string) bool {
+	for _, element := range list {
+		if strings.EqualFold(element, value) {
+			return true
+		}
+	}
+	return false
+}
+
+//checkAccess enforces the access policy table for a function, returning why the call is rejected
+func checkAccess(stub shim.ChaincodeStubInterface, function string) (bool, string) {
+	rule, found, err := getAccessRule(stub, function)
+	if err != nil {
+		return false, err.Error()
+	}
+	if !found {
+		return false, fmt.Sprintf("No Access Rule allows %s", function)
+	}
+	if rule.Public {
+		return true, ""
+	}
+	if len(rule.ParticipantTypes) == 0 && len(rule.MSPs) == 0 {
+		return false, fmt.Sprintf("No caller may call %s", function)
+	}
+
+	identity, err := getCreatorIdentity(stub)
+	if err != nil {
+		return false, "Invalid Identity - " + err.Error()
+	}
+
+	// Check the MSP of the caller
+	if len(rule.MSPs) > 0 && !containsFold(rule.MSPs, identity.MSPID) {
+		return false, fmt.Sprintf("MSP %s may not call %s", identity.MSPID, function)
+	}
+	if len(rule.ParticipantTypes) == 0 {
+		return true, ""
+	}
+
+	// Check the Participant Type of the caller
+	if identity.IsAdmin() && containsFold(rule.ParticipantTypes, adminType) {
+		return true, ""
+	}
+	participant, err := getBoundParticipant(stub, identity)
+	if err != nil {
+		return false, err.Error()
+	}
+	if participant == nil {
+		return false, fmt.Sprintf("Identity %s/%s is not enrolled as a Participant", identity.MSPID, identity.EnrollmentID)
+	}
+	if !participant.Approved {
+		return false, fmt.Sprintf("Participant %s is waiting for the approval of an admin", participant.ParticipantID)
+	}
+	if !containsFold(rule.ParticipantTypes, participant.ParticipantType) {
+		return false, fmt.Sprintf("Participant Type %s may not call %s", participant.ParticipantType, function)
+	}
+	return true, ""
+}
+
+//********************************************************************************************************
+// Access Policy Functions
+//********************************************************************************************************
+
+// Set the Access Rule of a Function (Admin only)
+func (t *BlockchainIOT) setAccessPolicy(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		Function         string   `json:"Function"`
+		Public           bool     `json:"Public"`
+		ParticipantTypes []string `json:"ParticipantTypes"`
+		MSPs             []string `json:"MSPs"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil || queryData.Function == "" {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	// The policy function itself stays admin only so the table cannot be locked
+	if queryData.Function == "setAccessPolicy" {
+		return common.Error(http.StatusBadRequest, "Invoke Error: The Access Rule of setAccessPolicy cannot be changed")
+	}
+
+	rule := AccessRule{}
+	rule.Asset_Type = "ACCESS POLICY"
+	rule.Function = queryData.Function
+	rule.Public = queryData.Public
+	rule.MSPs = queryData.MSPs
+	for _, element := range queryData.ParticipantTypes {
+		participantType := strings.ToUpper(element)
+		if !containsFold(validParticipantTypes, participantType) {
+			return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Participant Type must be one of the following: \n 1) GROWER \n 2) IMPORTER \n 3) DISTRIBUTOR \n 4) RETAILER \n 5) ADMIN")
+		}
+		rule.ParticipantTypes = append(rule.ParticipantTypes, participantType)
+	}
+
+	// Store in Blockchain
+	key, keyErr := accessRuleKey(stub, rule.Function)
+	if keyErr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: "+keyErr.Error())
+	}
+	jsonBytes, _ := json.Marshal(rule)
+	if puterr := stub.PutState(key, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	return common.Success(http.StatusCreated, "Access Policy Updated", nil)
+}
+
+// Get the effective Access Rule of a Function
+func (t *BlockchainIOT) getAccessPolicy(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+	rule, found, err := getAccessRule(stub, args[0])
+	if err != nil {
+		return common.Error(http.StatusInternalServerError, err.Error())
+	}
+	if !found {
+		return common.Error(http.StatusNotFound, "Not Found")
+	}
+	jsonBytes, _ := json.Marshal(rule)
+	return common.Success(http.StatusOK, "OK", jsonBytes)
+}
+
+// Approve a self-enrolled Participant (Admin only) - Arguments: {"ParticipantID"}
+// Until then the Participant Type it claimed passes no rule of the access policy table
+func (t *BlockchainIOT) approveParticipant(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ParticipantID string `json:"ParticipantID"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	// Get Participant
+	participantValue, participantGetErr := stub.GetState(common.Key(queryData.ParticipantID))
+	if participantGetErr != nil || participantValue == nil {
+		return common.Error(http.StatusNotFound, "Participant Does Not Exist! Please Check Participant ID!")
+	}
+	participant := Participant{}
+	json.Unmarshal(participantValue, &participant)
+	if participant.Asset_Type != "PARTICIPANT" {
+		return common.Error(http.StatusNotFound, "Participant Does Not Exist! Please Check Participant ID!")
+	}
+	participant.Approved = true
+
+	// Store in Blockchain
+	jsonBytes, _ := json.Marshal(participant)
+	if puterr := stub.PutState(common.Key(participant.ParticipantID), jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	return common.Success(http.StatusOK, "Participant Approved", nil)
+}
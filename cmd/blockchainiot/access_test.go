@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+func TestDefaultPolicyRejectsWrongParticipantType(t *testing.T) {
+	f := newFixture(t)
+	f.participant(importer.ParticipantID, importer.ParticipantType)
+	f.as(importer.ParticipantID).mustInvoke(http.StatusForbidden, "createProduct", map[string]string{"ProductID": "PRODUCT01"})
+
+	f.product("PRODUCT01")
+	f.as(importer.ParticipantID).mustInvoke(http.StatusForbidden, "deleteAsset", "PRODUCT01")
+	f.mustInvoke(http.StatusForbidden, "clearContamination", map[string]string{"ParticipantID": grower.ParticipantID})
+	f.mustInvoke(http.StatusOK, "getAsset", "PRODUCT01")
+	f.asAdmin().mustInvoke(http.StatusNoContent, "deleteAsset", "PRODUCT01")
+}
+
+func TestUnenrolledIdentityIsForbidden(t *testing.T) {
+	f := newFixture(t)
+	f.as("stranger").mustInvoke(http.StatusForbidden, "registerMaterial", map[string]string{"ParticipantID": "GROWER01"})
+	f.identity = common.Identity{}
+	f.mustInvoke(http.StatusForbidden, "createProduct", map[string]string{"ProductID": "PRODUCT01"})
+	f.mustInvoke(http.StatusForbidden, "createParticipant", map[string]string{"ParticipantID": "GROWER01", "ParticipantType": "GROWER"})
+}
+
+func TestCreateParticipantBindsIdentity(t *testing.T) {
+	f := newFixture(t)
+	f.participant(grower.ParticipantID, grower.ParticipantType)
+
+	participant := Participant{}
+	f.getState(grower.ParticipantID, &participant)
+	if participant.MSPID != "Org1MSP" || participant.EnrollmentID != grower.ParticipantID {
+		t.Fatalf("unexpected participant %+v", participant)
+	}
+	f.mustInvoke(http.StatusConflict, "createParticipant", map[string]string{"ParticipantID": "GROWER02", "ParticipantType": "GROWER"})
+}
+
+func TestAdminEditsAccessPolicy(t *testing.T) {
+	f := newFixture(t)
+	f.participant(importer.ParticipantID, importer.ParticipantType)
+
+	// Only an admin may edit the table, and never the rule of setAccessPolicy itself
+	rule := map[string]interface{}{"Function": "createProduct", "ParticipantTypes": []string{"importer"}}
+	f.as(importer.ParticipantID).mustInvoke(http.StatusForbidden, "setAccessPolicy", rule)
+	f.asAdmin().mustInvoke(http.StatusBadRequest, "setAccessPolicy", map[string]interface{}{"Function": "createProduct", "ParticipantTypes": []string{"FARMER"}})
+	f.mustInvoke(http.StatusBadRequest, "setAccessPolicy", map[string]interface{}{"Function": "setAccessPolicy"})
+	f.mustInvoke(http.StatusCreated, "setAccessPolicy", rule)
+
+	stored := AccessRule{}
+	json.Unmarshal(f.mustInvoke(http.StatusOK, "getAccessPolicy", "createProduct").Payload, &stored)
+	if len(stored.ParticipantTypes) != 1 || stored.ParticipantTypes[0] != "IMPORTER" || stored.Asset_Type != "ACCESS POLICY" {
+		t.Fatalf("unexpected rule %+v", stored)
+	}
+
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createProduct", map[string]string{"ProductID": "PRODUCT01"})
+	f.asAdmin().mustInvoke(http.StatusForbidden, "createProduct", map[string]string{"ProductID": "PRODUCT02"})
+	f.mustInvoke(http.StatusNotFound, "getAccessPolicy", "doesNotExist")
+}
+
+func TestFunctionsWithoutRuleAreDenied(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+
+	// Reads are listed as public rules
+	stored := AccessRule{}
+	json.Unmarshal(f.as("stranger").mustInvoke(http.StatusOK, "getAccessPolicy", "getAsset").Payload, &stored)
+	if !stored.Public || stored.Function != "getAsset" {
+		t.Fatalf("unexpected rule %+v", stored)
+	}
+	f.participant(grower.ParticipantID, grower.ParticipantType)
+	f.mustInvoke(http.StatusOK, "getAsset", "PRODUCT01")
+
+	// A function missing from the table is denied to every caller, admins included
+	rule := defaultAccessRules["getAsset"]
+	delete(defaultAccessRules, "getAsset")
+	defer func() { defaultAccessRules["getAsset"] = rule }()
+	f.mustInvoke(http.StatusForbidden, "getAsset", "PRODUCT01")
+	f.asAdmin().mustInvoke(http.StatusForbidden, "getAsset", "PRODUCT01")
+}
+
+func TestAccessPolicyRestrictsMSP(t *testing.T) {
+	f := newFixture(t)
+	f.mustInvoke(http.StatusCreated, "setAccessPolicy", map[string]interface{}{"Function": "getAsset", "MSPs": []string{"Org2MSP"}})
+	f.product("PRODUCT01")
+	f.mustInvoke(http.StatusForbidden, "getAsset", "PRODUCT01")
+	f.identity.MSPID = "Org2MSP"
+	f.mustInvoke(http.StatusOK, "getAsset", "PRODUCT01")
+}
+
+func TestAccessRulesDoNotShareTheAssetKeys(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+
+	// A Participant named after the flat key of a rule is not read as the rule
+	f.participant("ACCESSPOLICY-deleteAsset", grower.ParticipantType)
+	f.as("stranger").mustInvoke(http.StatusForbidden, "deleteAsset", "PRODUCT01")
+
+	// A stored rule with neither list lets no caller through
+	f.asAdmin().mustInvoke(http.StatusCreated, "setAccessPolicy", map[string]interface{}{"Function": "getAsset"})
+	f.mustInvoke(http.StatusForbidden, "getAsset", "PRODUCT01")
+
+	// A value under the key of a rule that is not an access policy rejects the call
+	key, _ := accessRuleKey(f.stub, "getHistory")
+	f.stub.MockTransactionStart("seed")
+	f.stub.PutState(key, []byte(`{"Asset_Type":"PARTICIPANT"}`))
+	f.stub.MockTransactionEnd("seed")
+	f.mustInvoke(http.StatusForbidden, "getHistory", "PRODUCT01")
+}
+
+func TestSelfEnrolledParticipantWaitsForApproval(t *testing.T) {
+	f := newFixture(t)
+	f.participant(importer.ParticipantID, importer.ParticipantType)
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createParticipant", map[string]string{"ParticipantID": grower.ParticipantID, "ParticipantType": "GROWER"})
+
+	// The claimed Participant Type passes no rule before an admin approves it
+	f.mustInvoke(http.StatusForbidden, "createProduct", map[string]string{"ProductID": "PRODUCT01", "ProductType": "PERISHABLE"})
+	f.mustInvoke(http.StatusForbidden, "approveParticipant", map[string]string{"ParticipantID": grower.ParticipantID})
+	f.as(importer.ParticipantID).mustInvoke(http.StatusForbidden, "approveParticipant", map[string]string{"ParticipantID": grower.ParticipantID})
+	f.asAdmin().mustInvoke(http.StatusNotFound, "approveParticipant", map[string]string{"ParticipantID": "UNKNOWN"})
+	f.mustInvoke(http.StatusOK, "approveParticipant", map[string]string{"ParticipantID": grower.ParticipantID})
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createProduct", map[string]string{"ProductID": "PRODUCT01", "ProductType": "PERISHABLE"})
+}
@@ -0,0 +1,141 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - As-Of State
+//****Description: Rebuilds an asset, or a whole Product lineage, as it was at a point in time
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//Mode of getAssetAsOf that rebuilds a Product with its lineage and Materials
+const lineageMode = "LINEAGE"
+
+//********************************************************************************************************
+//Struct for As-Of State
+//********************************************************************************************************
+
+//Product lineage as it was at a point in time
+type ProductAsOf struct {
+	AsOf      string     `json:"AsOf"`
+	Product   Product    `json:"Product"`
+	Materials []Material `json:"Materials"`
+}
+
+//productAsOf rebuilds a Product from the versions of the Product, its edges and their Materials current at a time.
+//Edges are listed from the current state, edges created after the time have no version then and are left out.
+func productAsOf(stub shim.ChaincodeStubInterface, productID string, at time.Time) (*ProductAsOf, error) {
+	version, err := common.VersionAsOf(stub, at, historyDecoder, common.Key(productID))
+	if err != nil || version == nil {
+		return nil, err
+	}
+	product, isProduct := version.Value.(*Product)
+	if !isProduct {
+		return nil, nil
+	}
+
+	currentEdges, err := getProductEdges(stub, product.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	edges := []LineageEdge{}
+	for _, element := range currentEdges {
+		key, err := edgeKey(stub, element.From, element.To)
+		if err != nil {
+			return nil, err
+		}
+		edgeVersion, err := common.VersionAsOf(stub, at, historyDecoder, key)
+		if err != nil {
+			return nil, err
+		}
+		if edgeVersion != nil {
+			if edge, isEdge := edgeVersion.Value.(*LineageEdge); isEdge {
+				edges = append(edges, *edge)
+			}
+		}
+	}
+
+	result := ProductAsOf{AsOf: at.UTC().Format(time.RFC3339Nano), Materials: []Material{}}
+	for _, element := range lineageMaterials(product, edges) {
+		materialVersion, err := common.VersionAsOf(stub, at, historyDecoder, element)
+		if err != nil {
+			return nil, err
+		}
+		if materialVersion != nil {
+			if material, isMaterial := materialVersion.Value.(*Material); isMaterial {
+				result.Materials = append(result.Materials, *material)
+			}
+		}
+	}
+
+	deriveProductView(product, result.Materials, edges)
+	result.Product = *product
+	return &result, nil
+}
+
+//********************************************************************************************************
+// As-Of Functions
+//********************************************************************************************************
+
+// Get an Asset as it was at a time - Arguments: Key, RFC3339 Time, Mode (optional)
+// Mode LINEAGE rebuilds a Product with its Mappings and every referenced Material
+// Participants only read the versions, and the lineage Materials, customQueries would return them
+func (t *BlockchainIOT) getAssetAsOf(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 2 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - Two Arguments expected")
+	}
+	at, timeerr := common.ParseTime(args[1])
+	if timeerr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Time - "+timeerr.Error())
+	}
+	participantID, scopeerr := queryScope(stub)
+	if scopeerr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeerr.Error())
+	}
+
+	var result interface{}
+	if len(args) > 2 && strings.ToUpper(args[2]) == lineageMode {
+		lineage, err := productAsOf(stub, args[0], at)
+		if err != nil {
+			return common.Error(http.StatusInternalServerError, err.Error())
+		}
+		if lineage == nil {
+			return common.Error(http.StatusNotFound, "Not Found")
+		}
+
+		// The Product view is derived from every Material, only the readable ones are listed
+		materials := []Material{}
+		for _, element := range lineage.Materials {
+			if value, _ := json.Marshal(element); common.ReadableBy(assetSchemas, value, participantID) {
+				materials = append(materials, element)
+			}
+		}
+		lineage.Materials = materials
+		result = lineage
+	} else {
+		version, err := common.VersionAsOf(stub, at, historyDecoder, common.Key(args[0]))
+		if err != nil {
+			return common.Error(http.StatusInternalServerError, err.Error())
+		}
+		if version == nil {
+			return common.Error(http.StatusNotFound, "Not Found")
+		}
+		if value, _ := json.Marshal(version.Value); !common.ReadableBy(assetSchemas, value, participantID) {
+			return common.Error(http.StatusNotFound, "Not Found")
+		}
+		result = version
+	}
+	jsonBytes, _ := json.Marshal(result)
+	return common.Success(http.StatusOK, "OK", jsonBytes)
+}
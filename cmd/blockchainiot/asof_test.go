@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAssetAsOfFollowsTheHistory(t *testing.T) {
+	f := newFixture(t).recordHistory()
+	f.supplyChain("PRODUCT01", 100, grower)
+	produced := f.txTime(f.tx)
+	f.participant(importer.ParticipantID, importer.ParticipantType)
+	f.material(importer.ParticipantID, importer.MaterialID, "PRODUCT01")
+	f.trade("PO-IB1", grower, importer, 40)
+	traded := f.txTime(f.tx)
+	f.mustInvoke(http.StatusCreated, "reportContamination", map[string]string{"ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "BatchNumber": grower.BatchNumber})
+
+	asOf := func(status int32, key string, at time.Time, mode ...string) []byte {
+		t.Helper()
+		res := new(BlockchainIOT).getAssetAsOf(f.historyStub(), append([]string{key, at.Format(time.RFC3339)}, mode...))
+		if res.Status != status {
+			t.Fatalf("expected status %d for %s at %s, got %d: %s", status, key, at, res.Status, res.Message)
+		}
+		return res.Payload
+	}
+
+	f.asAdmin()
+	version := struct{ Value Material }{}
+	json.Unmarshal(asOf(http.StatusOK, grower.ParticipantID+"-"+grower.MaterialID, traded), &version)
+	if len(version.Value.Batches) != 1 || version.Value.Batches[0].IsCompromised || version.Value.Batches[0].Quantity != 60 {
+		t.Fatalf("unexpected material before the contamination %+v", version.Value)
+	}
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, grower.BatchNumber); !batch.IsCompromised {
+		t.Fatalf("expected the current batch compromised %+v", batch)
+	}
+
+	lineage := ProductAsOf{}
+	json.Unmarshal(asOf(http.StatusOK, "PRODUCT01", produced, "lineage"), &lineage)
+	if len(lineage.Product.Mappings) != 0 || len(lineage.Materials) != 1 || lineage.Materials[0].Batches[0].Quantity != 100 {
+		t.Fatalf("unexpected lineage before the trade %+v", lineage)
+	}
+	json.Unmarshal(asOf(http.StatusOK, "PRODUCT01", traded, lineageMode), &lineage)
+	if len(lineage.Product.Mappings) != 1 || len(lineage.Materials) != 2 || lineage.Product.Mappings[0].From.IsCompromised {
+		t.Fatalf("unexpected lineage after the trade %+v", lineage)
+	}
+
+	// Participants only read their own versions, and their own Materials of a lineage
+	f.as(importer.ParticipantID)
+	asOf(http.StatusNotFound, grower.ParticipantID+"-"+grower.MaterialID, traded)
+	asOf(http.StatusOK, importer.ParticipantID+"-"+importer.MaterialID, traded)
+	scoped := ProductAsOf{}
+	json.Unmarshal(asOf(http.StatusOK, "PRODUCT01", traded, lineageMode), &scoped)
+	if len(scoped.Product.Mappings) != 1 || len(scoped.Materials) != 1 || scoped.Materials[0].ParticipantID != importer.ParticipantID {
+		t.Fatalf("unexpected lineage read by %s %+v", importer.ParticipantID, scoped)
+	}
+
+	asOf(http.StatusNotFound, "PRODUCT01", f.history.start)
+	asOf(http.StatusNotFound, "PRODUCT01", f.history.start, lineageMode)
+	if res := new(BlockchainIOT).getAssetAsOf(f.historyStub(), []string{"PRODUCT01", "yesterday"}); res.Status != http.StatusBadRequest {
+		t.Fatalf("expected a bad request for an invalid time, got %d", res.Status)
+	}
+}
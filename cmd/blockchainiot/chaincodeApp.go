@@ -0,0 +1,1750 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode
+//****Description: Chaincode logic for IoT Enabled Blockchain for Supply Chain of Perishable Goods
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//Import libraries for use
+
+//********************************************************************************************************
+//Struct for blockchain
+//********************************************************************************************************
+type BlockchainIOT struct {
+}
+
+//********************************************************************************************************
+//Struct for Participants, Assets and Transactions
+//********************************************************************************************************
+
+//********************
+// ASSETS
+//********************
+
+type Product struct {
+	Asset_Type         string                   `json:"Asset_Type,omitempty"`
+	ProductID          string                   `json:"ProductID"`
+	ProductType        string                   `json:"ProductType"`
+	TotalQuantity      int                      `json:"TotalQuantity"`
+	SupplyChainMembers []MaterialDetails        `json:"SupplyChainMembers,omitempty"`
+	AllMaterials       []string                 `json:"AllMaterials,omitempty"`
+	Mappings           []Mapping                `json:"Mappings,omitempty"`
+	ReverseMappings    []ReverseMapping         `json:"ReverseMappings,omitempty"`
+	TemperatureLimits  common.TemperatureLimits `json:"TemperatureLimits"` // Cold chain range checked by recordSensorReading
+}
+
+type MaterialDetails struct {
+	ParticipantID        string `json:"ParticipantID"`
+	ParticipantType      string `json:"ParticipantType"`
+	MaterialID           string `json:"MaterialID"`
+	IsCompromised        bool   `json:"IsCompromised"`
+	PotentialCompromised bool   `json:"PotentialCompromised"`
+}
+
+type Mapping struct {
+	From BatchTradeInfo   `json:"From"`
+	To   []BatchTradeInfo `json:"To"`
+}
+
+type ReverseMapping struct {
+	To   BatchTradeInfo   `json:"To"`
+	From []BatchTradeInfo `json:"From"`
+}
+
+type BatchTradeInfo struct {
+	// Different from BatchInfo in the sence it keeps only the count of exchanged Quantity
+	ParticipantID        string   `json:"ParticipantID"`
+	MaterialID           string   `json:"MaterialID"`
+	BatchNumber          string   `json:"BatchNumber"`
+	SerialNumbers        []string `json:"SerialNumbers"`
+	Quantity             int      `json:"Quantity"`
+	IsCompromised        bool     `json:"IsCompromised"`
+	PotentialCompromised bool     `json:"PotentialCompromised"`
+	PurchaseOrders       []string `json:"PurchaseOrders,omitempty"`   // Orders that moved Quantity along a Mapping edge
+	ProductionOrders     []string `json:"ProductionOrders,omitempty"` // Orders that consumed Quantity along a Mapping edge
+	GoodsReceipts        []string `json:"GoodsReceipts,omitempty"`    // Receipts that moved Quantity along a Mapping edge
+}
+
+type Material struct {
+	Asset_Type          string      `json:"Asset_Type"`
+	MaterialID          string      `json:"MaterialID"`
+	ParticipantID       string      `json:"ParticipantID"`
+	MaterialMasterID    string      `json:"MaterialMasterID"`
+	ProductBCID         string      `json:"ProductBCID"`
+	MaterialDescription string      `json:"MaterialDescription"`
+	Plant               string      `json:"Plant"`
+	StorageLocation     string      `json:"StorageLocation"`
+	UnitOfMeasure       string      `json:"Unit"`
+	TotalQuantity       int         `json:"TotalQuantity"`     // Quantity on hand
+	AvailableQuantity   int         `json:"AvailableQuantity"` // On hand and not reserved by a Purchase Order
+	ReservedQuantity    int         `json:"ReservedQuantity"`  // Reserved by open Purchase Orders
+	ShippedQuantity     int         `json:"ShippedQuantity"`   // Shipped against Purchase Orders
+	ConsumedQuantity    int         `json:"ConsumedQuantity"`  // Consumed by Production Orders
+	Batches             []BatchInfo `json:"Batches"`
+}
+
+type BatchInfo struct {
+	ParticipantID        string   `json:"ParticipantID"`
+	MaterialID           string   `json:"MaterialID"`
+	BatchNumber          string   `json:"BatchNumber"`
+	SerialNumbers        []string `json:"SerialNumbers"`
+	Quantity             int      `json:"Quantity"`
+	AvailableQuantity    int      `json:"AvailableQuantity"`
+	ReservedQuantity     int      `json:"ReservedQuantity"`
+	ShippedQuantity      int      `json:"ShippedQuantity"`
+	ConsumedQuantity     int      `json:"ConsumedQuantity"`
+	IsCompromised        bool     `json:"IsCompromised"`
+	PotentialCompromised bool     `json:"PotentialCompromised"`
+	ProductionDate       string   `json:"ProductionDate,omitempty"`
+	ExpiryDate           string   `json:"ExpiryDate,omitempty"`
+	ShelfLifeDays        int      `json:"ShelfLifeDays,omitempty"`
+}
+
+type PurchaseOrder struct {
+	Asset_Type          string `json:"Asset_Type"`
+	POID                string `json:"POID"`
+	RequestorID         string `json:"RequestorID"`
+	RequestorMaterialID string `json:"RequestorMaterialID"`
+	VendorID            string `json:"VendorID"`
+	VendorMaterialID    string `json:"VendorMaterialID"`
+	VendorBatchNumber   string `json:"VendorBatchNumber"`
+	Quantity            int    `json:"Quantity"`
+	UnitOfMeasure       string `json:"UnitOfMeasure"`
+	NetPrice            int    `json:"NetPrice"`
+	Currency            string `json:"Currency"`
+	DeliveryDate        string `json:"DeliveryDate"`
+	TimeStamp           string `json:"TimeStamp"`
+	ShipmentExists      bool   `json:"ShipmentExists"`
+	ShipmentID          string `json:"ShipmentID"`
+	Status              string `json:"Status"`
+	Reserved            bool   `json:"Reserved,omitempty"` // Quantity reserved on the Vendor Batch, false for orders created before reservations
+}
+
+type ProductionOrder struct {
+	Asset_Type    string      `json:"Asset_Type,omitempty"`
+	POID          string      `json:"POID"`
+	ParticipantID string      `json:"ParticipantID"`
+	MaterialID    string      `json:"MaterialID"`
+	Quantity      int         `json:"Quantity"`
+	UnitOfMeasure string      `json:"UnitOfMeasure"`
+	Components    []Component `json:"Components,omitempty"` // Bill of Materials, reserved until the Goods Receipt consumes them
+	TimeStamp     string      `json:"TimeStamp,omitempty"`
+	Status        string      `json:"Status"`
+}
+
+//Input batch of a Production Order, a Material of the producing Participant
+type Component struct {
+	MaterialID  string `json:"MaterialID"`
+	BatchNumber string `json:"BatchNumber"`
+	Quantity    int    `json:"Quantity"`
+}
+
+type Shipment struct {
+	Asset_Type       string                 `json:"Asset_Type,omitempty"`
+	ShipmentID       string                 `json:"ShipmentID"`
+	ProductBCID      string                 `json:"ProductBCID"`
+	POID             string                 `json:"POID"`
+	VendorID         string                 `json:"VendorID"` // Parties of the Purchase Order, who may query the Shipment
+	RequestorID      string                 `json:"RequestorID"`
+	GPSReading       []GetGPSReading        `json:"GPSReading,omitempty"` // Latest raw readings, see GPSRetention
+	GPSTrack         []GetGPSReading        `json:"GPSTrack,omitempty"`   // Downsampled readings of the GPSSegments
+	GPSSegments      []GPSSegment           `json:"GPSSegments,omitempty"`
+	Status           string                 `json:"Status,omitempty"`
+	SensorReadings   []common.SensorReading `json:"SensorReadings,omitempty"`
+	Excursions       []common.Excursion     `json:"Excursions,omitempty"`       // Readings outside the Temperature Limits of the Product
+	Devices          []string               `json:"Devices,omitempty"`          // Devices whose signed readings are accepted
+	UnsignedReadings bool                   `json:"UnsignedReadings,omitempty"` // Opted in at creation: readings without a Device signature are accepted until a Device is attached
+	Geofences        []string               `json:"Geofences,omitempty"`        // Geofences the last GPS reading is inside
+	Milestones       []Milestone            `json:"Milestones,omitempty"`       // Arrivals into and departures from Geofences
+}
+
+//********************
+// PARTICIPANTS
+//********************
+
+type Participant struct {
+	Asset_Type      string   `json:"Asset_Type"`
+	ParticipantID   string   `json:"ParticipantID"`
+	ParticipantType string   `json:"ParticipantType"` //Valid types are: GROWER, IMPORTER, DISTRIBUTOR, RETAILERS
+	Materials       []string `json:"Materials"`
+	CompanyName     string   `json:"CompanyName"`
+	ContactEmail    string   `json:"ContactEmail"`
+	MSPID           string   `json:"MSPID,omitempty"`
+	EnrollmentID    string   `json:"EnrollmentID,omitempty"`
+	Approved        bool     `json:"Approved"`            // Set by an admin, the Participant Type is trusted from then on
+	Geofences       []string `json:"Geofences,omitempty"` // Geofences of the plants and storage locations
+}
+
+//********************
+// TRANSACTIONS
+//********************
+
+type GetGPSReading struct {
+	ShipmentID string  `json:"ShipmentID"`
+	Latitude   float64 `json:"Latitude"`
+	Longitude  float64 `json:"Longitude"`
+	Accuracy   float32 `json:"Accuracy"`
+	Timestamp  string  `json:"Timestamp"`
+}
+
+type GoodsReceipt struct {
+	Asset_Type     string   `json:"Asset_Type,omitempty"`
+	ReceivedBy     string   `json:"ReceivedBy"`
+	GRNumber       string   `json:"GRNumber"`
+	Against        string   `json:"Against"`
+	POID           string   `json:"POID"`
+	BatchNumber    string   `json:"BatchNumber"`
+	SerialNumbers  []string `json:"SerialNumbers,omitempty"`
+	ProductionDate string   `json:"ProductionDate,omitempty"`
+	ExpiryDate     string   `json:"ExpiryDate,omitempty"`
+	ShelfLifeDays  int      `json:"ShelfLifeDays,omitempty"`
+}
+
+type BatchContamination struct {
+	ParticipantID string `json:"ParticipantID"`
+	MaterialID    string `json:"MaterialID"`
+	BatchNumber   string `json:"BatchNumber"`
+	MaxDepth      int    `json:"MaxDepth,omitempty"`
+}
+
+//********************************************************************************************************
+// JSON Marchal and Unmarshal functions for Assets, Participants and Trasactions
+//********************************************************************************************************
+func (product *Product) ProductJsonToStruct(input []byte) *Product {
+	json.Unmarshal(input, product)
+	return product
+}
+
+func (product *Product) ProductStructToJson() []byte {
+	jsonbytes, _ := json.Marshal(product)
+	return jsonbytes
+}
+
+//Dates of a batch
+func (batch BatchInfo) ShelfLife() common.ShelfLife {
+	return common.ShelfLife{ProductionDate: batch.ProductionDate, ExpiryDate: batch.ExpiryDate, ShelfLifeDays: batch.ShelfLifeDays}
+}
+
+func (batch *BatchInfo) SetShelfLife(life common.ShelfLife) {
+	batch.ProductionDate = life.ProductionDate
+	batch.ExpiryDate = life.ExpiryDate
+	batch.ShelfLifeDays = life.ShelfLifeDays
+}
+
+// Recomputes the available, reserved, shipped and consumed quantities of a Material from its Batches
+func (material *Material) UpdateQuantities() {
+	material.AvailableQuantity = 0
+	material.ReservedQuantity = 0
+	material.ShippedQuantity = 0
+	material.ConsumedQuantity = 0
+	for index, element := range material.Batches {
+		element.AvailableQuantity = element.Quantity - element.ReservedQuantity
+		material.AvailableQuantity += element.AvailableQuantity
+		material.ReservedQuantity += element.ReservedQuantity
+		material.ShippedQuantity += element.ShippedQuantity
+		material.ConsumedQuantity += element.ConsumedQuantity
+		material.Batches[index] = element
+	}
+}
+
+//********************************************************************************************************
+// Main Function
+//********************************************************************************************************
+func main() {
+	if err := shim.Start(new(BlockchainIOT)); err != nil {
+		fmt.Printf("Error starting BlockchainIOT chaincode: %s", err)
+	}
+}
+
+//********************************************************************************************************
+// INIT, INVOKE And QUERY
+//********************************************************************************************************
+var logger = shim.NewLogger("chaincode")
+
+func (t *BlockchainIOT) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	_, args := stub.GetFunctionAndParameters()
+	if len(args) > 0 {
+		return common.Error(http.StatusBadRequest, "Init Error: Incorrect number of arguments - NO ARGUMENT EXPECTED")
+	}
+	return common.Success(http.StatusOK, "OK", nil)
+}
+
+func (t *BlockchainIOT) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	function, args := stub.GetFunctionAndParameters()
+
+	// Enforce the access policy table before dispatching
+	if allowed, reason := checkAccess(stub, function); !allowed {
+		logger.Warningf("Access Denied - Function '%s': %s", function, reason)
+		return common.Error(http.StatusForbidden, "Access Denied - "+reason)
+	}
+
+	// The event of the function lists the keys it wrote
+	tx := newTransaction(stub)
+	response := t.dispatch(tx, function, args)
+	if response.Status < http.StatusBadRequest {
+		if eventerr := tx.emit(); eventerr != nil {
+			return common.Error(http.StatusInternalServerError, eventerr.Error())
+		}
+	}
+	return response
+}
+
+//dispatch calls the transaction function of an Invoke
+func (t *BlockchainIOT) dispatch(stub shim.ChaincodeStubInterface, function string, args []string) peer.Response {
+	switch function {
+	case "createParticipant":
+		return t.createParticipant(stub, args)
+	case "createProduct":
+		return t.createProduct(stub, args)
+	case "registerMaterial":
+		return t.registerMaterial(stub, args)
+	case "createProductionOrder":
+		return t.createProductionOrder(stub, args)
+	case "cancelProductionOrder":
+		return t.cancelProductionOrder(stub, args)
+	case "createPurchaseOrder":
+		return t.createPurchaseOrder(stub, args)
+	case "cancelPurchaseOrder":
+		return t.cancelPurchaseOrder(stub, args)
+	case "createShipment":
+		return t.createShipment(stub, args)
+	case "trackShipment":
+		return t.trackShipment(stub, args)
+	case "ingestReadings":
+		return t.ingestReadings(stub, args)
+	case "setGPSRetention":
+		return t.setGPSRetention(stub, args)
+	case "verifyGPSReading":
+		return t.verifyGPSReading(stub, args)
+	case "createGeofence":
+		return t.createGeofence(stub, args)
+	case "registerDevice":
+		return t.registerDevice(stub, args)
+	case "attachDevice":
+		return t.attachDevice(stub, args)
+	case "recordSensorReading":
+		return t.recordSensorReading(stub, args)
+	case "setTemperatureLimits":
+		return t.setTemperatureLimits(stub, args)
+	case "submitGoodsReceipt":
+		return t.submitGoodsReceipt(stub, args)
+	case "splitBatch":
+		return t.splitBatch(stub, args)
+	case "mergeBatches":
+		return t.mergeBatches(stub, args)
+	case "reportContamination":
+		return t.reportContamination(stub, args)
+	case "clearContamination":
+		return t.clearContamination(stub, args)
+	case "previewContamination":
+		return t.previewContamination(stub, args)
+	case "migrateLineage":
+		return t.migrateLineage(stub, args)
+	case "traceForward":
+		return t.traceForward(stub, args)
+	case "traceBackward":
+		return t.traceBackward(stub, args)
+	case "getMaterial":
+		return t.getMaterial(stub, args)
+	case "deleteMaterial":
+		return t.deleteMaterial(stub, args)
+	case "getAsset":
+		return t.getAsset(stub, args)
+	case "deleteAsset":
+		return t.deleteAsset(stub, args)
+	case "getHistory":
+		return t.getHistory(stub, args)
+	case "getAssetAsOf":
+		return t.getAssetAsOf(stub, args)
+	case "getExpiringBatches":
+		return t.getExpiringBatches(stub, args)
+	case "customQueries":
+		return t.customQueries(stub, args)
+	case "setAccessPolicy":
+		return t.setAccessPolicy(stub, args)
+	case "getAccessPolicy":
+		return t.getAccessPolicy(stub, args)
+	case "approveParticipant":
+		return t.approveParticipant(stub, args)
+	default:
+		logger.Warningf("Invalid Function Call - Function '%s' does not exist", function)
+		return common.Error(http.StatusNotImplemented, "Invalid Function Call")
+	}
+}
+
+//********************************************************************************************************
+// Functions Definition
+//********************************************************************************************************
+
+// CASE 01 Create a Participant
+func (t *BlockchainIOT) createParticipant(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ParticipantID   string `json:"ParticipantID"`
+		ParticipantType string `json:"ParticipantType"`
+		CompanyName     string `json:"CompanyName"`
+		ContactEmail    string `json:"ContactEmail"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	participant := Participant{}
+	participant.Asset_Type = "PARTICIPANT"
+
+	participant.ParticipantID = queryData.ParticipantID
+	participant.ParticipantType = queryData.ParticipantType
+	participant.CompanyName = queryData.CompanyName
+	participant.ContactEmail = queryData.ContactEmail
+
+	// Check If Exists
+	participantID := common.Key(participant.ParticipantID)
+	if value, geterr := stub.GetState(participantID); !(geterr == nil && value == nil) {
+		return common.Error(http.StatusConflict, "Participant Already Exists! \n Please Specify Another ID")
+	}
+
+	// Check Participant Type
+	// Valid Participant Types are:
+	// 1. GROWER
+	// 2. IMPORTER
+	// 3. DISTRIBUTOR
+	// 4. RETAILER
+	participantType := strings.ToUpper(participant.ParticipantType)
+	if participantType != "GROWER" && participantType != "IMPORTER" && participantType != "DISTRIBUTOR" && participantType != "RETAILER" {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Participant Type must be one of the following: \n 1) GROWER \n 2) IMPORTER \n 3) DISTRIBUTOR \n 4) RETAILER")
+	}
+
+	// Bind the Participant to the identity of the caller
+	identity, iderr := getCreatorIdentity(stub)
+	if iderr != nil {
+		return common.Error(http.StatusForbidden, "Invalid Identity - "+iderr.Error())
+	}
+	bindingKey, keyErr := identityKey(stub, identity)
+	if keyErr != nil {
+		return common.Error(http.StatusBadRequest, "Invalid Identity - "+keyErr.Error())
+	}
+	if value, geterr := stub.GetState(bindingKey); !(geterr == nil && value == nil) {
+		return common.Error(http.StatusConflict, "Identity Already Enrolled as a Participant")
+	}
+	participant.MSPID = identity.MSPID
+	participant.EnrollmentID = identity.EnrollmentID
+
+	// Store in Blockchain
+	jsonBytes, _ := json.Marshal(participant)
+	if puterr := stub.PutState(participantID, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	if puterr := stub.PutState(bindingKey, []byte(participant.ParticipantID)); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	return common.Success(http.StatusCreated, "Participant Created", nil)
+}
+
+// CASE 02 Create a Product
+func (t *BlockchainIOT) createProduct(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ProductID      string   `json:"ProductID"`
+		ProductType    string   `json:"ProductType"`
+		MinTemperature *float64 `json:"MinTemperature,omitempty"`
+		MaxTemperature *float64 `json:"MaxTemperature,omitempty"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+	limits := common.TemperatureLimits{MinTemperature: queryData.MinTemperature, MaxTemperature: queryData.MaxTemperature}
+	if limitErr := limits.Validate(); limitErr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: "+limitErr.Error())
+	}
+
+	product := Product{}
+	product.Asset_Type = "PRODUCT"
+	product.TotalQuantity = 0
+
+	product.ProductID = queryData.ProductID
+	product.ProductType = queryData.ProductType
+	product.TemperatureLimits = limits
+
+	// Check If Exists
+	productID := common.Key(product.ProductID)
+	if value, geterr := stub.GetState(productID); !(geterr == nil && value == nil) {
+		return common.Error(http.StatusConflict, "Product Already Exists! \n Please Specify Another ID")
+	}
+
+	// Store in Blockchain
+	jsonBytes, _ := json.Marshal(product)
+	if puterr := stub.PutState(productID, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	return common.Success(http.StatusCreated, "Product Created", nil)
+}
+
+// CASE 03 Register a Material
+func (t *BlockchainIOT) registerMaterial(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ParticipantID       string `json:"ParticipantID"`
+		MaterialMasterID    string `json:"MaterialMasterID"`
+		ProductBCID         string `json:"ProductBCID"`
+		MaterialDescription string `json:"MaterialDescription"`
+		Plant               string `json:"Plant"`
+		StorageLocation     string `json:"StorageLocation"`
+		UnitOfMeasure       string `json:"UnitOfMeasure"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	material := Material{}
+	material.Asset_Type = "MATERIAL"
+	material.TotalQuantity = 0
+
+	material.ParticipantID = queryData.ParticipantID
+	material.MaterialMasterID = queryData.MaterialMasterID
+	material.ProductBCID = queryData.ProductBCID
+	material.MaterialDescription = queryData.MaterialDescription
+	material.Plant = queryData.Plant
+	material.StorageLocation = queryData.StorageLocation
+	material.UnitOfMeasure = queryData.UnitOfMeasure
+	material.MaterialID = material.ParticipantID + "-" + material.MaterialMasterID
+
+	// Check If Exists
+	materialID := common.Key(material.MaterialID)
+	if value, geterr := stub.GetState(materialID); !(geterr == nil && value == nil) {
+		return common.Error(http.StatusConflict, "Material Already Exists! \n Please Specify Another ID")
+	}
+
+	// Check If Product Exists and Get the Product
+	productValue, productGetErr := stub.GetState(common.Key(material.ProductBCID))
+	if productGetErr != nil || productValue == nil {
+		return common.Error(http.StatusNotFound, "Product Does Not Exists! \n Please Specify Another Product ID")
+	}
+	product := Product{}
+	json.Unmarshal(productValue, &product)
+
+	// Check If Participant Exists and Get the Participant
+	participantValue, participantGetErr := stub.GetState(common.Key(material.ParticipantID))
+	if participantGetErr != nil || participantValue == nil {
+		return common.Error(http.StatusNotFound, "Participant Does Not Exists! \n Please Specify Another Participant ID")
+	}
+	participant := Participant{}
+	json.Unmarshal(participantValue, &participant)
+
+	for _, element := range participant.Materials {
+		if strings.ToLower(element) == strings.ToLower(materialID) {
+			return common.Error(http.StatusConflict, "Material Already Present with Participant!")
+		}
+	}
+
+	for _, element := range product.AllMaterials {
+		if strings.ToLower(element) == strings.ToLower(materialID) {
+			return common.Error(http.StatusConflict, "Material Already Present with Product!")
+		}
+	}
+
+	// Register the Material to Product and Participant
+	product.AllMaterials = append(product.AllMaterials, material.MaterialID)
+	participant.Materials = append(participant.Materials, material.MaterialID)
+
+	// Store Product and Material to Blockchain
+	productJsonBytes, _ := json.Marshal(product)
+	if puterr := stub.PutState(common.Key(product.ProductID), productJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	participantJsonBytes, _ := json.Marshal(participant)
+	if puterr := stub.PutState(common.Key(participant.ParticipantID), participantJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	materialJsonBytes, _ := json.Marshal(material)
+	if puterr := stub.PutState(materialID, materialJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	return common.Success(http.StatusCreated, "Material Registered", nil)
+}
+
+// CASE 04 Create Production Order
+func (t *BlockchainIOT) createProductionOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		POID          string      `json:"POID"`
+		ParticipantID string      `json:"ParticipantID"`
+		MaterialID    string      `json:"MaterialID"`
+		Quantity      int         `json:"Quantity"`
+		UnitOfMeasure string      `json:"UnitOfMeasure"`
+		Components    []Component `json:"Components,omitempty"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	productionOrder := ProductionOrder{}
+	productionOrder.Asset_Type = "PRODUCTION ORDER"
+	productionOrder.Status = "OPEN"
+
+	productionOrder.POID = queryData.POID
+	productionOrder.ParticipantID = queryData.ParticipantID
+	productionOrder.MaterialID = queryData.MaterialID
+	productionOrder.Quantity = queryData.Quantity
+	productionOrder.UnitOfMeasure = queryData.UnitOfMeasure
+	productionOrder.Components = queryData.Components
+
+	// Check If Exists
+	productionOrderID := common.Key(productionOrder.POID)
+	if value, geterr := stub.GetState(productionOrderID); !(geterr == nil && value == nil) {
+		return common.Error(http.StatusConflict, "Production Order Already Exists! \n Please Specify Another ID")
+	}
+
+	// Check If Participant Exists
+	participantValue, participantGetErr := stub.GetState(common.Key(productionOrder.ParticipantID))
+	if participantGetErr != nil || participantValue == nil {
+		return common.Error(http.StatusNotFound, "Participant Does Not Exists! \n Please Specify Another Participant ID")
+	}
+
+	// Check If Material Exists
+	materialID := common.Key(productionOrder.ParticipantID, productionOrder.MaterialID)
+	materialValue, materialGetErr := stub.GetState(materialID)
+	if materialGetErr != nil || materialValue == nil {
+		return common.Error(http.StatusNotFound, "Material Does Not Exists! \n Please Specify Another Material ID")
+	}
+
+	// Reserve the Quantity of every Component on its Batch
+	components, status, componentErr := reserveComponents(stub, productionOrder)
+	if componentErr != nil {
+		return common.Error(status, componentErr.Error())
+	}
+
+	// Store in Blockchain
+	jsonBytes, _ := json.Marshal(productionOrder)
+	if puterr := stub.PutState(productionOrderID, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	if puterr := components.store(stub); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	return common.Success(http.StatusCreated, "Production Order Created", nil)
+}
+
+// CASE 05 Create Purchase Order
+func (t *BlockchainIOT) createPurchaseOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		POID                string `json:"POID"`
+		RequestorID         string `json:"RequestorID"`
+		RequestorMaterialID string `json:"RequestorMaterialID"`
+		VendorID            string `json:"VendorID"`
+		VendorMaterialID    string `json:"VendorMaterialID"`
+		VendorBatchNumber   string `json:"VendorBatchNumber"`
+		Quantity            int    `json:"Quantity"`
+		UnitOfMeasure       string `json:"UnitOfMeasure"`
+		NetPrice            int    `json:"NetPrice"`
+		Currency            string `json:"Currency"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	purchaseOrder := PurchaseOrder{}
+	purchaseOrder.Asset_Type = "PURCHASE ORDER"
+	purchaseOrder.Status = "OPEN"
+
+	purchaseOrder.POID = queryData.POID
+	purchaseOrder.RequestorID = queryData.RequestorID
+	purchaseOrder.RequestorMaterialID = queryData.RequestorMaterialID
+	purchaseOrder.VendorID = queryData.VendorID
+	purchaseOrder.VendorMaterialID = queryData.VendorMaterialID
+	purchaseOrder.VendorBatchNumber = queryData.VendorBatchNumber
+	purchaseOrder.Quantity = queryData.Quantity
+	purchaseOrder.UnitOfMeasure = queryData.UnitOfMeasure
+	purchaseOrder.NetPrice = queryData.NetPrice
+	purchaseOrder.Currency = queryData.Currency
+
+	// Check If Exists
+	purchaseOrderID := common.Key(purchaseOrder.POID)
+	if value, geterr := stub.GetState(purchaseOrderID); !(geterr == nil && value == nil) {
+		return common.Error(http.StatusConflict, "Purchase Order Already Exists! \n Please Specify Another ID")
+	}
+
+	// Check If Vendor and Requestor Exists
+	vendorValue, vendorGetErr := stub.GetState(common.Key(purchaseOrder.VendorID))
+	if vendorGetErr != nil || vendorValue == nil {
+		return common.Error(http.StatusNotFound, "Vendor Does Not Exists! \n Please Specify Another Vendor ID")
+	}
+
+	requestorValue, requestorGetErr := stub.GetState(common.Key(purchaseOrder.RequestorID))
+	if requestorGetErr != nil || requestorValue == nil {
+		return common.Error(http.StatusNotFound, "Requestor Does Not Exists! \n Please Specify Another Requestor ID")
+	}
+
+	// Check If Material Exists for Vendor and Requestor
+	vendorMaterialID := common.Key(purchaseOrder.VendorID, purchaseOrder.VendorMaterialID)
+	vendorMaterialValue, vendorMaterialGetErr := stub.GetState(vendorMaterialID)
+	if vendorMaterialGetErr != nil || vendorMaterialValue == nil {
+		return common.Error(http.StatusNotFound, "Vendor Material Does Not Exists! \n Please Specify Another Vendor Material ID")
+	}
+
+	requestorMaterialID := common.Key(purchaseOrder.RequestorID, purchaseOrder.RequestorMaterialID)
+	requestorMaterialValue, requestorMaterialGetErr := stub.GetState(requestorMaterialID)
+	if requestorMaterialGetErr != nil || requestorMaterialValue == nil {
+		return common.Error(http.StatusNotFound, "Requestor Material Does Not Exists! \n Please Specify Another Requestor Material ID")
+	}
+
+	// Check if Vendor Batch Exists
+	vendorMaterial := Material{}
+	json.Unmarshal(vendorMaterialValue, &vendorMaterial)
+
+	// Reserve the Quantity on the Vendor Batch
+	batchExists := false
+	for index, element := range vendorMaterial.Batches {
+		if strings.ToLower(element.BatchNumber) == strings.ToLower(purchaseOrder.VendorBatchNumber) {
+			if purchaseOrder.Quantity <= 0 || element.Quantity-element.ReservedQuantity < purchaseOrder.Quantity {
+				return common.Error(http.StatusBadRequest, "Not Enough Quantity Available in this Batch!")
+			}
+			element.ReservedQuantity += purchaseOrder.Quantity
+			vendorMaterial.Batches[index] = element
+			purchaseOrder.Reserved = true
+			batchExists = true
+			break
+		}
+	}
+
+	if batchExists == false {
+		return common.Error(http.StatusNotFound, "Vendor Batch Not Found!")
+	}
+	vendorMaterial.UpdateQuantities()
+
+	// Store in Blockchain
+	jsonBytes, _ := json.Marshal(purchaseOrder)
+	if puterr := stub.PutState(purchaseOrderID, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	vendorMaterialJsonBytes, _ := json.Marshal(vendorMaterial)
+	if puterr := stub.PutState(vendorMaterialID, vendorMaterialJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Emit Event
+	if eventerr := emitEvent(stub, ChaincodeEvent{EventType: eventPurchaseOrderCreated, Status: purchaseOrder.Status}); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	return common.Success(http.StatusCreated, "Production Order Created", nil)
+}
+
+// CASE 06 Create Shipment
+func (t *BlockchainIOT) createShipment(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ShipmentID       string `json:"ShipmentID"`
+		ProductBCID      string `json:"ProductBCID"`
+		POID             string `json:"POID"`
+		UnsignedReadings bool   `json:"UnsignedReadings"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	shipment := Shipment{}
+	shipment.Asset_Type = "SHIPMENT"
+	shipment.Status = "SHIPPING"
+
+	shipment.ShipmentID = queryData.ShipmentID
+	shipment.ProductBCID = queryData.ProductBCID
+	shipment.POID = queryData.POID
+	shipment.UnsignedReadings = queryData.UnsignedReadings
+
+	// Check If Exists
+	shipmentID := common.Key(shipment.ShipmentID)
+	if value, geterr := stub.GetState(shipmentID); !(geterr == nil && value == nil) {
+		return common.Error(http.StatusConflict, "Shipment Already Exists! \n Please Specify Another ID")
+	}
+
+	// Check if Purchase Order Exists and get the Purchase Order
+	POValue, POGetErr := stub.GetState(common.Key(shipment.POID))
+	if POGetErr != nil || POValue == nil {
+		return common.Error(http.StatusNotFound, "Purchase Order Does Not Exists! \n Please Specify Another POID")
+	}
+	purchaseOrder := PurchaseOrder{}
+	json.Unmarshal(POValue, &purchaseOrder)
+	shipment.VendorID = purchaseOrder.VendorID
+	shipment.RequestorID = purchaseOrder.RequestorID
+
+	// Check if Purchase Order is Completed
+	if purchaseOrder.Status == "COMPLETED" {
+		return common.Error(http.StatusBadRequest, "Goods are Already Delivered for this Purchase Order")
+	}
+	if purchaseOrder.Status == "CANCELLED" {
+		return common.Error(http.StatusBadRequest, "Purchase Order is Cancelled")
+	}
+
+	// Check if Shipment Exists for this Purchase Order
+	if purchaseOrder.ShipmentExists == true {
+		return common.Error(http.StatusBadRequest, "Shipment Already Exists for this Purchase Order")
+	}
+
+	purchaseOrder.ShipmentExists = true
+	purchaseOrder.ShipmentID = shipment.ShipmentID
+
+	// Get Vendor Material and the Batch of the Purchase Order
+	vendorMaterialID := common.Key(purchaseOrder.VendorID, purchaseOrder.VendorMaterialID)
+	vendorMaterialValue, vendorMaterialGetErr := stub.GetState(vendorMaterialID)
+	if vendorMaterialGetErr != nil || vendorMaterialValue == nil {
+		return common.Error(http.StatusNotFound, "Vendor Material Does Not Exist! Please Check the Purchase Order!")
+	}
+	vendorMaterial := Material{}
+	json.Unmarshal(vendorMaterialValue, &vendorMaterial)
+	index := findBatch(&vendorMaterial, purchaseOrder.VendorBatchNumber)
+	if index < 0 {
+		return common.Error(http.StatusNotFound, "Vendor Batch Does Not Exist! Please Check the Purchase Order!")
+	}
+	batch := vendorMaterial.Batches[index]
+
+	// Batches past their Expiry Date cannot be Shipped
+	txTime, timeErr := common.TxTime(stub)
+	if timeErr != nil {
+		return common.Error(http.StatusInternalServerError, timeErr.Error())
+	}
+	if batch.ShelfLife().Expired(txTime) {
+		return common.Error(http.StatusBadRequest, "Batch Expired on "+batch.ExpiryDate+" and cannot be Shipped!")
+	}
+	if purchaseOrder.Reserved {
+		if batch.ReservedQuantity < purchaseOrder.Quantity || batch.Quantity < purchaseOrder.Quantity {
+			return common.Error(http.StatusBadRequest, "Not Enough Quantity Reserved in this Batch!")
+		}
+	} else if batch.Quantity-batch.ReservedQuantity < purchaseOrder.Quantity {
+		// Purchase Orders created before reservations ship from the Quantity no order has reserved
+		return common.Error(http.StatusBadRequest, "Not Enough Quantity Available in this Batch!")
+	}
+
+	// Update Vendor Material
+	// Convert the Reservation of the Purchase Order into a Shipped Quantity
+	batch.Quantity -= purchaseOrder.Quantity
+	if purchaseOrder.Reserved {
+		batch.ReservedQuantity -= purchaseOrder.Quantity
+	}
+	batch.ShippedQuantity += purchaseOrder.Quantity
+	vendorMaterial.Batches[index] = batch
+	vendorMaterial.TotalQuantity -= purchaseOrder.Quantity
+	vendorMaterial.UpdateQuantities()
+
+	// Store in Blockchain
+	shipmentJsonBytes, _ := json.Marshal(shipment)
+	if puterr := stub.PutState(common.Key(shipment.ShipmentID), shipmentJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	purchaseOrderJsonBytes, _ := json.Marshal(purchaseOrder)
+	if puterr := stub.PutState(common.Key(purchaseOrder.POID), purchaseOrderJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	vendorMaterialJsonBytes, _ := json.Marshal(vendorMaterial)
+	if puterr := stub.PutState(vendorMaterialID, vendorMaterialJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Emit Event
+	if eventerr := emitEvent(stub, ChaincodeEvent{EventType: eventShipmentCreated, Status: shipment.Status}); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	return common.Success(http.StatusCreated, "Shipment Created", nil)
+}
+
+// CASE 07 Track Shipment
+func (t *BlockchainIOT) trackShipment(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ShipmentID string  `json:"ShipmentID"`
+		Latitude   float64 `json:"Latitude"`
+		Longitude  float64 `json:"Longitude"`
+		Accuracy   float32 `json:"Accuracy"`
+		Timestamp  string  `json:"Timestamp"`
+		common.DeviceSignature
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	gpsReading := GetGPSReading{}
+
+	gpsReading.ShipmentID = queryData.ShipmentID
+	gpsReading.Latitude = queryData.Latitude
+	gpsReading.Longitude = queryData.Longitude
+	gpsReading.Accuracy = queryData.Accuracy
+	gpsReading.Timestamp = queryData.Timestamp
+
+	// Check if Shipment Exists and Get Shipment
+	shipmentValue, shipmentGetErr := stub.GetState(common.Key(gpsReading.ShipmentID))
+	if shipmentGetErr != nil || shipmentValue == nil {
+		return common.Error(http.StatusNotFound, "Shipment Does Not Exists! \n Please Specify Another Shipment ID")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentValue, &shipment)
+
+	// Check if Shipment is Completed
+	if shipment.Status == "COMPLETED" {
+		return common.Error(http.StatusBadRequest, "Shipment is already Completed")
+	}
+
+	// Check the Signature of the Device
+	devices := newDeviceReadings()
+	if verifyErr := devices.verify(stub, shipment, queryData.DeviceSignature, gpsFields(gpsReading)...); verifyErr != nil {
+		return common.Error(http.StatusUnauthorized, "Invoke Error: "+verifyErr.Error())
+	}
+	if puterr := devices.store(stub); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Enter Location for Shipment, older readings are rolled up into segments
+	retention, retentionErr := getGPSRetention(stub)
+	if retentionErr != nil {
+		return common.Error(http.StatusInternalServerError, retentionErr.Error())
+	}
+	shipment.GPSReading = append(shipment.GPSReading, gpsReading)
+	shipment.retainGPS(retention)
+
+	// Record the Geofences the Reading entered or left
+	route, routeErr := loadShipmentRoute(stub, shipment)
+	if routeErr != nil {
+		return common.Error(http.StatusInternalServerError, routeErr.Error())
+	}
+	previousStatus := shipment.Status
+	milestones := shipment.crossGeofences(route, gpsReading)
+
+	// Store Updated Shipment in Blockchain
+	shipmentJsonBytes, _ := json.Marshal(shipment)
+	if puterr := stub.PutState(common.Key(shipment.ShipmentID), shipmentJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Emit Event
+	event := ChaincodeEvent{EventType: eventShipmentTracked, PreviousStatus: previousStatus, Status: shipment.Status}
+	if len(milestones) > 0 {
+		event.EventType = eventGeofenceCrossed
+		milestoneEvent(&event, milestones)
+	}
+	if eventerr := emitEvent(stub, event); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	return common.Success(http.StatusCreated, "Shipment Location Updated", nil)
+}
+
+// CASE 08 Submit Goods Receipt
+func (t *BlockchainIOT) submitGoodsReceipt(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		GRNumber       string   `json:"GRNumber"`
+		ReceivedBy     string   `json:"ReceivedBy"`
+		Against        string   `json:"Against"`
+		POID           string   `json:"POID"`
+		BatchNumber    string   `json:"BatchNumber"`
+		SerialNumbers  []string `json:"SerialNumbers,omitempty"`
+		ProductionDate string   `json:"ProductionDate,omitempty"`
+		ExpiryDate     string   `json:"ExpiryDate,omitempty"`
+		ShelfLifeDays  int      `json:"ShelfLifeDays,omitempty"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+	shelfLife, shelfLifeErr := common.ShelfLife{ProductionDate: queryData.ProductionDate, ExpiryDate: queryData.ExpiryDate, ShelfLifeDays: queryData.ShelfLifeDays}.Normalize()
+	if shelfLifeErr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - "+shelfLifeErr.Error())
+	}
+
+	goodsReceipt := GoodsReceipt{}
+	goodsReceipt.Asset_Type = "Goods Receipt"
+
+	goodsReceipt.GRNumber = queryData.GRNumber
+	goodsReceipt.ReceivedBy = queryData.ReceivedBy
+	goodsReceipt.Against = queryData.Against
+	goodsReceipt.POID = queryData.POID
+	goodsReceipt.BatchNumber = queryData.BatchNumber
+	goodsReceipt.SerialNumbers = queryData.SerialNumbers
+	goodsReceipt.ProductionDate = shelfLife.ProductionDate
+	goodsReceipt.ExpiryDate = shelfLife.ExpiryDate
+	goodsReceipt.ShelfLifeDays = shelfLife.ShelfLifeDays
+
+	if strings.ToUpper(goodsReceipt.Against) == "PRODUCTION ORDER" {
+		// Check If Production Order Exists and Get the Order
+		POValue, POGetErr := stub.GetState(common.Key(goodsReceipt.POID))
+		if POGetErr != nil || POValue == nil {
+			return common.Error(http.StatusNotFound, "Production Order Does Not Exists! \n Please Specify Another POID")
+		}
+		productionOrder := ProductionOrder{}
+		json.Unmarshal(POValue, &productionOrder)
+
+		// Check the Status of Order
+		if productionOrder.Status == "COMPLETED" {
+			return common.Error(http.StatusBadRequest, "Goods Already Received for this Production Order")
+		}
+		if productionOrder.Status == "CANCELLED" {
+			return common.Error(http.StatusBadRequest, "Production Order is Cancelled")
+		}
+		if productionOrder.Status != "OPEN" {
+			return common.Error(http.StatusBadRequest, "Production Order is not OPEN")
+		}
+
+		// Check for Valid Receiver
+		if strings.ToLower(goodsReceipt.ReceivedBy) != strings.ToLower(productionOrder.ParticipantID) {
+			return common.Error(http.StatusBadRequest, "Not a Valid Receiver for this Production Order")
+		}
+
+		// Get Material, the Components are read into the same set
+		materials := newMaterialSet()
+		materialID := common.Key(productionOrder.ParticipantID, productionOrder.MaterialID)
+		material, materialGetErr := materials.load(stub, materialID)
+		if materialGetErr != nil || material == nil {
+			return common.Error(http.StatusNotFound, "Material Does Not Exists! \n Please Specify Another Material ID")
+		}
+
+		// Get Participant
+		participantValue, _ := stub.GetState(common.Key(material.ParticipantID))
+		participant := Participant{}
+		json.Unmarshal(participantValue, &participant)
+
+		// Get Product
+		productValue, _ := stub.GetState(common.Key(material.ProductBCID))
+		product := Product{}
+		json.Unmarshal(productValue, &product)
+
+		// Update Product
+		// Step 1: Add Quantity to Total Product Quantity
+		product.TotalQuantity += productionOrder.Quantity
+		// Step 2: Add Participant, BatchInfo and Material Details
+		batchInfo := BatchInfo{}
+		batchInfo.ParticipantID = productionOrder.ParticipantID
+		batchInfo.MaterialID = productionOrder.MaterialID
+		batchInfo.BatchNumber = goodsReceipt.BatchNumber
+		batchInfo.SerialNumbers = goodsReceipt.SerialNumbers
+		batchInfo.Quantity = productionOrder.Quantity
+		batchInfo.IsCompromised = false
+		batchInfo.PotentialCompromised = false
+		batchInfo.SetShelfLife(shelfLife)
+
+		// Consume the Components into the Batch, which inherits their contamination
+		output := BatchTradeInfo{ParticipantID: batchInfo.ParticipantID, MaterialID: batchInfo.MaterialID, BatchNumber: batchInfo.BatchNumber, SerialNumbers: batchInfo.SerialNumbers}
+		isCompromised, potentialCompromised, componentErr := consumeComponents(stub, materials, productionOrder, output, product.ProductID, goodsReceipt.GRNumber)
+		if componentErr != nil {
+			return common.Error(http.StatusBadRequest, componentErr.Error())
+		}
+		batchInfo.IsCompromised = isCompromised
+		batchInfo.PotentialCompromised = potentialCompromised
+
+		materialDetails := MaterialDetails{}
+		materialDetails.ParticipantID = productionOrder.ParticipantID
+		materialDetails.ParticipantType = participant.ParticipantType
+		materialDetails.MaterialID = productionOrder.MaterialID
+		materialDetails.IsCompromised = false
+		materialDetails.PotentialCompromised = false
+
+		participantExists := false
+		for _, element := range product.SupplyChainMembers {
+			if strings.ToLower(element.ParticipantID) == strings.ToLower(productionOrder.ParticipantID) {
+				participantExists = true
+				break
+			}
+		}
+		if participantExists == false {
+			product.SupplyChainMembers = append(product.SupplyChainMembers, materialDetails)
+		}
+
+		// Update Material
+		// Step 1: Add Quantity to Material
+		material.TotalQuantity += productionOrder.Quantity
+		// Step 2: Update Batch
+		materialBatchExists := false
+
+		for index, element := range material.Batches {
+			if strings.ToLower(element.BatchNumber) == strings.ToLower(goodsReceipt.BatchNumber) {
+				element.Quantity += productionOrder.Quantity
+				element.SetShelfLife(element.ShelfLife().Earliest(shelfLife))
+				element.IsCompromised = element.IsCompromised || isCompromised
+				element.PotentialCompromised = !element.IsCompromised && (element.PotentialCompromised || potentialCompromised)
+				material.Batches[index] = element
+				materialBatchExists = true
+				break
+			}
+		}
+		if materialBatchExists == false {
+			material.Batches = append(material.Batches, batchInfo)
+		}
+		material.UpdateQuantities()
+
+		// Update Production Order Status
+		previousStatus := productionOrder.Status
+		productionOrder.Status = "COMPLETED"
+
+		// Store Data into Blockchain (Update Product and Material)
+		POjsonBytes, _ := json.Marshal(productionOrder)
+		if puterr := stub.PutState(common.Key(productionOrder.POID), POjsonBytes); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+
+		ProductjsonBytes, _ := json.Marshal(product)
+		if puterr := stub.PutState(common.Key(product.ProductID), ProductjsonBytes); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+
+		if puterr := materials.store(stub); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+
+		GRjsonBytes, _ := json.Marshal(goodsReceipt)
+		if puterr := stub.PutState(common.Key(goodsReceipt.GRNumber), GRjsonBytes); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+
+		// Emit Event
+		if eventerr := emitEvent(stub, ChaincodeEvent{EventType: eventGoodsReceived, PreviousStatus: previousStatus, Status: productionOrder.Status}); eventerr != nil {
+			return common.Error(http.StatusInternalServerError, eventerr.Error())
+		}
+		return common.Success(http.StatusCreated, "Goods Received Against Production Order", nil)
+
+	} else if strings.ToUpper(goodsReceipt.Against) == "PURCHASE ORDER" {
+		// Check If Purchase Order Exists and Get the Order
+		POValue, POGetErr := stub.GetState(common.Key(goodsReceipt.POID))
+		if POGetErr != nil || POValue == nil {
+			return common.Error(http.StatusNotFound, "Purchase Order Does Not Exists! \n Please Specify Another POID")
+		}
+		purchaseOrder := PurchaseOrder{}
+		json.Unmarshal(POValue, &purchaseOrder)
+
+		// Check the Status of Order
+		if purchaseOrder.Status == "COMPLETED" {
+			return common.Error(http.StatusBadRequest, "Goods Already Received for this Purchase Order")
+		}
+		if purchaseOrder.Status == "CANCELLED" {
+			return common.Error(http.StatusBadRequest, "Purchase Order is Cancelled")
+		}
+
+		// Check for Valid Receiver
+		if strings.ToLower(goodsReceipt.ReceivedBy) != strings.ToLower(purchaseOrder.RequestorID) {
+			return common.Error(http.StatusBadRequest, "Not a Valid Receiver for this Production Order")
+		}
+
+		// Get Materials
+		vendorMaterialID := common.Key(purchaseOrder.VendorID, purchaseOrder.VendorMaterialID)
+		vendorMaterialValue, _ := stub.GetState(vendorMaterialID)
+		vendorMaterial := Material{}
+		json.Unmarshal(vendorMaterialValue, &vendorMaterial)
+
+		receiverMaterialID := common.Key(purchaseOrder.RequestorID, purchaseOrder.RequestorMaterialID)
+		receiverMaterialValue, _ := stub.GetState(receiverMaterialID)
+		receiverMaterial := Material{}
+		json.Unmarshal(receiverMaterialValue, &receiverMaterial)
+
+		// Get Participants
+		vendorParticipantValue, _ := stub.GetState(common.Key(purchaseOrder.VendorID))
+		vendor := Participant{}
+		json.Unmarshal(vendorParticipantValue, &vendor)
+
+		receiverParticipantValue, _ := stub.GetState(common.Key(purchaseOrder.RequestorID))
+		receiver := Participant{}
+		json.Unmarshal(receiverParticipantValue, &receiver)
+
+		// Get Shipment
+		shipmentValue, _ := stub.GetState(common.Key(purchaseOrder.ShipmentID))
+		shipment := Shipment{}
+		json.Unmarshal(shipmentValue, &shipment)
+
+		// Get Product
+		productValue, _ := stub.GetState(common.Key(receiverMaterial.ProductBCID))
+		product := Product{}
+		json.Unmarshal(productValue, &product)
+
+		// Update Materials
+		// Step 1: Update Receiver Material
+		// Get Vendor Batch Info
+		vendorbatchInfo := BatchInfo{}
+		for _, element := range vendorMaterial.Batches {
+			if strings.ToLower(element.BatchNumber) == strings.ToLower(purchaseOrder.VendorBatchNumber) {
+				vendorbatchInfo = element
+			}
+		}
+
+		// Create Receiver Batch Info
+		receiverbatchInfo := BatchInfo{}
+		receiverbatchInfo.ParticipantID = purchaseOrder.RequestorID
+		receiverbatchInfo.MaterialID = purchaseOrder.RequestorMaterialID
+		receiverbatchInfo.BatchNumber = goodsReceipt.BatchNumber
+		receiverbatchInfo.SerialNumbers = goodsReceipt.SerialNumbers
+		receiverbatchInfo.Quantity = purchaseOrder.Quantity
+		receiverbatchInfo.IsCompromised = vendorbatchInfo.IsCompromised
+		receiverbatchInfo.PotentialCompromised = vendorbatchInfo.PotentialCompromised || len(shipment.Excursions) > 0
+
+		if receiverbatchInfo.IsCompromised == true {
+			receiverbatchInfo.PotentialCompromised = false
+		}
+
+		// The Dates are inherited from the Vendor Batch, the earliest expiry wins
+		receiverbatchInfo.SetShelfLife(vendorbatchInfo.ShelfLife().Earliest(shelfLife))
+
+		// Step 2: Update Total Quantity of Receiver Material
+		receiverMaterial.TotalQuantity += purchaseOrder.Quantity
+
+		// Step 3: Check if Batch Exists or Add New Batch with Updated Quantity
+		receiverBatchExists := false
+		for index, element := range receiverMaterial.Batches {
+			if strings.ToLower(element.BatchNumber) == strings.ToLower(goodsReceipt.BatchNumber) {
+				element.Quantity += purchaseOrder.Quantity
+				element.SetShelfLife(element.ShelfLife().Earliest(receiverbatchInfo.ShelfLife()))
+				receiverBatchExists = true
+				receiverMaterial.Batches[index] = element
+				break
+			}
+		}
+		if receiverBatchExists == false {
+			receiverMaterial.Batches = append(receiverMaterial.Batches, receiverbatchInfo)
+		}
+		receiverMaterial.UpdateQuantities()
+
+		// Update Lineage
+		// Record the trade edge under its own key, the Product mappings are derived from the edges
+		batchTradeInfoFROM := BatchTradeInfo{}
+		batchTradeInfoFROM.ParticipantID = vendorbatchInfo.ParticipantID
+		batchTradeInfoFROM.MaterialID = vendorbatchInfo.MaterialID
+		batchTradeInfoFROM.BatchNumber = vendorbatchInfo.BatchNumber
+		batchTradeInfoFROM.SerialNumbers = vendorbatchInfo.SerialNumbers
+
+		batchTradeInfoTO := BatchTradeInfo{}
+		batchTradeInfoTO.ParticipantID = receiverbatchInfo.ParticipantID
+		batchTradeInfoTO.MaterialID = receiverbatchInfo.MaterialID
+		batchTradeInfoTO.BatchNumber = receiverbatchInfo.BatchNumber
+		batchTradeInfoTO.SerialNumbers = receiverbatchInfo.SerialNumbers
+
+		if puterr := putLineageEdge(stub, []string{product.ProductID}, batchTradeInfoFROM, batchTradeInfoTO, purchaseOrder.Quantity, LineageOrder{Against: "PURCHASE ORDER", POID: purchaseOrder.POID, GRNumber: goodsReceipt.GRNumber}); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+
+		// Update Participant in Product
+		// See if Vendor Exists
+		vendorMaterialExists := false
+		for _, element := range product.SupplyChainMembers {
+			if strings.ToLower(element.ParticipantID) == strings.ToLower(purchaseOrder.VendorID) {
+				vendorMaterialExists = true
+			}
+		}
+		if vendorMaterialExists == false {
+			return common.Error(http.StatusBadRequest, "Vendor Material Does Not Exist in Product Information! Register a Material First and Produce some Quantity")
+		}
+
+		receiverMaterialDetail := MaterialDetails{}
+		receiverMaterialDetail.ParticipantID = receiverMaterial.ParticipantID
+		receiverMaterialDetail.MaterialID = receiverMaterial.MaterialMasterID
+		receiverMaterialDetail.ParticipantType = receiver.ParticipantType
+
+		participantExists := false
+		for _, element := range product.SupplyChainMembers {
+			if strings.ToLower(element.ParticipantID) == strings.ToLower(receiver.ParticipantID) {
+				participantExists = true
+				break
+			}
+		}
+		if participantExists == false {
+			product.SupplyChainMembers = append(product.SupplyChainMembers, receiverMaterialDetail)
+		}
+
+		// Update Shipment
+		shipment.Status = "COMPLETED"
+
+		//Complete Purchase Order
+		previousStatus := purchaseOrder.Status
+		purchaseOrder.Status = "COMPLETED"
+
+		// Store Information in Blockchain
+		POjsonBytes, _ := json.Marshal(purchaseOrder)
+		if puterr := stub.PutState(common.Key(purchaseOrder.POID), POjsonBytes); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+
+		ShipmentjsonBytes, _ := json.Marshal(shipment)
+		if puterr := stub.PutState(common.Key(shipment.ShipmentID), ShipmentjsonBytes); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+
+		// The Product is only written when a new Supply Chain Member joins
+		if participantExists == false {
+			ProductjsonBytes, _ := json.Marshal(product)
+			if puterr := stub.PutState(common.Key(product.ProductID), ProductjsonBytes); puterr != nil {
+				return common.Error(http.StatusInternalServerError, puterr.Error())
+			}
+		}
+
+		vendorMaterialjsonBytes, _ := json.Marshal(vendorMaterial)
+		if puterr := stub.PutState(vendorMaterialID, vendorMaterialjsonBytes); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+
+		receiverMaterialjsonBytes, _ := json.Marshal(receiverMaterial)
+		if puterr := stub.PutState(receiverMaterialID, receiverMaterialjsonBytes); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+
+		GRjsonBytes, _ := json.Marshal(goodsReceipt)
+		if puterr := stub.PutState(common.Key(goodsReceipt.GRNumber), GRjsonBytes); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+
+		// Emit Event
+		if eventerr := emitEvent(stub, ChaincodeEvent{EventType: eventGoodsReceived, PreviousStatus: previousStatus, Status: purchaseOrder.Status}); eventerr != nil {
+			return common.Error(http.StatusInternalServerError, eventerr.Error())
+		}
+		return common.Success(http.StatusCreated, "Goods Received Against Production Order", nil)
+	} else {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data. Currently, Valid GR Types are Against: \n 1) PRODUCTION ORDER \n 2) PURCHASE ORDER ")
+	}
+}
+
+// CASE 09 Report Contamination
+func (t *BlockchainIOT) reportContamination(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ParticipantID string `json:"ParticipantID"`
+		MaterialID    string `json:"MaterialID"`
+		BatchNumber   string `json:"BatchNumber"`
+		MaxDepth      int    `json:"MaxDepth"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	contaminatedBatch := BatchContamination{}
+	contaminatedBatch.ParticipantID = queryData.ParticipantID
+	contaminatedBatch.MaterialID = queryData.MaterialID
+	contaminatedBatch.BatchNumber = queryData.BatchNumber
+	contaminatedBatch.MaxDepth = queryData.MaxDepth
+
+	// Get Material
+	materialID := common.Key(contaminatedBatch.ParticipantID, contaminatedBatch.MaterialID)
+	materialValue, materialGetErr := stub.GetState(materialID)
+	if materialGetErr != nil || materialValue == nil {
+		return common.Error(http.StatusNotFound, "Material Does Not Exist! Please Check Participant ID and Material ID!")
+	}
+
+	// Walk the lineage once and store every affected Material
+	impact, err := assessContamination(stub, contaminatedBatch)
+	if err != nil {
+		return common.Error(http.StatusInternalServerError, err.Error())
+	}
+	if puterr := applyContamination(stub, impact, false); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Emit the Recall Event listing every affected Participant
+	if eventerr := emitEvent(stub, contaminationEvent(eventRecall, "COMPROMISED", impact)); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	return common.Success(http.StatusCreated, "Product Updated", nil)
+}
+
+// CASE 10 Clear Contamination
+func (t *BlockchainIOT) clearContamination(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ParticipantID string `json:"ParticipantID"`
+		MaterialID    string `json:"MaterialID"`
+		BatchNumber   string `json:"BatchNumber"`
+		MaxDepth      int    `json:"MaxDepth"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	contaminatedBatch := BatchContamination{}
+	contaminatedBatch.ParticipantID = queryData.ParticipantID
+	contaminatedBatch.MaterialID = queryData.MaterialID
+	contaminatedBatch.BatchNumber = queryData.BatchNumber
+	contaminatedBatch.MaxDepth = queryData.MaxDepth
+
+	// Get Material
+	materialID := common.Key(contaminatedBatch.ParticipantID, contaminatedBatch.MaterialID)
+	materialValue, materialGetErr := stub.GetState(materialID)
+	if materialGetErr != nil || materialValue == nil {
+		return common.Error(http.StatusNotFound, "Material Does Not Exist! Please Check Participant ID and Material ID!")
+	}
+
+	// Walk the lineage once and store every affected Material
+	impact, err := assessContamination(stub, contaminatedBatch)
+	if err != nil {
+		return common.Error(http.StatusInternalServerError, err.Error())
+	}
+	if puterr := applyContamination(stub, impact, true); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Emit Event
+	if eventerr := emitEvent(stub, contaminationEvent(eventContaminationCleared, "CLEARED", impact)); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	return common.Success(http.StatusCreated, "Product Updated", nil)
+}
+
+// CASE 11 Get Materials
+// Participants only read their own Materials
+func (t *BlockchainIOT) getMaterial(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+	data := string(args[0])
+	data1 := string(args[1])
+
+	materialID := common.Key(data, data1)
+	participantID, scopeerr := queryScope(stub)
+	if scopeerr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeerr.Error())
+	}
+
+	//Get the Material from Blockchain
+	value, geterr := stub.GetState(materialID)
+	if geterr != nil || value == nil || !common.ReadableBy(assetSchemas, value, participantID) {
+		return common.Error(http.StatusNotFound, "Not Found")
+	}
+	return common.Success(http.StatusOK, "OK", value)
+}
+
+// CASE 12 Delete Material Asset
+func (t *BlockchainIOT) deleteMaterial(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+	data := string(args[0])
+	data1 := string(args[1])
+
+	materialID := common.Key(data, data1)
+
+	// Check if Exists
+	value, geterr := stub.GetState(materialID)
+	if geterr != nil || value == nil {
+		return common.Error(http.StatusNotFound, "Not Found")
+	}
+
+	// Delete if Exists
+	if delerr := stub.DelState(materialID); delerr != nil {
+		return common.Error(http.StatusInternalServerError, delerr.Error())
+	}
+	return common.Success(http.StatusNoContent, "Material Deleted", nil)
+}
+
+// CASE 13 Get Any Asset
+// Participants only read the records customQueries would return them
+func (t *BlockchainIOT) getAsset(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+	data := common.Key(args[0])
+	participantID, scopeerr := queryScope(stub)
+	if scopeerr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeerr.Error())
+	}
+
+	//Get the Asset from Blockchain
+	value, geterr := stub.GetState(data)
+	if geterr != nil || value == nil || !common.ReadableBy(assetSchemas, value, participantID) {
+		return common.Error(http.StatusNotFound, "Not Found")
+	}
+
+	// Products are returned with their derived lineage
+	product := Product{}
+	if json.Unmarshal(value, &product); product.Asset_Type == "PRODUCT" {
+		if viewerr := productView(stub, &product); viewerr != nil {
+			return common.Error(http.StatusInternalServerError, viewerr.Error())
+		}
+		value, _ = json.Marshal(product)
+	}
+	return common.Success(http.StatusOK, "OK", value)
+}
+
+// CASE 14 Delete Any Asset
+func (t *BlockchainIOT) deleteAsset(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+	data := common.Key(args[0])
+
+	// Check if Exists
+	value, geterr := stub.GetState(data)
+	if geterr != nil || value == nil {
+		return common.Error(http.StatusNotFound, "Not Found")
+	}
+
+	// Open Production Orders hold Reservations on their Components
+	asset := map[string]interface{}{}
+	json.Unmarshal(value, &asset)
+	previousStatus, _ := asset["Status"].(string)
+	if assetType, _ := asset["Asset_Type"].(string); assetType == "PRODUCTION ORDER" && previousStatus == "OPEN" {
+		return common.Error(http.StatusConflict, "Open Production Orders hold Reservations! Please Cancel the Production Order first")
+	}
+
+	// Delete if Exists
+	if delerr := stub.DelState(data); delerr != nil {
+		return common.Error(http.StatusInternalServerError, delerr.Error())
+	}
+
+	// Emit Event
+	if eventerr := emitEvent(stub, ChaincodeEvent{EventType: eventAssetDeleted, PreviousStatus: previousStatus, Status: "DELETED"}); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	return common.Success(http.StatusNoContent, "Asset Deleted", nil)
+}
+
+// CASE 15 Cancel Purchase Order
+func (t *BlockchainIOT) cancelPurchaseOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	// Check if Purchase Order Exists and get the Purchase Order
+	purchaseOrderID := common.Key(args[0])
+	POValue, POGetErr := stub.GetState(purchaseOrderID)
+	if POGetErr != nil || POValue == nil {
+		return common.Error(http.StatusNotFound, "Purchase Order Does Not Exists! \n Please Specify Another POID")
+	}
+	purchaseOrder := PurchaseOrder{}
+	json.Unmarshal(POValue, &purchaseOrder)
+
+	// Only the Requestor or the Vendor cancels the Purchase Order
+	participantID, scopeErr := queryScope(stub)
+	if scopeErr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeErr.Error())
+	}
+	if participantID != "" && !containsFold([]string{purchaseOrder.RequestorID, purchaseOrder.VendorID}, participantID) {
+		return common.Error(http.StatusForbidden, "Invoke Error: Only the Requestor or the Vendor can Cancel this Purchase Order")
+	}
+
+	// Only Open Purchase Orders without a Shipment hold a Reservation
+	if purchaseOrder.Status != "OPEN" || purchaseOrder.ShipmentExists == true {
+		return common.Error(http.StatusBadRequest, "Only Open Purchase Orders without a Shipment can be Cancelled")
+	}
+
+	// Release the Reservation on the Vendor Batch, a deleted Material or Batch holds none
+	// and neither does a Purchase Order created before reservations
+	vendorMaterialID := common.Key(purchaseOrder.VendorID, purchaseOrder.VendorMaterialID)
+	vendorMaterialValue, vendorMaterialGetErr := stub.GetState(vendorMaterialID)
+	if vendorMaterialGetErr != nil {
+		return common.Error(http.StatusInternalServerError, vendorMaterialGetErr.Error())
+	}
+	vendorMaterial := Material{}
+	json.Unmarshal(vendorMaterialValue, &vendorMaterial)
+	index := -1
+	if vendorMaterialValue != nil && purchaseOrder.Reserved {
+		index = findBatch(&vendorMaterial, purchaseOrder.VendorBatchNumber)
+	}
+	if index >= 0 {
+		batch := vendorMaterial.Batches[index]
+		if batch.ReservedQuantity < purchaseOrder.Quantity {
+			return common.Error(http.StatusConflict, fmt.Sprintf("Batch %s holds a Reservation of %d, below the Quantity %d of the Purchase Order!", batch.BatchNumber, batch.ReservedQuantity, purchaseOrder.Quantity))
+		}
+		batch.ReservedQuantity -= purchaseOrder.Quantity
+		vendorMaterial.Batches[index] = batch
+		vendorMaterial.UpdateQuantities()
+	}
+
+	purchaseOrder.Status = "CANCELLED"
+
+	// Store in Blockchain
+	purchaseOrderJsonBytes, _ := json.Marshal(purchaseOrder)
+	if puterr := stub.PutState(purchaseOrderID, purchaseOrderJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	if index >= 0 {
+		vendorMaterialJsonBytes, _ := json.Marshal(vendorMaterial)
+		if puterr := stub.PutState(vendorMaterialID, vendorMaterialJsonBytes); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+	}
+
+	// Emit Event
+	if eventerr := emitEvent(stub, ChaincodeEvent{EventType: eventPurchaseOrderCancelled, PreviousStatus: "OPEN", Status: purchaseOrder.Status}); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	return common.Success(http.StatusOK, "Purchase Order Cancelled", nil)
+}
+
+// CASE 16 Cancel Production Order
+func (t *BlockchainIOT) cancelProductionOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	// Check if Production Order Exists and get the Production Order
+	productionOrderID := common.Key(args[0])
+	productionOrderValue, productionOrderGetErr := stub.GetState(productionOrderID)
+	if productionOrderGetErr != nil || productionOrderValue == nil {
+		return common.Error(http.StatusNotFound, "Production Order Does Not Exists! \n Please Specify Another POID")
+	}
+	productionOrder := ProductionOrder{}
+	json.Unmarshal(productionOrderValue, &productionOrder)
+	if productionOrder.Asset_Type != "PRODUCTION ORDER" {
+		return common.Error(http.StatusNotFound, "Production Order Does Not Exists! \n Please Specify Another POID")
+	}
+
+	// Only the Participant producing cancels the Production Order
+	participantID, scopeErr := queryScope(stub)
+	if scopeErr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeErr.Error())
+	}
+	if participantID != "" && !strings.EqualFold(participantID, productionOrder.ParticipantID) {
+		return common.Error(http.StatusForbidden, "Invoke Error: Only the Owner can Cancel this Production Order")
+	}
+
+	// Only Open Production Orders hold a Reservation
+	if productionOrder.Status != "OPEN" {
+		return common.Error(http.StatusBadRequest, "Only Open Production Orders can be Cancelled")
+	}
+
+	// Release the Reservations on the Component Batches
+	components, status, componentErr := releaseComponents(stub, productionOrder)
+	if componentErr != nil {
+		return common.Error(status, componentErr.Error())
+	}
+
+	productionOrder.Status = "CANCELLED"
+
+	// Store in Blockchain
+	jsonBytes, _ := json.Marshal(productionOrder)
+	if puterr := stub.PutState(productionOrderID, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	if puterr := components.store(stub); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Emit Event
+	if eventerr := emitEvent(stub, ChaincodeEvent{EventType: eventProductionOrderCancelled, PreviousStatus: "OPEN", Status: productionOrder.Status}); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	return common.Success(http.StatusOK, "Production Order Cancelled", nil)
+}
+
+//********************************************************************************************************
+// Micellanious Functions
+//********************************************************************************************************
+
+//Structs the versions of each Asset Type are decoded into
+var historyRecords = map[string]func() interface{}{
+	"PRODUCT":          func() interface{} { return &Product{} },
+	"MATERIAL":         func() interface{} { return &Material{} },
+	"PRODUCTION ORDER": func() interface{} { return &ProductionOrder{} },
+	"PURCHASE ORDER":   func() interface{} { return &PurchaseOrder{} },
+	"SHIPMENT":         func() interface{} { return &Shipment{} },
+	"PARTICIPANT":      func() interface{} { return &Participant{} },
+	"Goods Receipt":    func() interface{} { return &GoodsReceipt{} },
+	"LINEAGE EDGE":     func() interface{} { return &LineageEdge{} },
+	"ACCESS POLICY":    func() interface{} { return &AccessRule{} },
+	"GPS RETENTION":    func() interface{} { return &GPSRetention{} },
+	"DEVICE":           func() interface{} { return &Device{} },
+	"GEOFENCE":         func() interface{} { return &Geofence{} },
+}
+
+var historyDecoder = common.AssetTypeDecoder(historyRecords)
+
+// Get Transactions History From Blockchain - Arguments: Key, Options (optional)
+// Options: {"From": RFC3339 time, "To": RFC3339 time, "ChangesOnly": true to keep the changed fields only}
+// Participants only read the history of the records customQueries would return them
+func (t *BlockchainIOT) getHistory(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+	key := common.Key(args[0])
+	participantID, scopeerr := queryScope(stub)
+	if scopeerr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeerr.Error())
+	}
+	if readable, err := readableKey(stub, participantID, key); err != nil {
+		return common.Error(http.StatusInternalServerError, err.Error())
+	} else if !readable {
+		return common.Error(http.StatusNotFound, "Not Found")
+	}
+	options := common.HistoryOptions{}
+	if len(args) > 1 {
+		var opterr error
+		if options, opterr = common.ParseHistoryOptions(args[1]); opterr != nil {
+			return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Options - "+opterr.Error())
+		}
+	}
+	historyResult, err := common.GetHistoryExecution(stub, options, historyDecoder, key)
+	if err != nil {
+		return common.Error(http.StatusInternalServerError, err.Error())
+	}
+	return common.Success(http.StatusOK, "OK", historyResult)
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+var (
+	grower      = Tier{"GROWER01", "GROWER", "MAT01", "B1"}
+	importer    = Tier{"IMPORTER01", "IMPORTER", "MAT02", "IB1"}
+	distributor = Tier{"DISTRIBUTOR01", "DISTRIBUTOR", "MAT03", "DB1"}
+	retailer    = Tier{"RETAILER01", "RETAILER", "MAT04", "RB1"}
+)
+
+func TestInitRejectsArguments(t *testing.T) {
+	f := newFixture(t)
+	if res := f.stub.MockInit("init2", [][]byte{[]byte("init"), []byte("unexpected")}); res.Status != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d", http.StatusBadRequest, res.Status)
+	}
+}
+
+func TestInvokeUnknownFunction(t *testing.T) {
+	f := newFixture(t)
+	f.mustInvoke(http.StatusForbidden, "doesNotExist", nil)
+
+	// A rule for a function that does not exist reaches the dispatcher
+	f.asAdmin().mustInvoke(http.StatusCreated, "setAccessPolicy", map[string]interface{}{"Function": "doesNotExist", "Public": true})
+	f.mustInvoke(http.StatusNotImplemented, "doesNotExist", nil)
+}
+
+func TestCreateParticipantValidation(t *testing.T) {
+	f := newFixture(t)
+	f.participant("GROWER01", "GROWER")
+	f.mustInvoke(http.StatusConflict, "createParticipant", map[string]string{"ParticipantID": "grower01", "ParticipantType": "GROWER"})
+	f.mustInvoke(http.StatusBadRequest, "createParticipant", map[string]string{"ParticipantID": "FARMER01", "ParticipantType": "FARMER"})
+	f.mustInvoke(http.StatusBadRequest, "createParticipant", "not json")
+}
+
+func TestRegisterMaterialRequiresProductAndParticipant(t *testing.T) {
+	f := newFixture(t)
+	f.participant("GROWER01", "GROWER")
+	f.mustInvoke(http.StatusNotFound, "registerMaterial", map[string]string{"ParticipantID": "GROWER01", "MaterialMasterID": "MAT01", "ProductBCID": "PRODUCT01"})
+	f.product("PRODUCT01")
+	f.as("GROWER01").mustInvoke(http.StatusNotFound, "registerMaterial", map[string]string{"ParticipantID": "IMPORTER01", "MaterialMasterID": "MAT01", "ProductBCID": "PRODUCT01"})
+	f.material("GROWER01", "MAT01", "PRODUCT01")
+	f.mustInvoke(http.StatusConflict, "registerMaterial", map[string]string{"ParticipantID": "GROWER01", "MaterialMasterID": "MAT01", "ProductBCID": "PRODUCT01"})
+
+	product := f.getProduct("PRODUCT01")
+	if len(product.AllMaterials) != 1 || product.AllMaterials[0] != "GROWER01-MAT01" {
+		t.Fatalf("unexpected product materials %v", product.AllMaterials)
+	}
+}
+
+//TestLifecycle runs every Invoke function of BlockchainIOT in the order a real supply chain would
+func TestLifecycle(t *testing.T) {
+	f := newFixture(t)
+
+	// Participants, Product and Materials
+	f.participant(grower.ParticipantID, grower.ParticipantType)
+	f.participant(importer.ParticipantID, importer.ParticipantType)
+	f.product("PRODUCT01")
+	f.material(grower.ParticipantID, grower.MaterialID, "PRODUCT01")
+	f.material(importer.ParticipantID, importer.MaterialID, "PRODUCT01")
+
+	// Production Order and its Goods Receipt
+	f.produce("PRO1", grower.ParticipantID, grower.MaterialID, grower.BatchNumber, 100)
+	f.mustInvoke(http.StatusBadRequest, "submitGoodsReceipt", map[string]string{"GRNumber": "GRX", "ReceivedBy": "GROWER01", "Against": "PRODUCTION ORDER", "POID": "PRO1", "BatchNumber": "B1"})
+
+	product := f.getProduct("PRODUCT01")
+	if product.TotalQuantity != 100 {
+		t.Fatalf("expected product quantity 100, got %d", product.TotalQuantity)
+	}
+	if len(product.SupplyChainMembers) != 1 || product.SupplyChainMembers[0].ParticipantType != "GROWER" {
+		t.Fatalf("unexpected supply chain members %+v", product.SupplyChainMembers)
+	}
+	material := f.getMaterial(grower.ParticipantID, grower.MaterialID)
+	if material.TotalQuantity != 100 || len(material.Batches) != 1 || material.Batches[0].Quantity != 100 {
+		t.Fatalf("unexpected grower material %+v", material)
+	}
+
+	// Purchase Order
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{
+		"POID": "PO1", "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID,
+		"VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": grower.BatchNumber,
+		"Quantity": 40, "UnitOfMeasure": "KG", "NetPrice": 10, "Currency": "USD",
+	})
+	purchaseOrder := f.getPurchaseOrder("PO1")
+	if purchaseOrder.Status != "OPEN" || purchaseOrder.Quantity != 40 || purchaseOrder.ShipmentExists {
+		t.Fatalf("unexpected purchase order %+v", purchaseOrder)
+	}
+
+	// Shipment
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createShipment", map[string]interface{}{"ShipmentID": "SH1", "ProductBCID": "PRODUCT01", "POID": "PO1", "UnsignedReadings": true})
+	f.mustInvoke(http.StatusBadRequest, "createShipment", map[string]string{"ShipmentID": "SH2", "ProductBCID": "PRODUCT01", "POID": "PO1"})
+	purchaseOrder = f.getPurchaseOrder("PO1")
+	if !purchaseOrder.ShipmentExists || purchaseOrder.ShipmentID != "SH1" {
+		t.Fatalf("unexpected purchase order %+v", purchaseOrder)
+	}
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, grower.BatchNumber); batch.Quantity != 60 {
+		t.Fatalf("expected vendor batch quantity 60, got %d", batch.Quantity)
+	}
+	if material = f.getMaterial(grower.ParticipantID, grower.MaterialID); material.TotalQuantity != 60 {
+		t.Fatalf("expected vendor material quantity 60, got %d", material.TotalQuantity)
+	}
+
+	// Track Shipment
+	f.mustInvoke(http.StatusCreated, "trackShipment", map[string]interface{}{"ShipmentID": "SH1", "Latitude": 1.5, "Longitude": 2.5, "Accuracy": 3})
+	f.mustInvoke(http.StatusCreated, "trackShipment", map[string]interface{}{"ShipmentID": "SH1", "Latitude": 1.6, "Longitude": 2.6, "Accuracy": 3})
+	if shipment := f.getShipment("SH1"); shipment.Status != "SHIPPING" || len(shipment.GPSReading) != 2 {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+
+	// Goods Receipt against the Purchase Order
+	f.as(importer.ParticipantID).mustInvoke(http.StatusBadRequest, "submitGoodsReceipt", map[string]string{"GRNumber": "GR2", "ReceivedBy": grower.ParticipantID, "Against": "PURCHASE ORDER", "POID": "PO1", "BatchNumber": importer.BatchNumber})
+	f.mustInvoke(http.StatusCreated, "submitGoodsReceipt", map[string]string{"GRNumber": "GR2", "ReceivedBy": importer.ParticipantID, "Against": "PURCHASE ORDER", "POID": "PO1", "BatchNumber": importer.BatchNumber})
+	f.mustInvoke(http.StatusBadRequest, "trackShipment", map[string]interface{}{"ShipmentID": "SH1", "Latitude": 1.7, "Longitude": 2.7})
+
+	if purchaseOrder = f.getPurchaseOrder("PO1"); purchaseOrder.Status != "COMPLETED" {
+		t.Fatalf("expected completed purchase order, got %s", purchaseOrder.Status)
+	}
+	if material = f.getMaterial(importer.ParticipantID, importer.MaterialID); material.TotalQuantity != 40 || material.Batches[0].BatchNumber != importer.BatchNumber {
+		t.Fatalf("unexpected importer material %+v", material)
+	}
+	product = f.getProduct("PRODUCT01")
+	if len(product.Mappings) != 1 || product.Mappings[0].From.BatchNumber != grower.BatchNumber || product.Mappings[0].To[0].BatchNumber != importer.BatchNumber || product.Mappings[0].To[0].Quantity != 40 {
+		t.Fatalf("unexpected mappings %+v", product.Mappings)
+	}
+	if len(product.ReverseMappings) != 1 || product.ReverseMappings[0].To.BatchNumber != importer.BatchNumber || product.ReverseMappings[0].From[0].BatchNumber != grower.BatchNumber {
+		t.Fatalf("unexpected reverse mappings %+v", product.ReverseMappings)
+	}
+	if len(product.SupplyChainMembers) != 2 {
+		t.Fatalf("expected 2 supply chain members, got %+v", product.SupplyChainMembers)
+	}
+
+	// Report Contamination at the Grower
+	f.mustInvoke(http.StatusCreated, "reportContamination", map[string]string{"ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "BatchNumber": grower.BatchNumber})
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, grower.BatchNumber); !batch.IsCompromised {
+		t.Fatalf("expected grower batch compromised %+v", batch)
+	}
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, importer.BatchNumber); !batch.IsCompromised || batch.PotentialCompromised {
+		t.Fatalf("expected importer batch compromised %+v", batch)
+	}
+	product = f.getProduct("PRODUCT01")
+	if !product.Mappings[0].From.IsCompromised || !product.Mappings[0].To[0].IsCompromised || !product.ReverseMappings[0].To.IsCompromised {
+		t.Fatalf("expected compromised mappings %+v %+v", product.Mappings, product.ReverseMappings)
+	}
+	for _, element := range product.SupplyChainMembers {
+		if !element.IsCompromised {
+			t.Fatalf("expected compromised member %+v", element)
+		}
+	}
+
+	// Clear Contamination
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "clearContamination", map[string]string{"ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "BatchNumber": grower.BatchNumber})
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, grower.BatchNumber); batch.IsCompromised || batch.PotentialCompromised {
+		t.Fatalf("expected grower batch cleared %+v", batch)
+	}
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, importer.BatchNumber); batch.IsCompromised || batch.PotentialCompromised {
+		t.Fatalf("expected importer batch cleared %+v", batch)
+	}
+	product = f.getProduct("PRODUCT01")
+	if product.Mappings[0].From.IsCompromised || product.Mappings[0].To[0].IsCompromised {
+		t.Fatalf("expected cleared mappings %+v", product.Mappings)
+	}
+}
+
+func TestContaminationFollowsMultiTierChain(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer, distributor, retailer)
+
+	product := f.getProduct("PRODUCT01")
+	if len(product.SupplyChainMembers) != 4 || len(product.Mappings) != 3 {
+		t.Fatalf("unexpected product %+v", product)
+	}
+	if batch := f.batch(retailer.ParticipantID, retailer.MaterialID, retailer.BatchNumber); batch.Quantity != 100 {
+		t.Fatalf("expected retailer batch quantity 100, got %d", batch.Quantity)
+	}
+
+	// Contamination at the Grower reaches every downstream batch
+	f.mustInvoke(http.StatusCreated, "reportContamination", map[string]string{"ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "BatchNumber": grower.BatchNumber})
+	for _, tier := range []Tier{importer, distributor, retailer} {
+		if batch := f.batch(tier.ParticipantID, tier.MaterialID, tier.BatchNumber); !batch.IsCompromised {
+			t.Fatalf("expected %s batch compromised", tier.ParticipantID)
+		}
+	}
+
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "clearContamination", map[string]string{"ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "BatchNumber": grower.BatchNumber})
+	for _, tier := range []Tier{grower, importer, distributor, retailer} {
+		if batch := f.batch(tier.ParticipantID, tier.MaterialID, tier.BatchNumber); batch.IsCompromised || batch.PotentialCompromised {
+			t.Fatalf("expected %s batch cleared", tier.ParticipantID)
+		}
+	}
+}
+
+func TestContaminationMarksUpstreamAsPotential(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer, distributor)
+
+	// Contamination at the Importer flags the Grower as a potential source
+	f.mustInvoke(http.StatusCreated, "reportContamination", map[string]string{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumber": importer.BatchNumber})
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, importer.BatchNumber); !batch.IsCompromised {
+		t.Fatalf("expected importer batch compromised %+v", batch)
+	}
+	if batch := f.batch(distributor.ParticipantID, distributor.MaterialID, distributor.BatchNumber); !batch.IsCompromised {
+		t.Fatalf("expected distributor batch compromised %+v", batch)
+	}
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, grower.BatchNumber); batch.IsCompromised || !batch.PotentialCompromised {
+		t.Fatalf("expected grower batch potentially compromised %+v", batch)
+	}
+}
+
+func TestDeleteAsset(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+	f.mustInvoke(http.StatusNoContent, "deleteAsset", "PRODUCT01")
+	f.mustInvoke(http.StatusNotFound, "getAsset", "PRODUCT01")
+	f.mustInvoke(http.StatusNotFound, "deleteAsset", "PRODUCT01")
+}
+
+func TestGetAndDeleteMaterial(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+	f.participant("GROWER01", "GROWER")
+	f.material("GROWER01", "MAT01", "PRODUCT01")
+	f.mustInvoke(http.StatusOK, "getMaterial", "GROWER01", "MAT01")
+	f.asAdmin().mustInvoke(http.StatusNoContent, "deleteMaterial", "GROWER01", "MAT01")
+	f.mustInvoke(http.StatusNotFound, "getMaterial", "GROWER01", "MAT01")
+}
+
+//MockStub has no history database or query engine, so both functions must surface the error
+func TestHistoryAndQueriesReportStubErrors(t *testing.T) {
+	f := newFixture(t)
+	f.mustInvoke(http.StatusInternalServerError, "getHistory", "PRODUCT01")
+	f.mustInvoke(http.StatusBadRequest, "getHistory", "PRODUCT01", `{"From":"2020-01-02"}`)
+	f.mustInvoke(http.StatusBadRequest, "getHistory", nil)
+	f.asAdmin().mustInvoke(http.StatusInternalServerError, "customQueries", `{"AssetType":"PRODUCT"}`)
+	f.mustInvoke(http.StatusBadRequest, "customQueries", `{"AssetType":"PRODUCT"}`, "zero")
+}
+
+func TestPurchaseOrderReservations(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+	f.produce("PRO-B2", grower.ParticipantID, grower.MaterialID, "B2", 50)
+
+	order := func(orderID string, quantity int) map[string]interface{} {
+		return map[string]interface{}{
+			"POID": orderID, "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID,
+			"VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": "B2",
+			"Quantity": quantity, "UnitOfMeasure": "KG",
+		}
+	}
+
+	// Two orders cannot claim the same stock
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", order("PO-A", 30))
+	f.mustInvoke(http.StatusBadRequest, "createPurchaseOrder", order("PO-B", 30))
+	f.mustInvoke(http.StatusCreated, "createPurchaseOrder", order("PO-B", 20))
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, "B2"); batch.Quantity != 50 || batch.ReservedQuantity != 50 || batch.AvailableQuantity != 0 {
+		t.Fatalf("unexpected reserved batch %+v", batch)
+	}
+
+	// Shipping converts the reservation, cancelling releases it
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createShipment", map[string]interface{}{"ShipmentID": "SH-A", "ProductBCID": "PRODUCT01", "POID": "PO-A", "UnsignedReadings": true})
+	f.as(importer.ParticipantID).mustInvoke(http.StatusOK, "cancelPurchaseOrder", "PO-B")
+	f.mustInvoke(http.StatusBadRequest, "cancelPurchaseOrder", "PO-B")
+	f.mustInvoke(http.StatusBadRequest, "cancelPurchaseOrder", "PO-A")
+	f.as(grower.ParticipantID).mustInvoke(http.StatusBadRequest, "createShipment", map[string]string{"ShipmentID": "SH-B", "ProductBCID": "PRODUCT01", "POID": "PO-B"})
+
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, "B2"); batch.Quantity != 20 || batch.ReservedQuantity != 0 || batch.ShippedQuantity != 30 || batch.AvailableQuantity != 20 {
+		t.Fatalf("unexpected batch after shipment %+v", batch)
+	}
+	material := f.getMaterial(grower.ParticipantID, grower.MaterialID)
+	if material.TotalQuantity != 20 || material.AvailableQuantity != 20 || material.ReservedQuantity != 0 || material.ShippedQuantity != 130 {
+		t.Fatalf("unexpected material quantities %+v", material)
+	}
+	if purchaseOrder := f.getPurchaseOrder("PO-B"); purchaseOrder.Status != "CANCELLED" {
+		t.Fatalf("expected cancelled purchase order, got %s", purchaseOrder.Status)
+	}
+}
+
+func TestPurchaseOrdersWithoutReservationShipFromFreeQuantity(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+	f.produce("PRO-B2", grower.ParticipantID, grower.MaterialID, "B2", 50)
+	order := func(orderID string, quantity int) map[string]interface{} {
+		return map[string]interface{}{"POID": orderID, "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID, "VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": "B2", "Quantity": quantity, "UnitOfMeasure": "KG"}
+	}
+	shipment := func(shipmentID string, orderID string) map[string]interface{} {
+		return map[string]interface{}{"ShipmentID": shipmentID, "ProductBCID": "PRODUCT01", "POID": orderID, "UnsignedReadings": true}
+	}
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", order("PO-OLD1", 20))
+	f.mustInvoke(http.StatusCreated, "createPurchaseOrder", order("PO-OLD2", 20))
+	if purchaseOrder := f.getPurchaseOrder("PO-OLD1"); !purchaseOrder.Reserved {
+		t.Fatalf("expected a reserved purchase order %+v", purchaseOrder)
+	}
+
+	// Orders created before reservations left the batch unreserved
+	legacy := map[string]interface{}{}
+	for _, orderID := range []string{"PO-OLD1", "PO-OLD2"} {
+		purchaseOrder := f.getPurchaseOrder(orderID)
+		purchaseOrder.Reserved = false
+		legacy[common.Key(orderID)] = purchaseOrder
+	}
+	material := f.getMaterial(grower.ParticipantID, grower.MaterialID)
+	material.Batches[findBatch(&material, "B2")].ReservedQuantity = 0
+	material.UpdateQuantities()
+	legacy[common.Key(grower.ParticipantID, grower.MaterialID)] = material
+	f.stub.MockTransactionStart("legacy")
+	for key, value := range legacy {
+		jsonBytes, _ := json.Marshal(value)
+		f.stub.PutState(key, jsonBytes)
+	}
+	f.stub.MockTransactionEnd("legacy")
+
+	// A new order reserves 20 of the 50, the legacy orders share the 30 left
+	f.mustInvoke(http.StatusCreated, "createPurchaseOrder", order("PO-NEW", 20))
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createShipment", shipment("SH-OLD1", "PO-OLD1"))
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, "B2"); batch.Quantity != 30 || batch.ReservedQuantity != 20 || batch.ShippedQuantity != 20 {
+		t.Fatalf("unexpected batch after the legacy shipment %+v", batch)
+	}
+	f.mustInvoke(http.StatusBadRequest, "createShipment", shipment("SH-OLD2", "PO-OLD2"))
+	f.mustInvoke(http.StatusCreated, "createShipment", shipment("SH-NEW", "PO-NEW"))
+
+	// Cancelling a legacy order releases nothing
+	f.as(importer.ParticipantID).mustInvoke(http.StatusOK, "cancelPurchaseOrder", "PO-OLD2")
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, "B2"); batch.Quantity != 10 || batch.ReservedQuantity != 0 || batch.AvailableQuantity != 10 {
+		t.Fatalf("unexpected batch after the cancellation %+v", batch)
+	}
+}
+
+func TestCreateShipmentRequiresTheVendorMaterial(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+	f.produce("PRO-B2", grower.ParticipantID, grower.MaterialID, "B2", 50)
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{"POID": "PO-A", "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID, "VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": "B2", "Quantity": 10, "UnitOfMeasure": "KG"})
+
+	// A deleted Material is neither shipped from nor written back
+	f.asAdmin().mustInvoke(http.StatusNoContent, "deleteAsset", grower.ParticipantID+"-"+grower.MaterialID)
+	f.as(grower.ParticipantID).mustInvoke(http.StatusNotFound, "createShipment", map[string]string{"ShipmentID": "SH-A", "ProductBCID": "PRODUCT01", "POID": "PO-A"})
+	if value, _ := f.stub.GetState(common.Key(grower.ParticipantID, grower.MaterialID)); value != nil {
+		t.Fatalf("expected no material, got %s", value)
+	}
+	if purchaseOrder := f.getPurchaseOrder("PO-A"); purchaseOrder.ShipmentExists {
+		t.Fatalf("unexpected purchase order %+v", purchaseOrder)
+	}
+}
+
+func TestCancelPurchaseOrderChecksPartiesAndReservations(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+	f.participant(distributor.ParticipantID, distributor.ParticipantType)
+	f.produce("PRO-B2", grower.ParticipantID, grower.MaterialID, "B2", 50)
+	order := func(orderID string) map[string]interface{} {
+		return map[string]interface{}{"POID": orderID, "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID, "VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": "B2", "Quantity": 10, "UnitOfMeasure": "KG"}
+	}
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", order("PO-A"))
+	f.mustInvoke(http.StatusCreated, "createPurchaseOrder", order("PO-B"))
+
+	f.as(distributor.ParticipantID).mustInvoke(http.StatusForbidden, "cancelPurchaseOrder", "PO-A")
+
+	// A Reservation below the order is reported instead of being clamped
+	material := f.getMaterial(grower.ParticipantID, grower.MaterialID)
+	material.Batches[findBatch(&material, "B2")].ReservedQuantity = 5
+	jsonBytes, _ := json.Marshal(material)
+	f.stub.MockTransactionStart("seed")
+	f.stub.PutState(common.Key(grower.ParticipantID, grower.MaterialID), jsonBytes)
+	f.stub.MockTransactionEnd("seed")
+	f.as(importer.ParticipantID).mustInvoke(http.StatusConflict, "cancelPurchaseOrder", "PO-A")
+
+	// A deleted Material is not written back
+	f.asAdmin().mustInvoke(http.StatusNoContent, "deleteAsset", grower.ParticipantID+"-"+grower.MaterialID)
+	f.as(importer.ParticipantID).mustInvoke(http.StatusOK, "cancelPurchaseOrder", "PO-B")
+	if value, _ := f.stub.GetState(common.Key(grower.ParticipantID, grower.MaterialID)); value != nil {
+		t.Fatalf("expected no material, got %s", value)
+	}
+	if event := f.lastEvent(eventPurchaseOrderCancelled); len(event.Keys) != 1 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+}
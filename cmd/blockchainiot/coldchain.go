@@ -0,0 +1,193 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Cold Chain
+//****Description: Sensor readings of shipments checked against the temperature limits of the Product
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//markShippedBatches flags the vendor batches of Purchase Orders PotentialCompromised, a Compromised batch is left as is.
+//Each Material is stored once, a transaction does not read its own writes.
+func markShippedBatches(stub shim.ChaincodeStubInterface, purchaseOrders []PurchaseOrder) error {
+	materialIDs := []string{}
+	batches := map[string][]string{}
+	for _, element := range purchaseOrders {
+		materialID := common.Key(element.VendorID, element.VendorMaterialID)
+		if _, exists := batches[materialID]; !exists {
+			materialIDs = append(materialIDs, materialID)
+		}
+		batches[materialID] = append(batches[materialID], element.VendorBatchNumber)
+	}
+
+	for _, materialID := range materialIDs {
+		batchNumbers := batches[materialID]
+		materialValue, geterr := stub.GetState(materialID)
+		if geterr != nil {
+			return geterr
+		}
+		if materialValue == nil {
+			continue
+		}
+		material := Material{}
+		json.Unmarshal(materialValue, &material)
+		for index, element := range material.Batches {
+			if containsFold(batchNumbers, element.BatchNumber) {
+				setFlags(markPotential, &element.IsCompromised, &element.PotentialCompromised)
+				material.Batches[index] = element
+			}
+		}
+		jsonBytes, _ := json.Marshal(material)
+		if puterr := stub.PutState(materialID, jsonBytes); puterr != nil {
+			return puterr
+		}
+	}
+	return nil
+}
+
+//********************************************************************************************************
+// Cold Chain Functions
+//********************************************************************************************************
+
+// Set Temperature Limits of a Product - Arguments: {"ProductID", "MinTemperature", "MaxTemperature"}
+// A limit left out is not checked
+func (t *BlockchainIOT) setTemperatureLimits(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ProductID      string   `json:"ProductID"`
+		MinTemperature *float64 `json:"MinTemperature,omitempty"`
+		MaxTemperature *float64 `json:"MaxTemperature,omitempty"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+	limits := common.TemperatureLimits{MinTemperature: queryData.MinTemperature, MaxTemperature: queryData.MaxTemperature}
+	if limitErr := limits.Validate(); limitErr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: "+limitErr.Error())
+	}
+
+	// Get Product
+	productID := common.Key(queryData.ProductID)
+	productValue, productGetErr := stub.GetState(productID)
+	if productGetErr != nil || productValue == nil {
+		return common.Error(http.StatusNotFound, "Product Does Not Exist! Please Check Product ID!")
+	}
+	product := Product{}
+	json.Unmarshal(productValue, &product)
+	product.TemperatureLimits = limits
+
+	// Store in Blockchain
+	jsonBytes, _ := json.Marshal(product)
+	if puterr := stub.PutState(productID, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	return common.Success(http.StatusOK, "Temperature Limits Updated", nil)
+}
+
+// Record a Sensor Reading of a Shipment - Arguments: {"ShipmentID", "TemperatureCelsius", "Humidity", "Shock", "Timestamp"}
+// A temperature outside the limits of the Product records an excursion and flags the shipped batch PotentialCompromised
+func (t *BlockchainIOT) recordSensorReading(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ShipmentID         string   `json:"ShipmentID"`
+		TemperatureCelsius *float64 `json:"TemperatureCelsius"`
+		Humidity           float64  `json:"Humidity,omitempty"`
+		Shock              float64  `json:"Shock,omitempty"`
+		Timestamp          string   `json:"Timestamp,omitempty"`
+		common.DeviceSignature
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil || queryData.TemperatureCelsius == nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+	reading, readingErr := common.SensorReading{TemperatureCelsius: *queryData.TemperatureCelsius, Humidity: queryData.Humidity, Shock: queryData.Shock, Timestamp: queryData.Timestamp}.Normalize(stub)
+	if readingErr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: "+readingErr.Error())
+	}
+
+	// Check if Shipment Exists and Get Shipment
+	shipmentID := common.Key(queryData.ShipmentID)
+	shipmentValue, shipmentGetErr := stub.GetState(shipmentID)
+	if shipmentGetErr != nil || shipmentValue == nil {
+		return common.Error(http.StatusNotFound, "Shipment Does Not Exists! \n Please Specify Another Shipment ID")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentValue, &shipment)
+
+	// Check if Shipment is Completed
+	if shipment.Status == "COMPLETED" {
+		return common.Error(http.StatusBadRequest, "Shipment is already Completed")
+	}
+
+	// Check the Signature of the Device over the reading as sent
+	devices := newDeviceReadings()
+	sent := common.SensorReading{TemperatureCelsius: *queryData.TemperatureCelsius, Humidity: queryData.Humidity, Shock: queryData.Shock, Timestamp: queryData.Timestamp}
+	if verifyErr := devices.verify(stub, shipment, queryData.DeviceSignature, sent.SignedFields(queryData.ShipmentID)...); verifyErr != nil {
+		return common.Error(http.StatusUnauthorized, "Invoke Error: "+verifyErr.Error())
+	}
+	if puterr := devices.store(stub); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Get Product
+	productValue, _ := stub.GetState(common.Key(shipment.ProductBCID))
+	product := Product{}
+	json.Unmarshal(productValue, &product)
+
+	// Enter Reading for Shipment
+	shipment.SensorReadings = append(shipment.SensorReadings, reading)
+	event := ChaincodeEvent{EventType: eventSensorReadingRecorded, PreviousStatus: shipment.Status, Status: shipment.Status}
+
+	// Record the Excursion and flag the shipped Batch
+	excursion := product.TemperatureLimits.Check(reading)
+	if excursion != nil {
+		shipment.Excursions = append(shipment.Excursions, *excursion)
+
+		purchaseOrderValue, _ := stub.GetState(common.Key(shipment.POID))
+		purchaseOrder := PurchaseOrder{}
+		json.Unmarshal(purchaseOrderValue, &purchaseOrder)
+		if markerr := markShippedBatches(stub, []PurchaseOrder{purchaseOrder}); markerr != nil {
+			return common.Error(http.StatusInternalServerError, markerr.Error())
+		}
+		event.EventType = eventTemperatureExcursion
+		event.Participants = []string{purchaseOrder.VendorID, purchaseOrder.RequestorID}
+	}
+
+	// Store Updated Shipment in Blockchain
+	jsonBytes, _ := json.Marshal(shipment)
+	if puterr := stub.PutState(shipmentID, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Emit Event
+	if eventerr := emitEvent(stub, event); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	if excursion != nil {
+		return common.Success(http.StatusCreated, "Temperature Excursion Recorded", nil)
+	}
+	return common.Success(http.StatusCreated, "Sensor Reading Recorded", nil)
+}
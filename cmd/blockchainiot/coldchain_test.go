@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTemperatureExcursionFlagsTheShippedBatch(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+	for _, element := range []Tier{grower, importer} {
+		f.participant(element.ParticipantID, element.ParticipantType)
+		f.material(element.ParticipantID, element.MaterialID, "PRODUCT01")
+	}
+	f.produce("PRO1", grower.ParticipantID, grower.MaterialID, grower.BatchNumber, 40)
+
+	f.as(grower.ParticipantID).mustInvoke(http.StatusBadRequest, "setTemperatureLimits", map[string]interface{}{"ProductID": "PRODUCT01", "MinTemperature": 8, "MaxTemperature": 2})
+	f.mustInvoke(http.StatusNotFound, "setTemperatureLimits", map[string]interface{}{"ProductID": "UNKNOWN", "MaxTemperature": 8})
+	f.mustInvoke(http.StatusOK, "setTemperatureLimits", map[string]interface{}{"ProductID": "PRODUCT01", "MinTemperature": 2, "MaxTemperature": 8})
+	if limits := f.getProduct("PRODUCT01").TemperatureLimits; *limits.MinTemperature != 2 || *limits.MaxTemperature != 8 {
+		t.Fatalf("unexpected limits %+v", limits)
+	}
+	f.asAdmin().mustInvoke(http.StatusBadRequest, "createProduct", map[string]interface{}{"ProductID": "PRODUCT02", "ProductType": "PERISHABLE", "MinTemperature": 5, "MaxTemperature": -5})
+
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{"POID": "PO1", "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID, "VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": grower.BatchNumber, "Quantity": 10, "UnitOfMeasure": "KG", "NetPrice": 10, "Currency": "USD"})
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createShipment", map[string]interface{}{"ShipmentID": "SH1", "ProductBCID": "PRODUCT01", "POID": "PO1", "UnsignedReadings": true})
+
+	f.mustInvoke(http.StatusBadRequest, "recordSensorReading", map[string]interface{}{"ShipmentID": "SH1"})
+	f.mustInvoke(http.StatusBadRequest, "recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": 4, "Humidity": 120})
+	f.mustInvoke(http.StatusNotFound, "recordSensorReading", map[string]interface{}{"ShipmentID": "UNKNOWN", "TemperatureCelsius": 4})
+	f.mustInvoke(http.StatusCreated, "recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": 4, "Humidity": 60, "Shock": 0.5, "Timestamp": "2020-01-01T10:00:00+01:00"})
+	f.lastEvent(eventSensorReadingRecorded)
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, grower.BatchNumber); batch.PotentialCompromised {
+		t.Fatalf("a reading within the limits must not flag the batch %+v", batch)
+	}
+
+	f.mustInvoke(http.StatusCreated, "recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": 11.5})
+	if event := f.lastEvent(eventTemperatureExcursion); flatKeys(event) != "grower01-mat01 sh1" || len(event.Keys) != 2 || len(event.Participants) != 2 {
+		t.Fatalf("unexpected excursion event %+v", event)
+	}
+	shipment := f.getShipment("SH1")
+	if len(shipment.SensorReadings) != 2 || shipment.SensorReadings[0].Timestamp != "2020-01-01T09:00:00Z" || len(shipment.Excursions) != 1 || shipment.Excursions[0].TemperatureCelsius != 11.5 {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, grower.BatchNumber); !batch.PotentialCompromised {
+		t.Fatalf("expected the shipped batch flagged %+v", batch)
+	}
+
+	// The received goods carry the flag
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "submitGoodsReceipt", map[string]interface{}{"GRNumber": "GR1", "ReceivedBy": importer.ParticipantID, "Against": "PURCHASE ORDER", "POID": "PO1", "BatchNumber": importer.BatchNumber})
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, importer.BatchNumber); !batch.PotentialCompromised {
+		t.Fatalf("expected the received batch flagged %+v", batch)
+	}
+	f.mustInvoke(http.StatusBadRequest, "recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": 4})
+}
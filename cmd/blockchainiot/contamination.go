@@ -0,0 +1,256 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Contamination Engine
+//****Description: Breadth-first walk of the batch lineage graph used to report and clear contamination
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//Maximum number of hops walked from the reported batch when the payload sets no MaxDepth
+const defaultContaminationDepth = 64
+
+//********************************************************************************************************
+//Struct for the Contamination Engine
+//********************************************************************************************************
+
+//Batch reached by the lineage walk, Hops is its distance from the reported batch.
+//Quantity is the quantity moved along the edge that reached the batch.
+type AffectedBatch struct {
+	ParticipantID string `json:"ParticipantID"`
+	MaterialID    string `json:"MaterialID"`
+	BatchNumber   string `json:"BatchNumber"`
+	Quantity      int    `json:"Quantity"`
+	Hops          int    `json:"Hops"`
+}
+
+//Consistent set of batches affected by a contaminated batch.
+//Compromised batches are downstream of it, PotentialCompromised batches are upstream of it.
+type ContaminationImpact struct {
+	Origin               AffectedBatch   `json:"Origin"`
+	Compromised          []AffectedBatch `json:"Compromised"`
+	PotentialCompromised []AffectedBatch `json:"PotentialCompromised"`
+}
+
+//Flags written by applyContamination
+type contaminationMode int
+
+const (
+	markCompromised contaminationMode = iota
+	markPotential
+	markClear
+)
+
+//Key of a batch in the lineage graph
+func batchKey(participant string, material string, batch string) string {
+	return common.Key(participant, material, batch)
+}
+
+//walkLineage visits every batch reachable from start in breadth-first order, each batch once,
+//stopping maxDepth hops away. Edges are read with partial composite key scans.
+//The start batch is not part of the result.
+func walkLineage(stub shim.ChaincodeStubInterface, start AffectedBatch, forward bool, maxDepth int) ([]AffectedBatch, error) {
+	result := []AffectedBatch{}
+	visited := map[string]bool{batchKey(start.ParticipantID, start.MaterialID, start.BatchNumber): true}
+	queue := []AffectedBatch{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current.Hops >= maxDepth {
+			continue
+		}
+		batches, err := neighbours(stub, current.ParticipantID, current.MaterialID, current.BatchNumber, forward)
+		if err != nil {
+			return nil, err
+		}
+		for _, element := range batches {
+			key := batchKey(element.ParticipantID, element.MaterialID, element.BatchNumber)
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+			next := AffectedBatch{element.ParticipantID, element.MaterialID, element.BatchNumber, element.Quantity, current.Hops + 1}
+			result = append(result, next)
+			queue = append(queue, next)
+		}
+	}
+	return result, nil
+}
+
+//assessContamination computes the batches affected by a contaminated batch without touching the ledger
+func assessContamination(stub shim.ChaincodeStubInterface, contaminatedBatch BatchContamination) (ContaminationImpact, error) {
+	maxDepth := contaminatedBatch.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultContaminationDepth
+	}
+
+	impact := ContaminationImpact{}
+	impact.Origin = AffectedBatch{ParticipantID: contaminatedBatch.ParticipantID, MaterialID: contaminatedBatch.MaterialID, BatchNumber: contaminatedBatch.BatchNumber}
+	downstream, err := walkLineage(stub, impact.Origin, true, maxDepth)
+	if err != nil {
+		return impact, err
+	}
+	upstream, err := walkLineage(stub, impact.Origin, false, maxDepth)
+	if err != nil {
+		return impact, err
+	}
+	impact.Compromised = downstream
+
+	// A batch that is both upstream and downstream (cyclic lineage) stays Compromised
+	compromised := map[string]bool{}
+	for _, element := range impact.Compromised {
+		compromised[batchKey(element.ParticipantID, element.MaterialID, element.BatchNumber)] = true
+	}
+	impact.PotentialCompromised = []AffectedBatch{}
+	for _, element := range upstream {
+		if !compromised[batchKey(element.ParticipantID, element.MaterialID, element.BatchNumber)] {
+			impact.PotentialCompromised = append(impact.PotentialCompromised, element)
+		}
+	}
+	return impact, nil
+}
+
+//setFlags updates a batch status. A batch already Compromised is not downgraded to Potential.
+func setFlags(mode contaminationMode, isCompromised *bool, potentialCompromised *bool) {
+	switch mode {
+	case markCompromised:
+		*isCompromised = true
+		*potentialCompromised = false
+	case markPotential:
+		if !*isCompromised {
+			*potentialCompromised = true
+		}
+	case markClear:
+		*isCompromised = false
+		*potentialCompromised = false
+	}
+}
+
+//applyContamination writes the impact to the Material batches, each Material is stored once.
+//Product mappings and supply chain members are derived from the Materials by productView.
+func applyContamination(stub shim.ChaincodeStubInterface, impact ContaminationImpact, clear bool) error {
+	modes := map[string]contaminationMode{}
+	materials := []string{}
+	mark := func(batches []AffectedBatch, mode contaminationMode) {
+		if clear {
+			mode = markClear
+		}
+		for _, element := range batches {
+			modes[batchKey(element.ParticipantID, element.MaterialID, element.BatchNumber)] = mode
+			materialID := common.Key(element.ParticipantID, element.MaterialID)
+			if !containsFold(materials, materialID) {
+				materials = append(materials, materialID)
+			}
+		}
+	}
+	mark(impact.Compromised, markCompromised)
+	mark(impact.PotentialCompromised, markPotential)
+	mark([]AffectedBatch{impact.Origin}, markCompromised)
+
+	for _, materialID := range materials {
+		materialValue, geterr := stub.GetState(materialID)
+		if geterr != nil {
+			return geterr
+		}
+		if materialValue == nil {
+			continue
+		}
+		material := Material{}
+		json.Unmarshal(materialValue, &material)
+		for index, element := range material.Batches {
+			if mode, exists := modes[batchKey(material.ParticipantID, material.MaterialMasterID, element.BatchNumber)]; exists {
+				setFlags(mode, &element.IsCompromised, &element.PotentialCompromised)
+				material.Batches[index] = element
+			}
+		}
+		jsonBytes, _ := json.Marshal(material)
+		if puterr := stub.PutState(materialID, jsonBytes); puterr != nil {
+			return puterr
+		}
+	}
+	return nil
+}
+
+//withBatchQuantities replaces the edge quantities of an impact by the quantities held in the Material batches
+func withBatchQuantities(stub shim.ChaincodeStubInterface, impact ContaminationImpact) (ContaminationImpact, error) {
+	materials := map[string]*Material{}
+	quantity := func(batch *AffectedBatch) error {
+		materialID := common.Key(batch.ParticipantID, batch.MaterialID)
+		material, exists := materials[materialID]
+		if !exists {
+			materialValue, geterr := stub.GetState(materialID)
+			if geterr != nil {
+				return geterr
+			}
+			material = &Material{}
+			json.Unmarshal(materialValue, material)
+			materials[materialID] = material
+		}
+		for _, element := range material.Batches {
+			if strings.ToLower(element.BatchNumber) == strings.ToLower(batch.BatchNumber) {
+				batch.Quantity = element.Quantity
+				break
+			}
+		}
+		return nil
+	}
+
+	if err := quantity(&impact.Origin); err != nil {
+		return impact, err
+	}
+	for index := range impact.Compromised {
+		if err := quantity(&impact.Compromised[index]); err != nil {
+			return impact, err
+		}
+	}
+	for index := range impact.PotentialCompromised {
+		if err := quantity(&impact.PotentialCompromised[index]); err != nil {
+			return impact, err
+		}
+	}
+	return impact, nil
+}
+
+//********************************************************************************************************
+// Contamination Functions
+//********************************************************************************************************
+
+// Preview Contamination - dry run of reportContamination, nothing is written to the ledger
+func (t *BlockchainIOT) previewContamination(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	contaminatedBatch := BatchContamination{}
+	err := json.Unmarshal([]byte(args[0]), &contaminatedBatch)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	// Get Material
+	materialValue, materialGetErr := stub.GetState(common.Key(contaminatedBatch.ParticipantID, contaminatedBatch.MaterialID))
+	if materialGetErr != nil || materialValue == nil {
+		return common.Error(http.StatusNotFound, "Material Does Not Exist! Please Check Participant ID and Material ID!")
+	}
+
+	impact, err := assessContamination(stub, contaminatedBatch)
+	if err == nil {
+		impact, err = withBatchQuantities(stub, impact)
+	}
+	if err != nil {
+		return common.Error(http.StatusInternalServerError, err.Error())
+	}
+	jsonBytes, _ := json.Marshal(impact)
+	return common.Success(http.StatusOK, "OK", jsonBytes)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+func TestContaminationSurvivesCyclicLineage(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer, distributor)
+
+	// Send part of the distributor batch back into the importer batch it came from
+	f.trade("PO-CYCLE", distributor, importer, 10)
+
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "reportContamination", map[string]string{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumber": importer.BatchNumber})
+	for _, tier := range []Tier{importer, distributor} {
+		if batch := f.batch(tier.ParticipantID, tier.MaterialID, tier.BatchNumber); !batch.IsCompromised || batch.PotentialCompromised {
+			t.Fatalf("expected %s batch compromised %+v", tier.ParticipantID, batch)
+		}
+	}
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, grower.BatchNumber); batch.IsCompromised || !batch.PotentialCompromised {
+		t.Fatalf("expected grower batch potentially compromised %+v", batch)
+	}
+
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "clearContamination", map[string]string{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumber": importer.BatchNumber})
+	for _, tier := range []Tier{grower, importer, distributor} {
+		if batch := f.batch(tier.ParticipantID, tier.MaterialID, tier.BatchNumber); batch.IsCompromised || batch.PotentialCompromised {
+			t.Fatalf("expected %s batch cleared %+v", tier.ParticipantID, batch)
+		}
+	}
+}
+
+func TestContaminationRespectsMaxDepth(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer, distributor, retailer)
+
+	f.mustInvoke(http.StatusCreated, "reportContamination", map[string]interface{}{"ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "BatchNumber": grower.BatchNumber, "MaxDepth": 2})
+	for _, tier := range []Tier{grower, importer, distributor} {
+		if batch := f.batch(tier.ParticipantID, tier.MaterialID, tier.BatchNumber); !batch.IsCompromised {
+			t.Fatalf("expected %s batch compromised %+v", tier.ParticipantID, batch)
+		}
+	}
+	if batch := f.batch(retailer.ParticipantID, retailer.MaterialID, retailer.BatchNumber); batch.IsCompromised {
+		t.Fatalf("expected retailer batch beyond the depth limit %+v", batch)
+	}
+
+	// Mappings and members agree with the materials
+	product := f.getProduct("PRODUCT01")
+	for _, element := range product.ReverseMappings {
+		for _, from := range element.From {
+			if from.ParticipantID == distributor.ParticipantID && !from.IsCompromised {
+				t.Fatalf("expected distributor compromised in reverse mappings %+v", element)
+			}
+		}
+	}
+	for _, element := range product.SupplyChainMembers {
+		if element.IsCompromised != (element.ParticipantID != retailer.ParticipantID) {
+			t.Fatalf("unexpected member %+v", element)
+		}
+	}
+}
+
+func TestAssessContaminationVisitsEachBatchOnce(t *testing.T) {
+	a := BatchTradeInfo{ParticipantID: "A", MaterialID: "M", BatchNumber: "1"}
+	b := BatchTradeInfo{ParticipantID: "B", MaterialID: "M", BatchNumber: "1"}
+	c := BatchTradeInfo{ParticipantID: "C", MaterialID: "M", BatchNumber: "1"}
+
+	stub := shim.NewMockStub("lineage", nil)
+	stub.MockTransactionStart("edges")
+	for _, edge := range [][2]BatchTradeInfo{{a, b}, {a, c}, {b, c}, {b, a}, {c, a}} {
+		if err := putLineageEdge(stub, []string{"P"}, edge[0], edge[1], 5, LineageOrder{Against: "PURCHASE ORDER", POID: "PO", GRNumber: "GR"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stub.MockTransactionEnd("edges")
+
+	impact, err := assessContamination(stub, BatchContamination{ParticipantID: "a", MaterialID: "m", BatchNumber: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(impact.Compromised) != 2 || impact.Compromised[0].Hops != 1 || impact.Compromised[1].Hops != 1 {
+		t.Fatalf("unexpected compromised batches %+v", impact.Compromised)
+	}
+	if len(impact.PotentialCompromised) != 0 {
+		t.Fatalf("batches downstream of the origin must not be potential %+v", impact.PotentialCompromised)
+	}
+}
+
+func TestPreviewContaminationWritesNothing(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer, distributor, retailer)
+
+	before := map[string]string{}
+	for key, value := range f.stub.State {
+		before[key] = string(value)
+	}
+	res := f.mustInvoke(http.StatusOK, "previewContamination", map[string]string{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumber": importer.BatchNumber})
+	for key, value := range f.stub.State {
+		if before[key] != string(value) {
+			t.Fatalf("preview changed %s", key)
+		}
+	}
+
+	impact := ContaminationImpact{}
+	json.Unmarshal(res.Payload, &impact)
+	if impact.Origin.BatchNumber != importer.BatchNumber || impact.Origin.Quantity != 0 {
+		t.Fatalf("unexpected origin %+v", impact.Origin)
+	}
+	if len(impact.Compromised) != 2 || impact.Compromised[0].ParticipantID != distributor.ParticipantID || impact.Compromised[0].Hops != 1 ||
+		impact.Compromised[1].ParticipantID != retailer.ParticipantID || impact.Compromised[1].Hops != 2 || impact.Compromised[1].Quantity != 100 {
+		t.Fatalf("unexpected compromised batches %+v", impact.Compromised)
+	}
+	if len(impact.PotentialCompromised) != 1 || impact.PotentialCompromised[0].ParticipantID != grower.ParticipantID || impact.PotentialCompromised[0].Hops != 1 {
+		t.Fatalf("unexpected potential batches %+v", impact.PotentialCompromised)
+	}
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, importer.BatchNumber); batch.IsCompromised {
+		t.Fatalf("preview must not flag the batch %+v", batch)
+	}
+
+	f.mustInvoke(http.StatusNotFound, "previewContamination", map[string]string{"ParticipantID": "NOBODY", "MaterialID": "MAT01"})
+}
@@ -0,0 +1,257 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Device Registry
+//****Description: IoT devices, their public keys and the shipments they report for
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//********************************************************************************************************
+//Struct for the Device Registry
+//********************************************************************************************************
+
+//IoT device signing the readings of the Shipments it is attached to
+type Device struct {
+	Asset_Type    string   `json:"Asset_Type,omitempty"`
+	DeviceID      string   `json:"DeviceID"`
+	ParticipantID string   `json:"ParticipantID"` // Owner of the device
+	KeyType       string   `json:"KeyType"`       // ED25519 or ECDSA
+	PublicKey     string   `json:"PublicKey"`     // base64 PKIX DER
+	Shipments     []string `json:"Shipments,omitempty"`
+	Sequence      uint64   `json:"Sequence"` // Sequence of the last accepted reading
+}
+
+//Object type of the composite key of a Device
+const deviceObjectType = "device"
+
+//Key for fetching/storing a Device
+func deviceKey(stub shim.ChaincodeStubInterface, deviceID string) (string, error) {
+	return stub.CreateCompositeKey(deviceObjectType, []string{strings.ToLower(deviceID)})
+}
+
+//deviceReadings verifies the signed readings of one transaction.
+//Devices are loaded once and stored once, a transaction does not read its own writes.
+type deviceReadings struct {
+	devices map[string]*Device
+	keys    []string
+}
+
+func newDeviceReadings() *deviceReadings {
+	return &deviceReadings{devices: map[string]*Device{}}
+}
+
+//verify checks a reading of a Shipment. A Shipment with attached Devices only accepts readings signed by one of them,
+//with a Sequence above the last one accepted from the Device. Unsigned readings are only accepted by a Shipment
+//created with UnsignedReadings, until a Device is attached to it.
+func (readings *deviceReadings) verify(stub shim.ChaincodeStubInterface, shipment Shipment, signature common.DeviceSignature, fields ...string) error {
+	if signature.DeviceID == "" {
+		if len(shipment.Devices) > 0 || !shipment.UnsignedReadings {
+			return fmt.Errorf("Shipment %s only accepts readings signed by its Devices", shipment.ShipmentID)
+		}
+		return nil
+	}
+	if !containsFold(shipment.Devices, signature.DeviceID) {
+		return fmt.Errorf("Device %s is not attached to Shipment %s", signature.DeviceID, shipment.ShipmentID)
+	}
+
+	key, keyErr := deviceKey(stub, signature.DeviceID)
+	if keyErr != nil {
+		return keyErr
+	}
+	device, loaded := readings.devices[key]
+	if !loaded {
+		deviceValue, geterr := stub.GetState(key)
+		if geterr != nil {
+			return geterr
+		}
+		if deviceValue == nil {
+			return fmt.Errorf("Device %s Does Not Exist", signature.DeviceID)
+		}
+		device = &Device{}
+		json.Unmarshal(deviceValue, device)
+		readings.devices[key] = device
+		readings.keys = append(readings.keys, key)
+	}
+
+	if err := signature.Check(common.DeviceKey{KeyType: device.KeyType, PublicKey: device.PublicKey}, device.Sequence, fields...); err != nil {
+		return err
+	}
+	device.Sequence = signature.Sequence
+	return nil
+}
+
+//store writes the Sequence of every Device that signed a reading
+func (readings *deviceReadings) store(stub shim.ChaincodeStubInterface) error {
+	for _, key := range readings.keys {
+		jsonBytes, _ := json.Marshal(readings.devices[key])
+		if puterr := stub.PutState(key, jsonBytes); puterr != nil {
+			return puterr
+		}
+	}
+	return nil
+}
+
+//gpsFields are the fields of a GPS reading signed by a device
+func gpsFields(reading GetGPSReading) []string {
+	return []string{readingGPS, reading.ShipmentID, reading.Timestamp, common.FormatNumber(reading.Latitude, 64), common.FormatNumber(reading.Longitude, 64), common.FormatNumber(float64(reading.Accuracy), 32)}
+}
+
+//********************************************************************************************************
+// Device Registry Functions
+//********************************************************************************************************
+
+// Register a Device - Arguments: {"DeviceID", "ParticipantID", "KeyType", "PublicKey"}
+// Participants register their own Devices, admins register Devices for any Participant
+func (t *BlockchainIOT) registerDevice(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		DeviceID      string `json:"DeviceID"`
+		ParticipantID string `json:"ParticipantID"`
+		KeyType       string `json:"KeyType"`
+		PublicKey     string `json:"PublicKey"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil || queryData.DeviceID == "" {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+	key, keyErr := common.DeviceKey{KeyType: queryData.KeyType, PublicKey: queryData.PublicKey}.Normalize()
+	if keyErr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: "+keyErr.Error())
+	}
+
+	// Check the Owner
+	participantID, scopeErr := queryScope(stub)
+	if scopeErr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeErr.Error())
+	}
+	if participantID != "" && queryData.ParticipantID != "" && !strings.EqualFold(participantID, queryData.ParticipantID) {
+		return common.Error(http.StatusForbidden, "Invoke Error: Devices can only be Registered for the Invoking Participant")
+	}
+	if participantID == "" {
+		participantID = queryData.ParticipantID
+	}
+	if participantValue, participantGetErr := stub.GetState(common.Key(participantID)); participantGetErr != nil || participantValue == nil {
+		return common.Error(http.StatusNotFound, "Participant Does Not Exist! Please Check Participant ID!")
+	}
+
+	device := Device{}
+	device.Asset_Type = "DEVICE"
+	device.DeviceID = queryData.DeviceID
+	device.ParticipantID = participantID
+	device.KeyType = key.KeyType
+	device.PublicKey = key.PublicKey
+
+	// Check If Exists
+	deviceID, keyErr := deviceKey(stub, device.DeviceID)
+	if keyErr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: "+keyErr.Error())
+	}
+	if value, geterr := stub.GetState(deviceID); !(geterr == nil && value == nil) {
+		return common.Error(http.StatusConflict, "Device Already Exists! \n Please Specify Another ID")
+	}
+
+	// Store in Blockchain
+	jsonBytes, _ := json.Marshal(device)
+	if puterr := stub.PutState(deviceID, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	return common.Success(http.StatusCreated, "Device Registered", nil)
+}
+
+// Attach a Device to a Shipment - Arguments: {"DeviceID", "ShipmentID"}
+// The owner of the Device must be the vendor or the requestor of the Purchase Order of the Shipment
+func (t *BlockchainIOT) attachDevice(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		DeviceID   string `json:"DeviceID"`
+		ShipmentID string `json:"ShipmentID"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	// Get Device
+	key, keyErr := deviceKey(stub, queryData.DeviceID)
+	if keyErr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: "+keyErr.Error())
+	}
+	deviceValue, deviceGetErr := stub.GetState(key)
+	if deviceGetErr != nil || deviceValue == nil {
+		return common.Error(http.StatusNotFound, "Device Does Not Exist! Please Check Device ID!")
+	}
+	device := Device{}
+	json.Unmarshal(deviceValue, &device)
+
+	// Only the Owner attaches the Device
+	participantID, scopeErr := queryScope(stub)
+	if scopeErr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeErr.Error())
+	}
+	if participantID != "" && !strings.EqualFold(participantID, device.ParticipantID) {
+		return common.Error(http.StatusForbidden, "Invoke Error: Only the Owner of the Device can Attach it")
+	}
+
+	// Get Shipment
+	shipmentID := common.Key(queryData.ShipmentID)
+	shipmentValue, shipmentGetErr := stub.GetState(shipmentID)
+	if shipmentGetErr != nil || shipmentValue == nil {
+		return common.Error(http.StatusNotFound, "Shipment Does Not Exists! \n Please Specify Another Shipment ID")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentValue, &shipment)
+	if shipment.Status == "COMPLETED" {
+		return common.Error(http.StatusBadRequest, "Shipment is already Completed")
+	}
+	if containsFold(shipment.Devices, device.DeviceID) {
+		return common.Error(http.StatusConflict, "Device is Already Attached to this Shipment")
+	}
+
+	// Check the Owner takes part in the Purchase Order
+	purchaseOrderValue, _ := stub.GetState(common.Key(shipment.POID))
+	purchaseOrder := PurchaseOrder{}
+	json.Unmarshal(purchaseOrderValue, &purchaseOrder)
+	if !containsFold([]string{purchaseOrder.VendorID, purchaseOrder.RequestorID}, device.ParticipantID) {
+		return common.Error(http.StatusForbidden, "Invoke Error: The Owner of the Device is not a Party of the Purchase Order of this Shipment")
+	}
+
+	// Update Shipment and Device
+	shipment.Devices = append(shipment.Devices, device.DeviceID)
+	device.Shipments = append(device.Shipments, shipment.ShipmentID)
+
+	// Store in Blockchain
+	shipmentJsonBytes, _ := json.Marshal(shipment)
+	if puterr := stub.PutState(shipmentID, shipmentJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	deviceJsonBytes, _ := json.Marshal(device)
+	if puterr := stub.PutState(key, deviceJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	return common.Success(http.StatusCreated, "Device Attached", nil)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+func TestAttachedDevicesSignShipmentReadings(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+	for _, element := range []Tier{grower, importer} {
+		f.participant(element.ParticipantID, element.ParticipantType)
+		f.material(element.ParticipantID, element.MaterialID, "PRODUCT01")
+	}
+	f.participant(distributor.ParticipantID, distributor.ParticipantType)
+	f.produce("PRO1", grower.ParticipantID, grower.MaterialID, grower.BatchNumber, 40)
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{"POID": "PO1", "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID, "VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": grower.BatchNumber, "Quantity": 10, "UnitOfMeasure": "KG", "NetPrice": 10, "Currency": "USD"})
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createShipment", map[string]interface{}{"ShipmentID": "SH1", "ProductBCID": "PRODUCT01", "POID": "PO1", "UnsignedReadings": true})
+
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	encoded := base64.StdEncoding.EncodeToString(publicKey)
+	sign := func(deviceID string, sequence uint64, fields []string) string {
+		return base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, common.SignedMessage(deviceID, sequence, fields...)))
+	}
+
+	// Registration
+	f.as(grower.ParticipantID).mustInvoke(http.StatusBadRequest, "registerDevice", map[string]string{"DeviceID": "D1", "KeyType": "ECDSA", "PublicKey": encoded})
+	f.mustInvoke(http.StatusForbidden, "registerDevice", map[string]string{"DeviceID": "D1", "ParticipantID": importer.ParticipantID, "KeyType": "ED25519", "PublicKey": encoded})
+	f.mustInvoke(http.StatusCreated, "registerDevice", map[string]string{"DeviceID": "D1", "KeyType": "ED25519", "PublicKey": encoded})
+	f.mustInvoke(http.StatusConflict, "registerDevice", map[string]string{"DeviceID": "D1", "KeyType": "ED25519", "PublicKey": encoded})
+	f.asAdmin().mustInvoke(http.StatusCreated, "registerDevice", map[string]string{"DeviceID": "D2", "ParticipantID": distributor.ParticipantID, "KeyType": "ED25519", "PublicKey": encoded})
+
+	// An unsigned reading is accepted until a Device is attached
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "trackShipment", map[string]interface{}{"ShipmentID": "SH1", "Latitude": 51.5, "Longitude": -0.12, "Timestamp": "2020-01-01T10:00:00Z"})
+	f.as(importer.ParticipantID).mustInvoke(http.StatusForbidden, "attachDevice", map[string]string{"DeviceID": "D1", "ShipmentID": "SH1"})
+	f.as(distributor.ParticipantID).mustInvoke(http.StatusForbidden, "attachDevice", map[string]string{"DeviceID": "D2", "ShipmentID": "SH1"})
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "attachDevice", map[string]string{"DeviceID": "D1", "ShipmentID": "SH1"})
+	f.mustInvoke(http.StatusConflict, "attachDevice", map[string]string{"DeviceID": "D1", "ShipmentID": "SH1"})
+	f.mustInvoke(http.StatusUnauthorized, "trackShipment", map[string]interface{}{"ShipmentID": "SH1", "Latitude": 51.6, "Longitude": -0.1, "Timestamp": "2020-01-01T10:01:00Z"})
+
+	gps := GetGPSReading{ShipmentID: "SH1", Latitude: 51.6, Longitude: -0.1, Accuracy: 2.5, Timestamp: "2020-01-01T10:01:00Z"}
+	signed := map[string]interface{}{"ShipmentID": "SH1", "Latitude": 51.6, "Longitude": -0.1, "Accuracy": 2.5, "Timestamp": gps.Timestamp, "DeviceID": "D1", "Sequence": 1, "Signature": sign("D1", 1, gpsFields(gps))}
+	f.mustInvoke(http.StatusCreated, "trackShipment", signed)
+	f.mustInvoke(http.StatusUnauthorized, "trackShipment", signed)
+	signed["Sequence"] = 2
+	f.mustInvoke(http.StatusUnauthorized, "trackShipment", signed)
+	signed["DeviceID"] = "D2"
+	f.mustInvoke(http.StatusUnauthorized, "trackShipment", signed)
+
+	sensor := common.SensorReading{TemperatureCelsius: 4, Humidity: 60, Timestamp: "2020-01-01T10:02:00Z"}
+	f.mustInvoke(http.StatusCreated, "recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": 4, "Humidity": 60, "Timestamp": sensor.Timestamp, "DeviceID": "D1", "Sequence": 5, "Signature": sign("D1", 5, sensor.SignedFields("SH1"))})
+	f.mustInvoke(http.StatusUnauthorized, "recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": 4, "Timestamp": "2020-01-01T10:03:00Z"})
+
+	// A bulk upload keeps the Sequence growing across its readings
+	late := GetGPSReading{ShipmentID: "SH1", Latitude: 51.7, Longitude: -0.1, Timestamp: "2020-01-01T10:04:00Z"}
+	later := GetGPSReading{ShipmentID: "SH1", Latitude: 51.8, Longitude: -0.1, Timestamp: "2020-01-01T10:05:00Z"}
+	readings := []IngestReading{
+		{ShipmentID: "SH1", Type: "GPS", Timestamp: late.Timestamp, Latitude: late.Latitude, Longitude: late.Longitude, DeviceSignature: common.DeviceSignature{DeviceID: "D1", Sequence: 6, Signature: sign("D1", 6, gpsFields(late))}},
+		{ShipmentID: "SH1", Type: "GPS", Timestamp: later.Timestamp, Latitude: later.Latitude, Longitude: later.Longitude, DeviceSignature: common.DeviceSignature{DeviceID: "D1", Sequence: 6, Signature: sign("D1", 6, gpsFields(later))}},
+		{ShipmentID: "SH1", Type: "GPS", Timestamp: "2020-01-01T10:06:00Z", Latitude: 51.9, Longitude: -0.1},
+	}
+	report := IngestReport{}
+	json.Unmarshal(f.mustInvoke(http.StatusCreated, "ingestReadings", map[string]interface{}{"Readings": readings}).Payload, &report)
+	if len(report.Accepted) != 1 || len(report.Rejected) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	device := Device{}
+	key, _ := deviceKey(f.stub, "D1")
+	value, _ := f.stub.GetState(key)
+	json.Unmarshal(value, &device)
+	if device.Sequence != 6 || device.ParticipantID != grower.ParticipantID || len(device.Shipments) != 1 {
+		t.Fatalf("unexpected device %+v", device)
+	}
+	if shipment := f.getShipment("SH1"); len(shipment.GPSReading) != 3 || len(shipment.SensorReadings) != 1 || len(shipment.Devices) != 1 {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+}
+
+func TestShipmentsRejectUnsignedReadingsUnlessOptedIn(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+	for _, element := range []Tier{grower, importer} {
+		f.participant(element.ParticipantID, element.ParticipantType)
+		f.material(element.ParticipantID, element.MaterialID, "PRODUCT01")
+	}
+	f.produce("PRO1", grower.ParticipantID, grower.MaterialID, grower.BatchNumber, 40)
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{"POID": "PO1", "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID, "VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": grower.BatchNumber, "Quantity": 10, "UnitOfMeasure": "KG", "NetPrice": 10, "Currency": "USD"})
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createShipment", map[string]string{"ShipmentID": "SH1", "ProductBCID": "PRODUCT01", "POID": "PO1"})
+
+	f.mustInvoke(http.StatusUnauthorized, "trackShipment", map[string]interface{}{"ShipmentID": "SH1", "Latitude": 51.5, "Longitude": -0.12, "Timestamp": "2020-01-01T10:00:00Z"})
+	f.mustInvoke(http.StatusUnauthorized, "recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": 4, "Timestamp": "2020-01-01T10:00:00Z"})
+	if shipment := f.getShipment("SH1"); shipment.UnsignedReadings || len(shipment.GPSReading) != 0 || len(shipment.SensorReadings) != 0 {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+}
@@ -0,0 +1,115 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Events
+//****Description: Chaincode events emitted by the state-changing BlockchainIOT transactions
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+//Event names, Fabric keeps one event per transaction
+const (
+	eventProductionOrderCancelled = "ProductionOrderCancelled"
+	eventPurchaseOrderCreated     = "PurchaseOrderCreated"
+	eventPurchaseOrderCancelled   = "PurchaseOrderCancelled"
+	eventShipmentCreated          = "ShipmentCreated"
+	eventShipmentTracked          = "ShipmentTracked"
+	eventGeofenceCrossed          = "GeofenceCrossed"
+	eventSensorReadingRecorded    = "SensorReadingRecorded"
+	eventTemperatureExcursion     = "TemperatureExcursion"
+	eventReadingsIngested         = "ReadingsIngested"
+	eventGoodsReceived            = "GoodsReceived"
+	eventBatchSplit               = "BatchSplit"
+	eventBatchesMerged            = "BatchesMerged"
+	eventRecall                   = "Recall"
+	eventContaminationCleared     = "ContaminationCleared"
+	eventAssetDeleted             = "AssetDeleted"
+)
+
+//********************************************************************************************************
+//Struct for Events
+//********************************************************************************************************
+
+//Payload of every BlockchainIOT event. Keys are the ledger keys the transaction wrote or deleted.
+type ChaincodeEvent struct {
+	EventType      string               `json:"EventType"`
+	TxID           string               `json:"TxID"`
+	Keys           []string             `json:"Keys"`
+	PreviousStatus string               `json:"PreviousStatus,omitempty"`
+	Status         string               `json:"Status,omitempty"`
+	Participants   []string             `json:"Participants,omitempty"`
+	Impact         *ContaminationImpact `json:"Impact,omitempty"`
+}
+
+//transaction records the keys a transaction function puts or deletes, once each and in order.
+//It holds the event of the function until it returns, so the event lists every key written.
+type transaction struct {
+	shim.ChaincodeStubInterface
+	keys  []string
+	event *ChaincodeEvent
+}
+
+func newTransaction(stub shim.ChaincodeStubInterface) *transaction {
+	return &transaction{ChaincodeStubInterface: stub}
+}
+
+func (stub *transaction) record(key string) {
+	for _, element := range stub.keys {
+		if element == key {
+			return
+		}
+	}
+	stub.keys = append(stub.keys, key)
+}
+
+func (stub *transaction) PutState(key string, value []byte) error {
+	stub.record(key)
+	return stub.ChaincodeStubInterface.PutState(key, value)
+}
+
+func (stub *transaction) DelState(key string) error {
+	stub.record(key)
+	return stub.ChaincodeStubInterface.DelState(key)
+}
+
+//emit sets the event held for the transaction with the keys written
+func (stub *transaction) emit() error {
+	if stub.event == nil {
+		return nil
+	}
+	stub.event.Keys = append([]string{}, stub.keys...)
+	jsonBytes, _ := json.Marshal(stub.event)
+	return stub.ChaincodeStubInterface.SetEvent(stub.event.EventType, jsonBytes)
+}
+
+//emitEvent sets the event of the transaction. Within Invoke the event is held until the function returns
+//and lists the keys it wrote, otherwise it is set at once without keys.
+func emitEvent(stub shim.ChaincodeStubInterface, event ChaincodeEvent) error {
+	event.TxID = stub.GetTxID()
+	event.Keys = []string{}
+	if tx, recorded := stub.(*transaction); recorded {
+		tx.event = &event
+		return nil
+	}
+	jsonBytes, _ := json.Marshal(event)
+	return stub.SetEvent(event.EventType, jsonBytes)
+}
+
+//contaminationEvent lists every batch and participant touched by a contamination report or clearance
+func contaminationEvent(eventType string, status string, impact ContaminationImpact) ChaincodeEvent {
+	event := ChaincodeEvent{EventType: eventType, Status: status, Impact: &impact}
+	batches := append([]AffectedBatch{impact.Origin}, append(impact.Compromised, impact.PotentialCompromised...)...)
+	for _, element := range batches {
+		if !containsFold(event.Participants, element.ParticipantID) {
+			event.Participants = append(event.Participants, element.ParticipantID)
+		}
+	}
+	return event
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestStateChangesEmitEvents(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+
+	f.as(importer.ParticipantID).mustInvoke(http.StatusBadRequest, "createPurchaseOrder", map[string]interface{}{
+		"POID": "PO2", "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID,
+		"VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": grower.BatchNumber, "Quantity": 0,
+	})
+	if f.event != nil {
+		t.Fatalf("rejected transaction should not emit an event %v", f.event)
+	}
+
+	f.produce("PRO-B2", grower.ParticipantID, grower.MaterialID, "B2", 10)
+	if event := f.lastEvent(eventGoodsReceived); event.PreviousStatus != "OPEN" || event.Status != "COMPLETED" || event.Keys[0] != "pro-b2" {
+		t.Fatalf("unexpected goods receipt event %+v", event)
+	}
+
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{
+		"POID": "PO2", "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID,
+		"VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": "B2", "Quantity": 5,
+	})
+	if event := f.lastEvent(eventPurchaseOrderCreated); event.Status != "OPEN" || len(event.Keys) != 2 || event.Keys[1] != "grower01-mat01" || event.TxID != fmt.Sprintf("tx%d", f.tx) {
+		t.Fatalf("unexpected purchase order event %+v", event)
+	}
+	f.mustInvoke(http.StatusOK, "cancelPurchaseOrder", "PO2")
+	if event := f.lastEvent(eventPurchaseOrderCancelled); event.PreviousStatus != "OPEN" || event.Status != "CANCELLED" {
+		t.Fatalf("unexpected cancel event %+v", event)
+	}
+
+	f.asAdmin().mustInvoke(http.StatusNoContent, "deleteAsset", "PO2")
+	if event := f.lastEvent(eventAssetDeleted); event.PreviousStatus != "CANCELLED" || event.Status != "DELETED" || event.Keys[0] != "po2" {
+		t.Fatalf("unexpected delete event %+v", event)
+	}
+}
+
+func TestShipmentEvents(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower)
+	f.participant(importer.ParticipantID, importer.ParticipantType)
+	f.material(importer.ParticipantID, importer.MaterialID, "PRODUCT01")
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{
+		"POID": "PO1", "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID,
+		"VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": grower.BatchNumber, "Quantity": 5,
+	})
+
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createShipment", map[string]interface{}{"ShipmentID": "SH1", "ProductBCID": "PRODUCT01", "POID": "PO1", "UnsignedReadings": true})
+	if event := f.lastEvent(eventShipmentCreated); event.Status != "SHIPPING" || event.Keys[0] != "sh1" || event.Keys[1] != "po1" {
+		t.Fatalf("unexpected shipment event %+v", event)
+	}
+	f.mustInvoke(http.StatusCreated, "trackShipment", map[string]interface{}{"ShipmentID": "SH1", "Latitude": 1.5, "Longitude": 2.5})
+	if event := f.lastEvent(eventShipmentTracked); event.Status != "SHIPPING" {
+		t.Fatalf("unexpected tracking event %+v", event)
+	}
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "submitGoodsReceipt", map[string]string{"GRNumber": "GR1", "ReceivedBy": importer.ParticipantID, "Against": "PURCHASE ORDER", "POID": "PO1", "BatchNumber": importer.BatchNumber})
+	if event := f.lastEvent(eventGoodsReceived); event.Status != "COMPLETED" || flatKeys(event) != "po1 sh1 product01 grower01-mat01 importer01-mat02 gr1" || len(event.Keys) != 9 {
+		t.Fatalf("unexpected goods receipt event %+v", event)
+	}
+}
+
+func TestContaminationEmitsRecall(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer, distributor, retailer)
+
+	f.mustInvoke(http.StatusCreated, "reportContamination", map[string]string{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumber": importer.BatchNumber})
+	event := f.lastEvent(eventRecall)
+	if len(event.Participants) != 4 || event.Participants[0] != importer.ParticipantID || event.Impact == nil || len(event.Impact.Compromised) != 2 {
+		t.Fatalf("unexpected recall event %+v", event)
+	}
+
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "clearContamination", map[string]string{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumber": importer.BatchNumber})
+	if event := f.lastEvent(eventContaminationCleared); event.Status != "CLEARED" || len(event.Participants) != 4 {
+		t.Fatalf("unexpected clear event %+v", event)
+	}
+}
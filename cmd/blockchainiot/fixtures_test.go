@@ -0,0 +1,373 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//********************************************************************************************************
+// Test Fixtures
+//********************************************************************************************************
+
+//fixture wraps a MockStub running BlockchainIOT, numbers every transaction it sends,
+//signs it as the current identity and keeps the event it emitted
+type fixture struct {
+	t        *testing.T
+	stub     *shim.MockStub
+	tx       int
+	identity common.Identity
+	event    *peer.ChaincodeEvent
+	history  *ledgerHistory
+}
+
+//ledgerHistory keeps the versions written by every transaction, since the MockStub keeps no history.
+//Transaction N is timestamped N minutes after start.
+type ledgerHistory struct {
+	start    time.Time
+	state    map[string][]byte
+	versions map[string][]*queryresult.KeyModification
+}
+
+//historyStub answers GetHistoryForKey from the recorded history
+type historyStub struct {
+	*shim.MockStub
+	history *ledgerHistory
+}
+
+type historyIterator struct {
+	modifications []*queryresult.KeyModification
+}
+
+//Tier describes one participant of a supply chain built with supplyChain
+type Tier struct {
+	ParticipantID   string
+	ParticipantType string
+	MaterialID      string
+	BatchNumber     string
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{t: t, stub: shim.NewMockStub("blockchainiot", new(BlockchainIOT))}
+	getCreatorIdentity = func(stub shim.ChaincodeStubInterface) (common.Identity, error) {
+		if f.identity.EnrollmentID == "" {
+			return f.identity, fmt.Errorf("no identity")
+		}
+		return f.identity, nil
+	}
+	t.Cleanup(func() { getCreatorIdentity = common.GetIdentity })
+	f.asAdmin()
+	if res := f.stub.MockInit("init", [][]byte{[]byte("init")}); res.Status != http.StatusOK {
+		t.Fatalf("Init failed: %d %s", res.Status, res.Message)
+	}
+	return f
+}
+
+//as signs the following transactions as the identity enrolled for a participant
+func (f *fixture) as(participantID string) *fixture {
+	f.identity = common.Identity{MSPID: "Org1MSP", EnrollmentID: participantID}
+	return f
+}
+
+//asAdmin signs the following transactions as an admin of Org1MSP
+func (f *fixture) asAdmin() *fixture {
+	f.identity = common.Identity{MSPID: "Org1MSP", EnrollmentID: "admin", Type: "admin"}
+	return f
+}
+
+//invoke sends a function with a JSON payload followed by any plain string arguments
+func (f *fixture) invoke(function string, payload interface{}, extra ...string) peer.Response {
+	f.t.Helper()
+	args := [][]byte{[]byte(function)}
+	if payload != nil {
+		switch value := payload.(type) {
+		case string:
+			args = append(args, []byte(value))
+		default:
+			jsonBytes, err := json.Marshal(value)
+			if err != nil {
+				f.t.Fatalf("Cannot marshal payload for %s: %s", function, err)
+			}
+			args = append(args, jsonBytes)
+		}
+	}
+	for _, element := range extra {
+		args = append(args, []byte(element))
+	}
+	f.tx++
+	res := f.stub.MockInvoke(fmt.Sprintf("tx%d", f.tx), args)
+	if f.history != nil {
+		f.history.record(fmt.Sprintf("tx%d", f.tx), f.txTime(f.tx), f.stub.State)
+	}
+
+	// Drain the event channel so MockStub never blocks on SetEvent
+	f.event = nil
+	for {
+		select {
+		case event := <-f.stub.ChaincodeEventsChannel:
+			f.event = event
+		default:
+			return res
+		}
+	}
+}
+
+//mustInvoke fails the test if the function does not answer with the expected status
+func (f *fixture) mustInvoke(status int32, function string, payload interface{}, extra ...string) peer.Response {
+	f.t.Helper()
+	res := f.invoke(function, payload, extra...)
+	if res.Status != status {
+		f.t.Fatalf("%s: expected status %d, got %d (%s)", function, status, res.Status, res.Message)
+	}
+	return res
+}
+
+//recordHistory keeps the versions written by the following transactions
+func (f *fixture) recordHistory() *fixture {
+	f.history = &ledgerHistory{start: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), state: map[string][]byte{}, versions: map[string][]*queryresult.KeyModification{}}
+	f.history.record("recording", f.history.start, f.stub.State)
+	return f
+}
+
+//txTime is the timestamp of a transaction while the history is recorded
+func (f *fixture) txTime(tx int) time.Time {
+	return f.history.start.Add(time.Duration(tx) * time.Minute)
+}
+
+//historyStub gives the recorded history to a function called directly
+func (f *fixture) historyStub() *historyStub {
+	f.stub.MockTransactionStart("history")
+	f.t.Cleanup(func() { f.stub.MockTransactionEnd("history") })
+	return &historyStub{MockStub: f.stub, history: f.history}
+}
+
+func (history *ledgerHistory) record(txID string, at time.Time, state map[string][]byte) {
+	modification := func(value []byte, isDelete bool) *queryresult.KeyModification {
+		return &queryresult.KeyModification{TxId: txID, Value: value, Timestamp: &timestamp.Timestamp{Seconds: at.Unix()}, IsDelete: isDelete}
+	}
+	for key, value := range state {
+		if before, found := history.state[key]; !found || string(before) != string(value) {
+			history.versions[key] = append(history.versions[key], modification(value, false))
+		}
+	}
+	for key := range history.state {
+		if _, found := state[key]; !found {
+			history.versions[key] = append(history.versions[key], modification(nil, true))
+		}
+	}
+	history.state = map[string][]byte{}
+	for key, value := range state {
+		history.state[key] = value
+	}
+}
+
+func (stub *historyStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	return &historyIterator{stub.history.versions[key]}, nil
+}
+
+func (iterator *historyIterator) HasNext() bool { return len(iterator.modifications) > 0 }
+func (iterator *historyIterator) Close() error  { return nil }
+func (iterator *historyIterator) Next() (*queryresult.KeyModification, error) {
+	modification := iterator.modifications[0]
+	iterator.modifications = iterator.modifications[1:]
+	return modification, nil
+}
+
+//********************
+// BUILDERS
+//********************
+
+func (f *fixture) participant(participantID string, participantType string) {
+	f.t.Helper()
+	f.as(participantID).mustInvoke(http.StatusCreated, "createParticipant", map[string]string{
+		"ParticipantID":   participantID,
+		"ParticipantType": participantType,
+		"CompanyName":     participantID + " Ltd",
+		"ContactEmail":    participantID + "@example.com",
+	})
+	f.asAdmin().mustInvoke(http.StatusOK, "approveParticipant", map[string]string{"ParticipantID": participantID})
+	f.as(participantID)
+}
+
+func (f *fixture) product(productID string) {
+	f.t.Helper()
+	f.asAdmin().mustInvoke(http.StatusCreated, "createProduct", map[string]string{
+		"ProductID":   productID,
+		"ProductType": "PERISHABLE",
+	})
+}
+
+func (f *fixture) material(participantID string, materialID string, productID string) {
+	f.t.Helper()
+	f.as(participantID).mustInvoke(http.StatusCreated, "registerMaterial", map[string]string{
+		"ParticipantID":    participantID,
+		"MaterialMasterID": materialID,
+		"ProductBCID":      productID,
+		"UnitOfMeasure":    "KG",
+	})
+}
+
+//produce creates a production order and receives its goods into a batch
+func (f *fixture) produce(orderID string, participantID string, materialID string, batchNumber string, quantity int) {
+	f.t.Helper()
+	f.as(participantID).mustInvoke(http.StatusCreated, "createProductionOrder", map[string]interface{}{
+		"POID":          orderID,
+		"ParticipantID": participantID,
+		"MaterialID":    materialID,
+		"Quantity":      quantity,
+		"UnitOfMeasure": "KG",
+	})
+	f.mustInvoke(http.StatusCreated, "submitGoodsReceipt", map[string]interface{}{
+		"GRNumber":    "GR-" + orderID,
+		"ReceivedBy":  participantID,
+		"Against":     "PRODUCTION ORDER",
+		"POID":        orderID,
+		"BatchNumber": batchNumber,
+	})
+}
+
+//trade moves quantity from a vendor batch to a requestor batch through a purchase order,
+//a shipment with one GPS reading and a goods receipt, each signed by the participant doing it
+func (f *fixture) trade(orderID string, vendor Tier, requestor Tier, quantity int) {
+	f.t.Helper()
+	f.as(requestor.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{
+		"POID":                orderID,
+		"RequestorID":         requestor.ParticipantID,
+		"RequestorMaterialID": requestor.MaterialID,
+		"VendorID":            vendor.ParticipantID,
+		"VendorMaterialID":    vendor.MaterialID,
+		"VendorBatchNumber":   vendor.BatchNumber,
+		"Quantity":            quantity,
+		"UnitOfMeasure":       "KG",
+		"NetPrice":            10,
+		"Currency":            "USD",
+	})
+	f.as(vendor.ParticipantID).mustInvoke(http.StatusCreated, "createShipment", map[string]interface{}{
+		"ShipmentID":       "SH-" + orderID,
+		"ProductBCID":      f.getMaterial(vendor.ParticipantID, vendor.MaterialID).ProductBCID,
+		"POID":             orderID,
+		"UnsignedReadings": true,
+	})
+	f.mustInvoke(http.StatusCreated, "trackShipment", map[string]interface{}{
+		"ShipmentID": "SH-" + orderID,
+		"Latitude":   51.5,
+		"Longitude":  -0.12,
+		"Accuracy":   5,
+	})
+	f.as(requestor.ParticipantID).mustInvoke(http.StatusCreated, "submitGoodsReceipt", map[string]interface{}{
+		"GRNumber":    "GR-" + orderID,
+		"ReceivedBy":  requestor.ParticipantID,
+		"Against":     "PURCHASE ORDER",
+		"POID":        orderID,
+		"BatchNumber": requestor.BatchNumber,
+	})
+}
+
+//supplyChain enrolls every tier, produces quantity at the first tier and trades it
+//down the chain one tier at a time, e.g. grower->importer->distributor->retailer
+func (f *fixture) supplyChain(productID string, quantity int, tiers ...Tier) {
+	f.t.Helper()
+	f.product(productID)
+	for _, element := range tiers {
+		f.participant(element.ParticipantID, element.ParticipantType)
+		f.material(element.ParticipantID, element.MaterialID, productID)
+	}
+	f.produce("PRO-"+tiers[0].BatchNumber, tiers[0].ParticipantID, tiers[0].MaterialID, tiers[0].BatchNumber, quantity)
+	for index := 1; index < len(tiers); index++ {
+		f.trade("PO-"+tiers[index].BatchNumber, tiers[index-1], tiers[index], quantity)
+	}
+}
+
+//********************
+// GETTERS
+//********************
+
+//lastEvent decodes the event of the last transaction, failing the test if it emitted another one
+func (f *fixture) lastEvent(eventType string) ChaincodeEvent {
+	f.t.Helper()
+	event := ChaincodeEvent{}
+	if f.event == nil || f.event.EventName != eventType {
+		f.t.Fatalf("expected event %s, got %v", eventType, f.event)
+	}
+	if err := json.Unmarshal(f.event.Payload, &event); err != nil {
+		f.t.Fatalf("Cannot decode event: %s", err)
+	}
+	for _, key := range event.Keys {
+		if value, _ := f.stub.GetState(key); value == nil && eventType != eventAssetDeleted {
+			f.t.Fatalf("event %s lists %q, which the transaction did not write", eventType, key)
+		}
+	}
+	return event
+}
+
+//flatKeys joins the keys of an event that are not composite keys, in the order they were written
+func flatKeys(event ChaincodeEvent) string {
+	keys := []string{}
+	for _, key := range event.Keys {
+		if !strings.HasPrefix(key, "\x00") {
+			keys = append(keys, key)
+		}
+	}
+	return strings.Join(keys, " ")
+}
+
+//getState reads a record as an admin, whatever the current identity, so tests can inspect any record
+func (f *fixture) getState(key string, out interface{}) {
+	f.t.Helper()
+	identity := f.identity
+	res := f.asAdmin().mustInvoke(http.StatusOK, "getAsset", key)
+	f.identity = identity
+	if err := json.Unmarshal(res.Payload, out); err != nil {
+		f.t.Fatalf("Cannot decode %s: %s", key, err)
+	}
+}
+
+func (f *fixture) getProduct(productID string) Product {
+	f.t.Helper()
+	product := Product{}
+	f.getState(productID, &product)
+	return product
+}
+
+func (f *fixture) getMaterial(participantID string, materialID string) Material {
+	f.t.Helper()
+	material := Material{}
+	f.getState(participantID+"-"+materialID, &material)
+	return material
+}
+
+func (f *fixture) getPurchaseOrder(orderID string) PurchaseOrder {
+	f.t.Helper()
+	purchaseOrder := PurchaseOrder{}
+	f.getState(orderID, &purchaseOrder)
+	return purchaseOrder
+}
+
+func (f *fixture) getShipment(shipmentID string) Shipment {
+	f.t.Helper()
+	shipment := Shipment{}
+	f.getState(shipmentID, &shipment)
+	return shipment
+}
+
+//batch returns the batch of a material, failing the test if it does not exist
+func (f *fixture) batch(participantID string, materialID string, batchNumber string) BatchInfo {
+	f.t.Helper()
+	for _, element := range f.getMaterial(participantID, materialID).Batches {
+		if element.BatchNumber == batchNumber {
+			return element
+		}
+	}
+	f.t.Fatalf("Batch %s not found in material %s-%s", batchNumber, participantID, materialID)
+	return BatchInfo{}
+}
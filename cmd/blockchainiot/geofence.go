@@ -0,0 +1,235 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Geofences
+//****Description: Geofences of participant plants and storage locations, shipment arrivals and departures
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//Location types of a Geofence
+var geofenceLocationTypes = []string{"PLANT", "STORAGE"}
+
+//Milestones recorded when a Shipment crosses a Geofence
+const (
+	milestoneArrival   = "ARRIVAL"
+	milestoneDeparture = "DEPARTURE"
+)
+
+//Shipment Status set by the Geofences it crosses, until its Goods Receipt completes it
+const (
+	shipmentAtOrigin  = "AT_ORIGIN"
+	shipmentInTransit = "IN_TRANSIT"
+	shipmentArrived   = "ARRIVED"
+)
+
+//********************************************************************************************************
+//Struct for Geofences
+//********************************************************************************************************
+
+//Area around a plant or storage location of a Participant
+type Geofence struct {
+	Asset_Type    string         `json:"Asset_Type,omitempty"`
+	GeofenceID    string         `json:"GeofenceID"`
+	ParticipantID string         `json:"ParticipantID"` // Owner of the location
+	LocationType  string         `json:"LocationType"`  // PLANT or STORAGE
+	Name          string         `json:"Name,omitempty"`
+	Area          common.GeoArea `json:"Area"`
+}
+
+//Arrival into or departure from a Geofence, timestamped by the GPS reading that crossed it
+type Milestone struct {
+	Type          string `json:"Type"` // ARRIVAL or DEPARTURE
+	GeofenceID    string `json:"GeofenceID"`
+	ParticipantID string `json:"ParticipantID"`
+	LocationType  string `json:"LocationType"`
+	Timestamp     string `json:"Timestamp"`
+}
+
+//Object type of the composite key of a Geofence
+const geofenceObjectType = "geofence"
+
+//Key for fetching/storing a Geofence
+func geofenceKey(stub shim.ChaincodeStubInterface, geofenceID string) (string, error) {
+	return stub.CreateCompositeKey(geofenceObjectType, []string{strings.ToLower(geofenceID)})
+}
+
+//shipmentRoute holds the Geofences a Shipment is checked against:
+//those of the vendor of its Purchase Order, the origin, and of the requestor, the destination
+type shipmentRoute struct {
+	vendorID    string
+	requestorID string
+	geofences   []Geofence
+}
+
+//loadShipmentRoute gets the Geofences of both parties of the Purchase Order of a Shipment.
+//Geofences deleted since they were created are skipped.
+func loadShipmentRoute(stub shim.ChaincodeStubInterface, shipment Shipment) (shipmentRoute, error) {
+	route := shipmentRoute{}
+	purchaseOrderValue, geterr := stub.GetState(common.Key(shipment.POID))
+	if geterr != nil {
+		return route, geterr
+	}
+	purchaseOrder := PurchaseOrder{}
+	json.Unmarshal(purchaseOrderValue, &purchaseOrder)
+	route.vendorID = purchaseOrder.VendorID
+	route.requestorID = purchaseOrder.RequestorID
+
+	for _, participantID := range []string{route.vendorID, route.requestorID} {
+		participantValue, geterr := stub.GetState(common.Key(participantID))
+		if geterr != nil {
+			return route, geterr
+		}
+		participant := Participant{}
+		json.Unmarshal(participantValue, &participant)
+		for _, element := range participant.Geofences {
+			key, keyErr := geofenceKey(stub, element)
+			if keyErr != nil {
+				return route, keyErr
+			}
+			geofenceValue, geterr := stub.GetState(key)
+			if geterr != nil {
+				return route, geterr
+			}
+			if geofenceValue == nil {
+				continue
+			}
+			geofence := Geofence{}
+			json.Unmarshal(geofenceValue, &geofence)
+			route.geofences = append(route.geofences, geofence)
+		}
+	}
+	return route, nil
+}
+
+//crossGeofences records the Geofences a GPS reading entered or left and returns the new Milestones.
+//On a crossing the Status becomes ARRIVED inside a destination Geofence, AT_ORIGIN inside an origin Geofence
+//and IN_TRANSIT outside of both.
+func (shipment *Shipment) crossGeofences(route shipmentRoute, reading GetGPSReading) []Milestone {
+	point := common.GeoPoint{Latitude: reading.Latitude, Longitude: reading.Longitude}
+	inside := []string{}
+	milestones := []Milestone{}
+	atOrigin, atDestination := false, false
+	for _, element := range route.geofences {
+		now := element.Area.Contains(point)
+		was := containsFold(shipment.Geofences, element.GeofenceID)
+		if now {
+			inside = append(inside, element.GeofenceID)
+			atOrigin = atOrigin || strings.EqualFold(element.ParticipantID, route.vendorID)
+			atDestination = atDestination || strings.EqualFold(element.ParticipantID, route.requestorID)
+		}
+		if now == was {
+			continue
+		}
+		milestone := Milestone{Type: milestoneDeparture, GeofenceID: element.GeofenceID, ParticipantID: element.ParticipantID, LocationType: element.LocationType, Timestamp: reading.Timestamp}
+		if now {
+			milestone.Type = milestoneArrival
+		}
+		milestones = append(milestones, milestone)
+	}
+	shipment.Geofences = inside
+	if len(milestones) == 0 {
+		return milestones
+	}
+
+	shipment.Milestones = append(shipment.Milestones, milestones...)
+	switch {
+	case atDestination:
+		shipment.Status = shipmentArrived
+	case atOrigin:
+		shipment.Status = shipmentAtOrigin
+	default:
+		shipment.Status = shipmentInTransit
+	}
+	return milestones
+}
+
+//milestoneEvent lists the Participants owning the Geofences crossed by the Milestones
+func milestoneEvent(event *ChaincodeEvent, milestones []Milestone) {
+	for _, element := range milestones {
+		if !containsFold(event.Participants, element.ParticipantID) {
+			event.Participants = append(event.Participants, element.ParticipantID)
+		}
+	}
+}
+
+//********************************************************************************************************
+// Geofence Functions
+//********************************************************************************************************
+
+// Create a Geofence - Arguments: {"GeofenceID", "ParticipantID", "LocationType", "Name", "Area"}
+// Area: {"Shape": "CIRCLE", "Center": {"Latitude", "Longitude"}, "RadiusMeters"} or {"Shape": "POLYGON", "Vertices": [...]}
+// Participants create Geofences of their own locations, admins create Geofences for any Participant
+func (t *BlockchainIOT) createGeofence(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	geofence := Geofence{}
+	err := json.Unmarshal([]byte(args[0]), &geofence)
+	if err != nil || geofence.GeofenceID == "" {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+	geofence.LocationType = strings.ToUpper(geofence.LocationType)
+	if !containsFold(geofenceLocationTypes, geofence.LocationType) {
+		return common.Error(http.StatusBadRequest, "Invoke Error: LocationType must be PLANT or STORAGE")
+	}
+	area, areaErr := geofence.Area.Normalize()
+	if areaErr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: "+areaErr.Error())
+	}
+	geofence.Area = area
+	geofence.Asset_Type = "GEOFENCE"
+
+	// Check the Owner
+	participantID, scopeErr := queryScope(stub)
+	if scopeErr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeErr.Error())
+	}
+	if participantID != "" && geofence.ParticipantID != "" && !strings.EqualFold(participantID, geofence.ParticipantID) {
+		return common.Error(http.StatusForbidden, "Invoke Error: Geofences can only be Created for the Invoking Participant")
+	}
+	if participantID != "" {
+		geofence.ParticipantID = participantID
+	}
+	participantValue, participantGetErr := stub.GetState(common.Key(geofence.ParticipantID))
+	if participantGetErr != nil || participantValue == nil {
+		return common.Error(http.StatusNotFound, "Participant Does Not Exist! Please Check Participant ID!")
+	}
+	participant := Participant{}
+	json.Unmarshal(participantValue, &participant)
+
+	// Check If Exists
+	geofenceID, keyErr := geofenceKey(stub, geofence.GeofenceID)
+	if keyErr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: "+keyErr.Error())
+	}
+	if value, geterr := stub.GetState(geofenceID); !(geterr == nil && value == nil) {
+		return common.Error(http.StatusConflict, "Geofence Already Exists! \n Please Specify Another ID")
+	}
+
+	// Register the Geofence to the Participant
+	participant.Geofences = append(participant.Geofences, geofence.GeofenceID)
+
+	// Store in Blockchain
+	jsonBytes, _ := json.Marshal(geofence)
+	if puterr := stub.PutState(geofenceID, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	participantJsonBytes, _ := json.Marshal(participant)
+	if puterr := stub.PutState(common.Key(participant.ParticipantID), participantJsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	return common.Success(http.StatusCreated, "Geofence Created", nil)
+}
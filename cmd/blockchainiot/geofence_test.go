@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+func TestGeofencesRecordShipmentMilestones(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+	for _, element := range []Tier{grower, importer} {
+		f.participant(element.ParticipantID, element.ParticipantType)
+		f.material(element.ParticipantID, element.MaterialID, "PRODUCT01")
+	}
+	f.participant(distributor.ParticipantID, distributor.ParticipantType)
+	f.produce("PRO1", grower.ParticipantID, grower.MaterialID, grower.BatchNumber, 40)
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{"POID": "PO1", "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID, "VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": grower.BatchNumber, "Quantity": 10, "UnitOfMeasure": "KG", "NetPrice": 10, "Currency": "USD"})
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createShipment", map[string]interface{}{"ShipmentID": "SH1", "ProductBCID": "PRODUCT01", "POID": "PO1", "UnsignedReadings": true})
+
+	plant := common.GeoArea{Shape: "CIRCLE", Center: &common.GeoPoint{Latitude: 51.5, Longitude: -0.12}, RadiusMeters: 1000}
+	storage := common.GeoArea{Shape: "POLYGON", Vertices: []common.GeoPoint{{Latitude: 52, Longitude: 0}, {Latitude: 52, Longitude: 0.1}, {Latitude: 52.1, Longitude: 0.1}, {Latitude: 52.1, Longitude: 0}}}
+	f.mustInvoke(http.StatusBadRequest, "createGeofence", map[string]interface{}{"GeofenceID": "GF1", "LocationType": "OFFICE", "Area": plant})
+	f.mustInvoke(http.StatusBadRequest, "createGeofence", map[string]interface{}{"GeofenceID": "GF1", "LocationType": "PLANT", "Area": common.GeoArea{Shape: "CIRCLE"}})
+	f.mustInvoke(http.StatusForbidden, "createGeofence", map[string]interface{}{"GeofenceID": "GF1", "ParticipantID": importer.ParticipantID, "LocationType": "PLANT", "Area": plant})
+	f.mustInvoke(http.StatusCreated, "createGeofence", map[string]interface{}{"GeofenceID": "GF1", "LocationType": "plant", "Area": plant})
+	f.mustInvoke(http.StatusConflict, "createGeofence", map[string]interface{}{"GeofenceID": "GF1", "LocationType": "PLANT", "Area": plant})
+	f.asAdmin().mustInvoke(http.StatusCreated, "createGeofence", map[string]interface{}{"GeofenceID": "GF2", "ParticipantID": importer.ParticipantID, "LocationType": "STORAGE", "Area": storage})
+	f.as(distributor.ParticipantID).mustInvoke(http.StatusCreated, "createGeofence", map[string]interface{}{"GeofenceID": "GF3", "LocationType": "STORAGE", "Area": plant})
+
+	// A Participant ID never shares the key of a Geofence
+	f.participant("GEOFENCE-GF4", distributor.ParticipantType)
+	f.as("GEOFENCE-GF4").mustInvoke(http.StatusCreated, "createGeofence", map[string]interface{}{"GeofenceID": "GF4", "LocationType": "STORAGE", "Area": storage})
+	participant := Participant{}
+	if f.getState("GEOFENCE-GF4", &participant); participant.ParticipantType != distributor.ParticipantType || len(participant.Geofences) != 1 {
+		t.Fatalf("unexpected participant %+v", participant)
+	}
+
+	track := func(latitude float64, longitude float64, timestamp string) {
+		f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "trackShipment", map[string]interface{}{"ShipmentID": "SH1", "Latitude": latitude, "Longitude": longitude, "Timestamp": timestamp})
+	}
+
+	// The plant of the vendor is the origin, the Geofence of another Participant is ignored
+	track(51.501, -0.12, "2020-01-01T10:00:00Z")
+	if event := f.lastEvent(eventGeofenceCrossed); event.PreviousStatus != "SHIPPING" || event.Status != shipmentAtOrigin || len(event.Keys) != 1 || event.Keys[0] != "sh1" || event.Participants[0] != grower.ParticipantID {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	track(51.502, -0.12, "2020-01-01T10:01:00Z")
+	f.lastEvent(eventShipmentTracked)
+	track(51.6, -0.12, "2020-01-01T10:30:00Z")
+	if event := f.lastEvent(eventGeofenceCrossed); event.Status != shipmentInTransit || event.Participants[0] != grower.ParticipantID {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	// Readings uploaded in bulk cross Geofences in device time order
+	f.mustInvoke(http.StatusCreated, "ingestReadings", map[string]interface{}{"Readings": []IngestReading{
+		{ShipmentID: "SH1", Type: "GPS", Timestamp: "2020-01-01T12:05:00Z", Latitude: 52.06, Longitude: 0.05},
+		{ShipmentID: "SH1", Type: "GPS", Timestamp: "2020-01-01T12:00:00Z", Latitude: 52.05, Longitude: 0.05},
+	}})
+	if event := f.lastEvent(eventGeofenceCrossed); len(event.Participants) != 1 || event.Participants[0] != importer.ParticipantID {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	shipment := f.getShipment("SH1")
+	if shipment.Status != shipmentArrived || len(shipment.Geofences) != 1 || shipment.Geofences[0] != "GF2" {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+	expected := []Milestone{
+		{Type: milestoneArrival, GeofenceID: "GF1", ParticipantID: grower.ParticipantID, LocationType: "PLANT", Timestamp: "2020-01-01T10:00:00Z"},
+		{Type: milestoneDeparture, GeofenceID: "GF1", ParticipantID: grower.ParticipantID, LocationType: "PLANT", Timestamp: "2020-01-01T10:30:00Z"},
+		{Type: milestoneArrival, GeofenceID: "GF2", ParticipantID: importer.ParticipantID, LocationType: "STORAGE", Timestamp: "2020-01-01T12:00:00Z"},
+	}
+	if len(shipment.Milestones) != len(expected) {
+		t.Fatalf("unexpected milestones %+v", shipment.Milestones)
+	}
+	for index, element := range expected {
+		if shipment.Milestones[index] != element {
+			t.Fatalf("milestone %d: expected %+v, got %+v", index, element, shipment.Milestones[index])
+		}
+	}
+}
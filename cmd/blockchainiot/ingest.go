@@ -0,0 +1,346 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Reading Ingestion
+//****Description: GPS and sensor readings of gateways written in bulk, one transaction per upload
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//Most readings accepted in one ingestReadings transaction
+const maxIngestReadings = 1000
+
+//Types of ingested readings
+const (
+	readingGPS    = "GPS"
+	readingSensor = "SENSOR"
+)
+
+//********************************************************************************************************
+//Struct for Reading Ingestion
+//********************************************************************************************************
+
+//Reading uploaded by a gateway, Type selects the GPS or the sensor fields
+type IngestReading struct {
+	ShipmentID         string   `json:"ShipmentID"`
+	Type               string   `json:"Type"`      // GPS or SENSOR
+	Timestamp          string   `json:"Timestamp"` // RFC3339 time of the device, required
+	Latitude           float64  `json:"Latitude,omitempty"`
+	Longitude          float64  `json:"Longitude,omitempty"`
+	Accuracy           float32  `json:"Accuracy,omitempty"`
+	TemperatureCelsius *float64 `json:"TemperatureCelsius,omitempty"`
+	Humidity           float64  `json:"Humidity,omitempty"`
+	Shock              float64  `json:"Shock,omitempty"`
+	common.DeviceSignature
+}
+
+//Outcome of one uploaded reading, Index is its position in the upload
+type IngestResult struct {
+	Index      int    `json:"Index"`
+	ShipmentID string `json:"ShipmentID"`
+	Type       string `json:"Type"`
+	Timestamp  string `json:"Timestamp"`
+	Reason     string `json:"Reason,omitempty"` // Why the reading was rejected
+}
+
+//Response of ingestReadings
+type IngestReport struct {
+	Accepted   []IngestResult     `json:"Accepted"`
+	Rejected   []IngestResult     `json:"Rejected"`
+	Excursions []common.Excursion `json:"Excursions"`
+}
+
+//readingTime normalizes a device time so that duplicates compare equal, an unparsable time is kept as given
+func readingTime(value string) string {
+	if at, err := common.ParseTime(value); err == nil {
+		return at.UTC().Format(time.RFC3339Nano)
+	}
+	return value
+}
+
+//readingBefore orders device times, times that do not parse come first
+func readingBefore(left string, right string) bool {
+	leftTime, leftErr := common.ParseTime(left)
+	rightTime, rightErr := common.ParseTime(right)
+	if leftErr != nil || rightErr != nil {
+		return leftErr != nil && rightErr == nil
+	}
+	return leftTime.Before(rightTime)
+}
+
+//readingID identifies a reading of a shipment for de-duplication
+func readingID(shipmentID string, readingType string, timestamp string) string {
+	return common.Key(shipmentID, readingType, readingTime(timestamp))
+}
+
+//validateGPS checks the coordinates of a GPS reading
+func validateGPS(reading IngestReading) error {
+	if reading.Latitude < -90 || reading.Latitude > 90 || reading.Longitude < -180 || reading.Longitude > 180 {
+		return fmt.Errorf("Latitude must be between -90 and 90 and Longitude between -180 and 180")
+	}
+	if reading.Accuracy < 0 {
+		return fmt.Errorf("Accuracy must not be negative")
+	}
+	return nil
+}
+
+//ingestedShipment is a Shipment loaded once for the whole upload, with the readings seen so far
+type ingestedShipment struct {
+	shipment Shipment
+	limits   common.TemperatureLimits
+	seen     map[string]bool
+	gps      []GetGPSReading
+	sensors  []common.SensorReading
+}
+
+//loadIngestedShipment gets a Shipment, the limits of its Product and its recorded readings
+func loadIngestedShipment(stub shim.ChaincodeStubInterface, shipmentID string) (*ingestedShipment, error) {
+	shipmentValue, geterr := stub.GetState(common.Key(shipmentID))
+	if geterr != nil {
+		return nil, geterr
+	}
+	if shipmentValue == nil {
+		return nil, nil
+	}
+	loaded := ingestedShipment{seen: map[string]bool{}}
+	json.Unmarshal(shipmentValue, &loaded.shipment)
+
+	productValue, geterr := stub.GetState(common.Key(loaded.shipment.ProductBCID))
+	if geterr != nil {
+		return nil, geterr
+	}
+	product := Product{}
+	json.Unmarshal(productValue, &product)
+	loaded.limits = product.TemperatureLimits
+
+	for _, element := range loaded.shipment.GPSReading {
+		if element.Timestamp != "" {
+			loaded.seen[readingID(shipmentID, readingGPS, element.Timestamp)] = true
+		}
+	}
+	for _, element := range loaded.shipment.SensorReadings {
+		loaded.seen[readingID(shipmentID, readingSensor, element.Timestamp)] = true
+	}
+	return &loaded, nil
+}
+
+//********************************************************************************************************
+// Reading Ingestion Functions
+//********************************************************************************************************
+
+// Ingest Readings - Arguments: {"Readings": [{"ShipmentID", "Type", "Timestamp", ...}]}
+// Valid readings are written in one transaction, each Shipment once and in device time order.
+// Readings of a Shipment already recorded for the same Type and Timestamp are rejected as duplicates.
+// Readings of a Shipment with attached Devices must be signed by one of them, see deviceReadings.
+func (t *BlockchainIOT) ingestReadings(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		Readings []IngestReading `json:"Readings"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil || len(queryData.Readings) == 0 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+	if len(queryData.Readings) > maxIngestReadings {
+		return common.Error(http.StatusBadRequest, fmt.Sprintf("Invoke Error: At most %d Readings per Transaction", maxIngestReadings))
+	}
+
+	report := IngestReport{Accepted: []IngestResult{}, Rejected: []IngestResult{}, Excursions: []common.Excursion{}}
+	shipments := map[string]*ingestedShipment{}
+	shipmentIDs := []string{}
+	devices := newDeviceReadings()
+	for index, element := range queryData.Readings {
+		element.Type = strings.ToUpper(element.Type)
+		result := IngestResult{Index: index, ShipmentID: element.ShipmentID, Type: element.Type, Timestamp: element.Timestamp}
+		reject := func(reason string) {
+			result.Reason = reason
+			report.Rejected = append(report.Rejected, result)
+		}
+
+		// Get Shipment
+		shipmentID := common.Key(element.ShipmentID)
+		loaded, exists := shipments[shipmentID]
+		if !exists {
+			loaded, err = loadIngestedShipment(stub, element.ShipmentID)
+			if err != nil {
+				return common.Error(http.StatusInternalServerError, err.Error())
+			}
+			shipments[shipmentID] = loaded
+		}
+		if loaded == nil {
+			reject("Shipment Does Not Exist")
+			continue
+		}
+		if loaded.shipment.Status == "COMPLETED" {
+			reject("Shipment is already Completed")
+			continue
+		}
+
+		// Validate the Reading
+		if element.Timestamp == "" {
+			reject("Timestamp is required")
+			continue
+		}
+		at, timeErr := common.ParseTime(element.Timestamp)
+		if timeErr != nil {
+			reject(timeErr.Error())
+			continue
+		}
+		result.Timestamp = at.UTC().Format(time.RFC3339Nano)
+		id := readingID(element.ShipmentID, element.Type, result.Timestamp)
+
+		switch element.Type {
+		case readingGPS:
+			if gpsErr := validateGPS(element); gpsErr != nil {
+				reject(gpsErr.Error())
+				continue
+			}
+			if loaded.seen[id] {
+				reject("Duplicate Reading")
+				continue
+			}
+			if segments := loaded.shipment.GPSSegments; len(segments) > 0 && !readingBefore(segments[len(segments)-1].To, result.Timestamp) {
+				reject("Reading is not newer than the GPS Segments of the Shipment")
+				continue
+			}
+			if verifyErr := devices.verify(stub, loaded.shipment, element.DeviceSignature, gpsFields(GetGPSReading{ShipmentID: element.ShipmentID, Latitude: element.Latitude, Longitude: element.Longitude, Accuracy: element.Accuracy, Timestamp: element.Timestamp})...); verifyErr != nil {
+				reject(verifyErr.Error())
+				continue
+			}
+			loaded.gps = append(loaded.gps, GetGPSReading{ShipmentID: loaded.shipment.ShipmentID, Latitude: element.Latitude, Longitude: element.Longitude, Accuracy: element.Accuracy, Timestamp: result.Timestamp})
+		case readingSensor:
+			if element.TemperatureCelsius == nil {
+				reject("TemperatureCelsius is required")
+				continue
+			}
+			reading, readingErr := common.SensorReading{TemperatureCelsius: *element.TemperatureCelsius, Humidity: element.Humidity, Shock: element.Shock, Timestamp: result.Timestamp}.Normalize(stub)
+			if readingErr != nil {
+				reject(readingErr.Error())
+				continue
+			}
+			if loaded.seen[id] {
+				reject("Duplicate Reading")
+				continue
+			}
+			sent := common.SensorReading{TemperatureCelsius: *element.TemperatureCelsius, Humidity: element.Humidity, Shock: element.Shock, Timestamp: element.Timestamp}
+			if verifyErr := devices.verify(stub, loaded.shipment, element.DeviceSignature, sent.SignedFields(element.ShipmentID)...); verifyErr != nil {
+				reject(verifyErr.Error())
+				continue
+			}
+			loaded.sensors = append(loaded.sensors, reading)
+		default:
+			reject("Type must be GPS or SENSOR")
+			continue
+		}
+
+		loaded.seen[id] = true
+		if !containsFold(shipmentIDs, shipmentID) {
+			shipmentIDs = append(shipmentIDs, shipmentID)
+		}
+		report.Accepted = append(report.Accepted, result)
+	}
+
+	jsonBytes, _ := json.Marshal(report)
+	if len(report.Accepted) == 0 {
+		return common.Success(http.StatusOK, "No Reading Accepted", jsonBytes)
+	}
+
+	// Store the Sequence of the Devices that signed accepted readings
+	if puterr := devices.store(stub); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Update every Shipment once, checking its sensor readings against the limits of its Product
+	retention, retentionErr := getGPSRetention(stub)
+	if retentionErr != nil {
+		return common.Error(http.StatusInternalServerError, retentionErr.Error())
+	}
+	event := ChaincodeEvent{EventType: eventReadingsIngested}
+	milestones := []Milestone{}
+	purchaseOrders := []PurchaseOrder{}
+	for _, shipmentID := range shipmentIDs {
+		loaded := shipments[shipmentID]
+		shipment := loaded.shipment
+		sort.SliceStable(loaded.gps, func(i, j int) bool { return readingBefore(loaded.gps[i].Timestamp, loaded.gps[j].Timestamp) })
+		sort.SliceStable(loaded.sensors, func(i, j int) bool { return readingBefore(loaded.sensors[i].Timestamp, loaded.sensors[j].Timestamp) })
+		if len(loaded.gps) > 0 {
+			route, routeErr := loadShipmentRoute(stub, shipment)
+			if routeErr != nil {
+				return common.Error(http.StatusInternalServerError, routeErr.Error())
+			}
+			for _, element := range loaded.gps {
+				milestones = append(milestones, shipment.crossGeofences(route, element)...)
+			}
+		}
+		shipment.GPSReading = append(shipment.GPSReading, loaded.gps...)
+		shipment.retainGPS(retention)
+		shipment.SensorReadings = append(shipment.SensorReadings, loaded.sensors...)
+
+		excursions := 0
+		for _, element := range loaded.sensors {
+			if excursion := loaded.limits.Check(element); excursion != nil {
+				shipment.Excursions = append(shipment.Excursions, *excursion)
+				report.Excursions = append(report.Excursions, *excursion)
+				excursions++
+			}
+		}
+		if excursions > 0 {
+			purchaseOrderValue, _ := stub.GetState(common.Key(shipment.POID))
+			purchaseOrder := PurchaseOrder{}
+			json.Unmarshal(purchaseOrderValue, &purchaseOrder)
+			purchaseOrders = append(purchaseOrders, purchaseOrder)
+			for _, participantID := range []string{purchaseOrder.VendorID, purchaseOrder.RequestorID} {
+				if !containsFold(event.Participants, participantID) {
+					event.Participants = append(event.Participants, participantID)
+				}
+			}
+		}
+
+		// Store Updated Shipment in Blockchain
+		shipmentJsonBytes, _ := json.Marshal(shipment)
+		if puterr := stub.PutState(shipmentID, shipmentJsonBytes); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+	}
+
+	// Report the Geofences crossed, unless an excursion is reported
+	if len(milestones) > 0 {
+		event.EventType = eventGeofenceCrossed
+		milestoneEvent(&event, milestones)
+	}
+
+	// Flag the shipped Batches of the Shipments with an excursion
+	if len(purchaseOrders) > 0 {
+		if markerr := markShippedBatches(stub, purchaseOrders); markerr != nil {
+			return common.Error(http.StatusInternalServerError, markerr.Error())
+		}
+		event.EventType = eventTemperatureExcursion
+	}
+
+	// Emit Event
+	if eventerr := emitEvent(stub, event); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	jsonBytes, _ = json.Marshal(report)
+	return common.Success(http.StatusCreated, "Readings Ingested", jsonBytes)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestIngestReadingsDeduplicatesAndReports(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+	for _, element := range []Tier{grower, importer} {
+		f.participant(element.ParticipantID, element.ParticipantType)
+		f.material(element.ParticipantID, element.MaterialID, "PRODUCT01")
+	}
+	f.produce("PRO1", grower.ParticipantID, grower.MaterialID, grower.BatchNumber, 40)
+	f.as(grower.ParticipantID).mustInvoke(http.StatusOK, "setTemperatureLimits", map[string]interface{}{"ProductID": "PRODUCT01", "MaxTemperature": 8})
+	for _, orderID := range []string{"PO1", "PO2"} {
+		f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{"POID": orderID, "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID, "VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": grower.BatchNumber, "Quantity": 10, "UnitOfMeasure": "KG", "NetPrice": 10, "Currency": "USD"})
+		f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createShipment", map[string]interface{}{"ShipmentID": "SH-" + orderID, "ProductBCID": "PRODUCT01", "POID": orderID, "UnsignedReadings": true})
+	}
+	f.mustInvoke(http.StatusCreated, "trackShipment", map[string]interface{}{"ShipmentID": "SH-PO1", "Latitude": 51.5, "Longitude": -0.12, "Timestamp": "2020-01-01T10:00:00Z"})
+
+	f.mustInvoke(http.StatusBadRequest, "ingestReadings", map[string]interface{}{"Readings": []IngestReading{}})
+	f.mustInvoke(http.StatusBadRequest, "ingestReadings", map[string]interface{}{"Readings": make([]IngestReading, maxIngestReadings+1)})
+
+	celsius := func(value float64) *float64 { return &value }
+	readings := []IngestReading{
+		{ShipmentID: "SH-PO1", Type: "gps", Timestamp: "2020-01-01T10:01:00Z", Latitude: 51.6, Longitude: -0.1},
+		{ShipmentID: "SH-PO1", Type: "GPS", Timestamp: "2020-01-01T11:00:00+01:00", Latitude: 51.5, Longitude: -0.12}, // recorded by trackShipment
+		{ShipmentID: "SH-PO1", Type: "GPS", Timestamp: "2020-01-01T10:00:30Z", Latitude: 51.55, Longitude: -0.11},
+		{ShipmentID: "SH-PO1", Type: "GPS", Timestamp: "2020-01-01T10:01:00Z", Latitude: 51.6, Longitude: -0.1}, // repeated in the upload
+		{ShipmentID: "SH-PO1", Type: "GPS", Timestamp: "2020-01-01T10:02:00Z", Latitude: 95},
+		{ShipmentID: "SH-PO1", Type: "SENSOR", Timestamp: "2020-01-01T10:01:00Z", TemperatureCelsius: celsius(4)},
+		{ShipmentID: "SH-PO1", Type: "SENSOR", Timestamp: "2020-01-01T10:02:00Z"},
+		{ShipmentID: "SH-PO2", Type: "SENSOR", Timestamp: "2020-01-01T10:01:00Z", TemperatureCelsius: celsius(12)},
+		{ShipmentID: "SH-PO2", Type: "SENSOR", Timestamp: "now", TemperatureCelsius: celsius(4)},
+		{ShipmentID: "SH-PO2", Type: "SENSOR", TemperatureCelsius: celsius(4)},
+		{ShipmentID: "SH-PO2", Type: "HUMIDITY", Timestamp: "2020-01-01T10:03:00Z"},
+		{ShipmentID: "UNKNOWN", Type: "GPS", Timestamp: "2020-01-01T10:01:00Z"},
+	}
+	report := IngestReport{}
+	json.Unmarshal(f.mustInvoke(http.StatusCreated, "ingestReadings", map[string]interface{}{"Readings": readings}).Payload, &report)
+	if len(report.Accepted) != 4 || len(report.Rejected) != 8 || len(report.Excursions) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	for index, rejected := range map[int]string{1: "Duplicate", 3: "Duplicate", 4: "Latitude", 6: "TemperatureCelsius", 8: "RFC3339", 9: "Timestamp", 10: "Type", 11: "Shipment"} {
+		found := false
+		for _, element := range report.Rejected {
+			found = found || (element.Index == index && strings.Contains(element.Reason, rejected))
+		}
+		if !found {
+			t.Fatalf("expected reading %d rejected for %s, got %+v", index, rejected, report.Rejected)
+		}
+	}
+	if event := f.lastEvent(eventTemperatureExcursion); flatKeys(event) != "sh-po1 sh-po2 grower01-mat01" || len(event.Keys) != 3 || len(event.Participants) != 2 {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	// Each Shipment is written once, the new readings in device time order
+	shipment := f.getShipment("SH-PO1")
+	if len(shipment.GPSReading) != 3 || shipment.GPSReading[1].Timestamp != "2020-01-01T10:00:30Z" || len(shipment.SensorReadings) != 1 || len(shipment.Excursions) != 0 {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+	if shipment = f.getShipment("SH-PO2"); len(shipment.SensorReadings) != 1 || len(shipment.Excursions) != 1 {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, grower.BatchNumber); !batch.PotentialCompromised {
+		t.Fatalf("expected the shipped batch flagged %+v", batch)
+	}
+
+	// An upload of known readings writes nothing
+	res := f.mustInvoke(http.StatusOK, "ingestReadings", map[string]interface{}{"Readings": readings[:1]})
+	if json.Unmarshal(res.Payload, &report); len(report.Accepted) != 0 || len(report.Rejected) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
@@ -0,0 +1,392 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Batch Lineage
+//****Description: Trade edges between batches stored under their own composite keys
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//Composite key object types of the lineage.
+//edge~from~to holds the edge, the reverse and product indexes only hold its key.
+const (
+	edgeObjectType        = "edge"
+	edgeReverseObjectType = "edge~rev"
+	edgeProductObjectType = "edge~product"
+)
+
+//********************************************************************************************************
+//Struct for Lineage
+//********************************************************************************************************
+
+//Edge from a vendor batch to a requestor batch, or from a component batch to a produced batch,
+//accumulated over every order between them
+type LineageEdge struct {
+	Asset_Type       string         `json:"Asset_Type,omitempty"`
+	ProductBCID      string         `json:"ProductBCID"`
+	From             BatchTradeInfo `json:"From"`
+	To               BatchTradeInfo `json:"To"`
+	Quantity         int            `json:"Quantity"`
+	PurchaseOrders   []string       `json:"PurchaseOrders,omitempty"`
+	ProductionOrders []string       `json:"ProductionOrders,omitempty"`
+	GoodsReceipts    []string       `json:"GoodsReceipts,omitempty"`
+}
+
+//Order and Goods Receipt that moved Quantity along an edge, empty for a split or merge of batches
+type LineageOrder struct {
+	Against  string // PURCHASE ORDER or PRODUCTION ORDER
+	POID     string
+	GRNumber string
+}
+
+//along returns a batch of the edge carrying the Quantity, orders and receipts of the edge
+func (edge LineageEdge) along(batch BatchTradeInfo) BatchTradeInfo {
+	batch.Quantity = edge.Quantity
+	batch.PurchaseOrders = edge.PurchaseOrders
+	batch.ProductionOrders = edge.ProductionOrders
+	batch.GoodsReceipts = edge.GoodsReceipts
+	return batch
+}
+
+//Attributes of a batch inside a composite key
+func batchAttributes(participant string, material string, batch string) []string {
+	return []string{strings.ToLower(participant), strings.ToLower(material), strings.ToLower(batch)}
+}
+
+func edgeKey(stub shim.ChaincodeStubInterface, from BatchTradeInfo, to BatchTradeInfo) (string, error) {
+	attributes := append(batchAttributes(from.ParticipantID, from.MaterialID, from.BatchNumber), batchAttributes(to.ParticipantID, to.MaterialID, to.BatchNumber)...)
+	return stub.CreateCompositeKey(edgeObjectType, attributes)
+}
+
+//putLineageEdge adds the quantity of an order to the edge between two batches,
+//creating the edge and its indexes on the first order. The edge belongs to the first Product
+//and is listed under every Product given.
+func putLineageEdge(stub shim.ChaincodeStubInterface, productIDs []string, from BatchTradeInfo, to BatchTradeInfo, quantity int, order LineageOrder) error {
+	added := LineageEdge{From: from, To: to, Quantity: quantity}
+	if order.POID != "" {
+		if strings.ToUpper(order.Against) == "PRODUCTION ORDER" {
+			added.ProductionOrders = []string{order.POID}
+		} else {
+			added.PurchaseOrders = []string{order.POID}
+		}
+	}
+	if order.GRNumber != "" {
+		added.GoodsReceipts = []string{order.GRNumber}
+	}
+	return addLineageEdge(stub, productIDs, added)
+}
+
+//addLineageEdge adds the Quantity, orders and GoodsReceipts of an edge to the stored edge between the same batches
+func addLineageEdge(stub shim.ChaincodeStubInterface, productIDs []string, added LineageEdge) error {
+	from, to := added.From, added.To
+	key, err := edgeKey(stub, from, to)
+	if err != nil {
+		return err
+	}
+	value, err := stub.GetState(key)
+	if err != nil {
+		return err
+	}
+
+	edge := LineageEdge{}
+	if value != nil {
+		json.Unmarshal(value, &edge)
+	} else {
+		edge.Asset_Type = "LINEAGE EDGE"
+		edge.ProductBCID = productIDs[0]
+		edge.From = BatchTradeInfo{ParticipantID: from.ParticipantID, MaterialID: from.MaterialID, BatchNumber: from.BatchNumber, SerialNumbers: from.SerialNumbers}
+		edge.To = BatchTradeInfo{ParticipantID: to.ParticipantID, MaterialID: to.MaterialID, BatchNumber: to.BatchNumber, SerialNumbers: to.SerialNumbers}
+
+		// Reverse and Product indexes
+		reverseKey, err := stub.CreateCompositeKey(edgeReverseObjectType, append(batchAttributes(to.ParticipantID, to.MaterialID, to.BatchNumber), batchAttributes(from.ParticipantID, from.MaterialID, from.BatchNumber)...))
+		if err != nil {
+			return err
+		}
+		if err := stub.PutState(reverseKey, []byte{0x00}); err != nil {
+			return err
+		}
+		for _, productID := range productIDs {
+			productKey, err := stub.CreateCompositeKey(edgeProductObjectType, append([]string{strings.ToLower(productID)}, append(batchAttributes(from.ParticipantID, from.MaterialID, from.BatchNumber), batchAttributes(to.ParticipantID, to.MaterialID, to.BatchNumber)...)...))
+			if err != nil {
+				return err
+			}
+			if err := stub.PutState(productKey, []byte{0x00}); err != nil {
+				return err
+			}
+		}
+	}
+	edge.Quantity += added.Quantity
+	edge.PurchaseOrders = append(edge.PurchaseOrders, added.PurchaseOrders...)
+	edge.ProductionOrders = append(edge.ProductionOrders, added.ProductionOrders...)
+	edge.GoodsReceipts = append(edge.GoodsReceipts, added.GoodsReceipts...)
+
+	jsonBytes, _ := json.Marshal(edge)
+	return stub.PutState(key, jsonBytes)
+}
+
+//getEdgesByIndex resolves the edges listed under an index, keyOf maps the index attributes to the edge attributes
+func getEdgesByIndex(stub shim.ChaincodeStubInterface, objectType string, attributes []string, keyOf func([]string) []string) ([]LineageEdge, error) {
+	resultsIterator, err := stub.GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	edges := []LineageEdge{}
+	for resultsIterator.HasNext() {
+		output, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		value := output.Value
+		if keyOf != nil {
+			_, indexAttributes, err := stub.SplitCompositeKey(output.Key)
+			if err != nil {
+				return nil, err
+			}
+			key, err := stub.CreateCompositeKey(edgeObjectType, keyOf(indexAttributes))
+			if err != nil {
+				return nil, err
+			}
+			if value, err = stub.GetState(key); err != nil {
+				return nil, err
+			}
+		}
+		edge := LineageEdge{}
+		json.Unmarshal(value, &edge)
+		edges = append(edges, edge)
+	}
+	return edges, nil
+}
+
+//getLineageEdges returns the edges leaving a batch (forward) or reaching it (backward)
+func getLineageEdges(stub shim.ChaincodeStubInterface, participant string, material string, batch string, forward bool) ([]LineageEdge, error) {
+	attributes := batchAttributes(participant, material, batch)
+	if forward {
+		return getEdgesByIndex(stub, edgeObjectType, attributes, nil)
+	}
+	return getEdgesByIndex(stub, edgeReverseObjectType, attributes, func(indexAttributes []string) []string {
+		return append(append([]string{}, indexAttributes[3:]...), indexAttributes[:3]...)
+	})
+}
+
+//getProductEdges returns every edge recorded for a Product
+func getProductEdges(stub shim.ChaincodeStubInterface, productID string) ([]LineageEdge, error) {
+	return getEdgesByIndex(stub, edgeProductObjectType, []string{strings.ToLower(productID)}, func(indexAttributes []string) []string {
+		return indexAttributes[1:]
+	})
+}
+
+//neighbours returns the batches one edge away from a batch, each carrying the Quantity, orders
+//and GoodsReceipts of the edge that reaches it
+func neighbours(stub shim.ChaincodeStubInterface, participant string, material string, batch string, forward bool) ([]BatchTradeInfo, error) {
+	edges, err := getLineageEdges(stub, participant, material, batch, forward)
+	if err != nil {
+		return nil, err
+	}
+	result := []BatchTradeInfo{}
+	for _, element := range edges {
+		next := element.To
+		if !forward {
+			next = element.From
+		}
+		result = append(result, element.along(next))
+	}
+	return result, nil
+}
+
+//productView derives the lineage and contamination fields of a Product from its edges and the Material batches
+func productView(stub shim.ChaincodeStubInterface, product *Product) error {
+	edges, err := getProductEdges(stub, product.ProductID)
+	if err != nil {
+		return err
+	}
+
+	materials := []Material{}
+	for _, element := range lineageMaterials(product, edges) {
+		materialValue, err := stub.GetState(element)
+		if err != nil {
+			return err
+		}
+		material := Material{}
+		json.Unmarshal(materialValue, &material)
+		materials = append(materials, material)
+	}
+	deriveProductView(product, materials, edges)
+	return nil
+}
+
+//lineageMaterials returns the keys of the Materials of a Product and of the batches on its edges,
+//components consumed by a Production Order may belong to another Product
+func lineageMaterials(product *Product, edges []LineageEdge) []string {
+	keys := []string{}
+	add := func(key string) {
+		if !containsFold(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	for _, element := range product.AllMaterials {
+		add(common.Key(element))
+	}
+	for _, element := range edges {
+		add(common.Key(element.From.ParticipantID, element.From.MaterialID))
+		add(common.Key(element.To.ParticipantID, element.To.MaterialID))
+	}
+	return keys
+}
+
+//deriveProductView fills the lineage and contamination fields of a Product from the given Materials and edges
+func deriveProductView(product *Product, materials []Material, edges []LineageEdge) {
+	// Batch status from the Materials of the Product
+	batches := map[string]BatchInfo{}
+	for _, material := range materials {
+		for _, element1 := range material.Batches {
+			batches[batchKey(material.ParticipantID, material.MaterialMasterID, element1.BatchNumber)] = element1
+		}
+	}
+	status := func(batch BatchTradeInfo) BatchTradeInfo {
+		info := batches[batchKey(batch.ParticipantID, batch.MaterialID, batch.BatchNumber)]
+		batch.IsCompromised = info.IsCompromised
+		batch.PotentialCompromised = info.PotentialCompromised
+		return batch
+	}
+
+	// Mappings and Reverse Mappings
+	product.Mappings = nil
+	product.ReverseMappings = nil
+	mappingIndex := map[string]int{}
+	reverseIndex := map[string]int{}
+	for _, element := range edges {
+		to := element.along(status(element.To))
+		from := element.along(status(element.From))
+
+		fromKey := batchKey(from.ParticipantID, from.MaterialID, from.BatchNumber)
+		if index, exists := mappingIndex[fromKey]; exists {
+			product.Mappings[index].From.Quantity += element.Quantity
+			product.Mappings[index].To = append(product.Mappings[index].To, to)
+		} else {
+			mappingIndex[fromKey] = len(product.Mappings)
+			product.Mappings = append(product.Mappings, Mapping{From: status(BatchTradeInfo{ParticipantID: from.ParticipantID, MaterialID: from.MaterialID, BatchNumber: from.BatchNumber, SerialNumbers: from.SerialNumbers, Quantity: element.Quantity}), To: []BatchTradeInfo{to}})
+		}
+
+		toKey := batchKey(to.ParticipantID, to.MaterialID, to.BatchNumber)
+		if index, exists := reverseIndex[toKey]; exists {
+			product.ReverseMappings[index].To.Quantity += element.Quantity
+			product.ReverseMappings[index].From = append(product.ReverseMappings[index].From, from)
+		} else {
+			reverseIndex[toKey] = len(product.ReverseMappings)
+			product.ReverseMappings = append(product.ReverseMappings, ReverseMapping{To: status(BatchTradeInfo{ParticipantID: to.ParticipantID, MaterialID: to.MaterialID, BatchNumber: to.BatchNumber, SerialNumbers: to.SerialNumbers, Quantity: element.Quantity}), From: []BatchTradeInfo{from}})
+		}
+	}
+
+	// Supply Chain Members are Compromised when any of their batches is
+	for index, element := range product.SupplyChainMembers {
+		element.IsCompromised = false
+		element.PotentialCompromised = false
+		for _, element1 := range batches {
+			if strings.ToLower(element1.ParticipantID) == strings.ToLower(element.ParticipantID) {
+				element.IsCompromised = element.IsCompromised || element1.IsCompromised
+				element.PotentialCompromised = element.PotentialCompromised || element1.PotentialCompromised
+			}
+		}
+		if element.IsCompromised {
+			element.PotentialCompromised = false
+		}
+		product.SupplyChainMembers[index] = element
+	}
+}
+
+//legacyLineageEdges turns the Mappings stored inside a Product before the lineage edges into edges,
+//one per pair of batches. The Reverse Mappings mirror the Mappings and add no edge.
+func legacyLineageEdges(product Product) []LineageEdge {
+	edges := []LineageEdge{}
+	edgeIndex := map[string]int{}
+	for _, element := range product.Mappings {
+		for _, to := range element.To {
+			key := batchKey(element.From.ParticipantID, element.From.MaterialID, element.From.BatchNumber) + common.KeySeparator + batchKey(to.ParticipantID, to.MaterialID, to.BatchNumber)
+			index, exists := edgeIndex[key]
+			if !exists {
+				index = len(edges)
+				edgeIndex[key] = index
+				edges = append(edges, LineageEdge{
+					From: BatchTradeInfo{ParticipantID: element.From.ParticipantID, MaterialID: element.From.MaterialID, BatchNumber: element.From.BatchNumber, SerialNumbers: element.From.SerialNumbers},
+					To:   BatchTradeInfo{ParticipantID: to.ParticipantID, MaterialID: to.MaterialID, BatchNumber: to.BatchNumber, SerialNumbers: to.SerialNumbers},
+				})
+			}
+			edges[index].Quantity += to.Quantity
+			edges[index].PurchaseOrders = append(edges[index].PurchaseOrders, to.PurchaseOrders...)
+			edges[index].ProductionOrders = append(edges[index].ProductionOrders, to.ProductionOrders...)
+			edges[index].GoodsReceipts = append(edges[index].GoodsReceipts, to.GoodsReceipts...)
+		}
+	}
+	return edges
+}
+
+//********************************************************************************************************
+// Lineage Functions
+//********************************************************************************************************
+
+// Migrate the Mappings stored inside a Product to lineage edges (Admin only) - Arguments: {"ProductID"}
+// Trades recorded before the lineage edges are only visible to trace and contamination once migrated
+func (t *BlockchainIOT) migrateLineage(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ProductID string `json:"ProductID"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	// Get Product
+	productValue, productGetErr := stub.GetState(common.Key(queryData.ProductID))
+	if productGetErr != nil || productValue == nil {
+		return common.Error(http.StatusNotFound, "Product Does Not Exist! Please Check Product ID!")
+	}
+	product := Product{}
+	json.Unmarshal(productValue, &product)
+	if product.Asset_Type != "PRODUCT" {
+		return common.Error(http.StatusNotFound, "Product Does Not Exist! Please Check Product ID!")
+	}
+
+	// Add every legacy Mapping to the edge between its batches, edges recorded since the upgrade are kept
+	edges := legacyLineageEdges(product)
+	for _, element := range edges {
+		if puterr := addLineageEdge(stub, []string{product.ProductID}, element); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+	}
+
+	// The Product keeps no Mappings, so a second migration adds nothing
+	if len(product.Mappings) > 0 || len(product.ReverseMappings) > 0 {
+		product.Mappings = nil
+		product.ReverseMappings = nil
+		jsonBytes, _ := json.Marshal(product)
+		if puterr := stub.PutState(common.Key(product.ProductID), jsonBytes); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+	}
+
+	type Report struct {
+		Edges int `json:"Edges"`
+	}
+	jsonBytes, _ := json.Marshal(Report{Edges: len(edges)})
+	return common.Success(http.StatusOK, "Lineage Migrated", jsonBytes)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTradeEdgesAreStoredOutsideTheProduct(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+
+	stored := Product{}
+	json.Unmarshal(f.stub.State["product01"], &stored)
+	if len(stored.Mappings) != 0 || len(stored.ReverseMappings) != 0 {
+		t.Fatalf("product document should not hold the lineage %+v", stored)
+	}
+
+	// Orders between existing members do not rewrite the Product
+	f.produce("PRO-B2", grower.ParticipantID, grower.MaterialID, "B2", 50)
+	f.trade("PO-IB1-2", Tier{grower.ParticipantID, grower.ParticipantType, grower.MaterialID, "B2"}, importer, 20)
+	f.trade("PO-IB1-3", Tier{grower.ParticipantID, grower.ParticipantType, grower.MaterialID, "B2"}, importer, 10)
+	json.Unmarshal(f.stub.State["product01"], &stored)
+	if stored.TotalQuantity != 150 {
+		t.Fatalf("expected product quantity 150, got %d", stored.TotalQuantity)
+	}
+	before := string(f.stub.State["product01"])
+	f.trade("PO-IB1-4", Tier{grower.ParticipantID, grower.ParticipantType, grower.MaterialID, "B2"}, importer, 5)
+	if string(f.stub.State["product01"]) != before {
+		t.Fatal("trade between existing members should not write the product")
+	}
+
+	f.stub.MockTransactionStart("scan")
+	edges, err := getLineageEdges(f.stub, grower.ParticipantID, grower.MaterialID, "B2", true)
+	if err != nil || len(edges) != 1 || edges[0].Quantity != 35 || len(edges[0].PurchaseOrders) != 3 {
+		t.Fatalf("unexpected forward edges %+v %v", edges, err)
+	}
+	edges, err = getLineageEdges(f.stub, importer.ParticipantID, importer.MaterialID, importer.BatchNumber, false)
+	if err != nil || len(edges) != 2 {
+		t.Fatalf("expected two edges into the importer batch %+v %v", edges, err)
+	}
+	f.stub.MockTransactionEnd("scan")
+
+	// The derived view folds the edges back into Mappings
+	product := f.getProduct("PRODUCT01")
+	if len(product.Mappings) != 2 || len(product.ReverseMappings) != 1 || len(product.ReverseMappings[0].From) != 2 || product.ReverseMappings[0].To.Quantity != 135 {
+		t.Fatalf("unexpected derived mappings %+v %+v", product.Mappings, product.ReverseMappings)
+	}
+}
+
+func TestMigrateLineageTurnsLegacyMappingsIntoEdges(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer, distributor)
+
+	// A Product recorded before the lineage edges holds its Mappings and has no edge
+	legacy := f.getProduct("PRODUCT01")
+	f.stub.MockTransactionStart("legacy")
+	for key := range f.stub.State {
+		if strings.HasPrefix(key, "\x00"+edgeObjectType) {
+			f.stub.DelState(key)
+		}
+	}
+	jsonBytes, _ := json.Marshal(legacy)
+	f.stub.PutState("product01", jsonBytes)
+	f.stub.MockTransactionEnd("legacy")
+	if root := f.trace("traceForward", grower); len(root.Children) != 0 {
+		t.Fatalf("expected no edge before the migration %+v", root)
+	}
+
+	f.as(grower.ParticipantID).mustInvoke(http.StatusForbidden, "migrateLineage", map[string]string{"ProductID": "PRODUCT01"})
+	f.asAdmin().mustInvoke(http.StatusNotFound, "migrateLineage", map[string]string{"ProductID": "UNKNOWN"})
+	res := f.mustInvoke(http.StatusOK, "migrateLineage", map[string]string{"ProductID": "PRODUCT01"})
+	if string(res.Payload) != `{"Edges":2}` {
+		t.Fatalf("unexpected migration report %s", res.Payload)
+	}
+	stored := Product{}
+	json.Unmarshal(f.stub.State["product01"], &stored)
+	if len(stored.Mappings) != 0 || len(stored.ReverseMappings) != 0 {
+		t.Fatalf("the migrated Product should not hold the lineage %+v", stored)
+	}
+
+	// A second migration adds nothing
+	if res := f.mustInvoke(http.StatusOK, "migrateLineage", map[string]string{"ProductID": "PRODUCT01"}); string(res.Payload) != `{"Edges":0}` {
+		t.Fatalf("unexpected second migration report %s", res.Payload)
+	}
+	root := f.trace("traceForward", grower)
+	if len(root.Children) != 1 || root.Children[0].Batch.Quantity != 100 || root.Children[0].Batch.PurchaseOrders[0] != "PO-IB1" || len(root.Children[0].Children) != 1 {
+		t.Fatalf("expected the migrated edges in the trace %+v", root)
+	}
+	if product := f.getProduct("PRODUCT01"); len(product.Mappings) != len(legacy.Mappings) || len(product.ReverseMappings) != len(legacy.ReverseMappings) {
+		t.Fatalf("expected the migrated mappings %+v", product.Mappings)
+	}
+
+	// Contamination spreads along the migrated edges
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "reportContamination", map[string]string{"ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "BatchNumber": grower.BatchNumber})
+	if batch := f.batch(distributor.ParticipantID, distributor.MaterialID, distributor.BatchNumber); !batch.IsCompromised {
+		t.Fatalf("expected the distributor batch compromised %+v", batch)
+	}
+}
@@ -0,0 +1,175 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Bill of Materials
+//****Description: Components of Production Orders, reserved on creation and consumed by the Goods Receipt
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//********************************************************************************************************
+//Struct for Bill of Materials
+//********************************************************************************************************
+
+//Materials read and updated by one transaction, each Material is stored once
+type materialSet struct {
+	keys      []string
+	materials map[string]*Material
+}
+
+func newMaterialSet() *materialSet {
+	return &materialSet{materials: map[string]*Material{}}
+}
+
+//load returns the Material of a key, nil if it does not exist
+func (set *materialSet) load(stub shim.ChaincodeStubInterface, materialID string) (*Material, error) {
+	materialID = strings.ToLower(materialID)
+	if material, exists := set.materials[materialID]; exists {
+		return material, nil
+	}
+	materialValue, err := stub.GetState(materialID)
+	if err != nil || materialValue == nil {
+		return nil, err
+	}
+	material := &Material{}
+	json.Unmarshal(materialValue, material)
+	set.keys = append(set.keys, materialID)
+	set.materials[materialID] = material
+	return material, nil
+}
+
+//store writes every loaded Material back to the ledger
+func (set *materialSet) store(stub shim.ChaincodeStubInterface) error {
+	for _, materialID := range set.keys {
+		jsonBytes, _ := json.Marshal(set.materials[materialID])
+		if err := stub.PutState(materialID, jsonBytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//findBatch returns the index of a batch in a Material, -1 if it does not exist
+func findBatch(material *Material, batchNumber string) int {
+	for index, element := range material.Batches {
+		if strings.ToLower(element.BatchNumber) == strings.ToLower(batchNumber) {
+			return index
+		}
+	}
+	return -1
+}
+
+//reserveComponents reserves the Quantity of every Component of a Production Order on its Batch.
+//The returned Materials hold the reservations, the status is the HTTP status of a rejected Component.
+func reserveComponents(stub shim.ChaincodeStubInterface, productionOrder ProductionOrder) (*materialSet, int32, error) {
+	materials := newMaterialSet()
+	for _, element := range productionOrder.Components {
+		if element.Quantity <= 0 {
+			return nil, http.StatusBadRequest, fmt.Errorf("Component Quantity must be Positive for Batch %s", element.BatchNumber)
+		}
+		material, err := materials.load(stub, common.Key(productionOrder.ParticipantID, element.MaterialID))
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		if material == nil {
+			return nil, http.StatusNotFound, fmt.Errorf("Component Material %s Does Not Exists for this Participant", element.MaterialID)
+		}
+		index := findBatch(material, element.BatchNumber)
+		if index < 0 {
+			return nil, http.StatusNotFound, fmt.Errorf("Component Batch %s Not Found!", element.BatchNumber)
+		}
+		batch := material.Batches[index]
+		if batch.Quantity-batch.ReservedQuantity < element.Quantity {
+			return nil, http.StatusBadRequest, fmt.Errorf("Not Enough Quantity Available in Component Batch %s!", element.BatchNumber)
+		}
+		batch.ReservedQuantity += element.Quantity
+		material.Batches[index] = batch
+		material.UpdateQuantities()
+	}
+	return materials, http.StatusCreated, nil
+}
+
+//releaseComponents releases the reservations of the Components of a cancelled Production Order.
+//A deleted Material or Batch holds no reservation and is skipped, the returned Materials hold the releases.
+func releaseComponents(stub shim.ChaincodeStubInterface, productionOrder ProductionOrder) (*materialSet, int32, error) {
+	materials := newMaterialSet()
+	for _, element := range productionOrder.Components {
+		material, err := materials.load(stub, common.Key(productionOrder.ParticipantID, element.MaterialID))
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		if material == nil {
+			continue
+		}
+		index := findBatch(material, element.BatchNumber)
+		if index < 0 {
+			continue
+		}
+		batch := material.Batches[index]
+		if batch.ReservedQuantity < element.Quantity {
+			return nil, http.StatusConflict, fmt.Errorf("Component Batch %s holds a Reservation of %d, below the Quantity %d of the Production Order!", element.BatchNumber, batch.ReservedQuantity, element.Quantity)
+		}
+		batch.ReservedQuantity -= element.Quantity
+		material.Batches[index] = batch
+		material.UpdateQuantities()
+	}
+	return materials, http.StatusOK, nil
+}
+
+//consumeComponents deducts the reserved Components of a Production Order from their Batches and records
+//an edge from every Component Batch to the output Batch. It returns the contamination flags the output
+//Batch inherits from its Components.
+func consumeComponents(stub shim.ChaincodeStubInterface, materials *materialSet, productionOrder ProductionOrder, output BatchTradeInfo, productID string, goodsReceipt string) (bool, bool, error) {
+	isCompromised, potentialCompromised := false, false
+	for _, element := range productionOrder.Components {
+		material, err := materials.load(stub, common.Key(productionOrder.ParticipantID, element.MaterialID))
+		if err != nil {
+			return false, false, err
+		}
+		index := -1
+		if material != nil {
+			index = findBatch(material, element.BatchNumber)
+		}
+		if index < 0 {
+			return false, false, fmt.Errorf("Component Batch %s Not Found!", element.BatchNumber)
+		}
+		batch := material.Batches[index]
+		if batch.ReservedQuantity < element.Quantity || batch.Quantity < element.Quantity {
+			return false, false, fmt.Errorf("Not Enough Quantity Reserved in Component Batch %s!", element.BatchNumber)
+		}
+		if batchKey(batch.ParticipantID, batch.MaterialID, batch.BatchNumber) == batchKey(output.ParticipantID, output.MaterialID, output.BatchNumber) {
+			return false, false, fmt.Errorf("Component Batch %s Cannot be the Output Batch", element.BatchNumber)
+		}
+		batch.Quantity -= element.Quantity
+		batch.ReservedQuantity -= element.Quantity
+		batch.ConsumedQuantity += element.Quantity
+		material.Batches[index] = batch
+		material.TotalQuantity -= element.Quantity
+		material.UpdateQuantities()
+
+		isCompromised = isCompromised || batch.IsCompromised
+		potentialCompromised = potentialCompromised || batch.PotentialCompromised
+
+		// The edge is listed under the Products of the Component and of the output
+		input := BatchTradeInfo{ParticipantID: batch.ParticipantID, MaterialID: batch.MaterialID, BatchNumber: batch.BatchNumber, SerialNumbers: batch.SerialNumbers}
+		order := LineageOrder{Against: "PRODUCTION ORDER", POID: productionOrder.POID, GRNumber: goodsReceipt}
+		if err := putLineageEdge(stub, []string{productID, material.ProductBCID}, input, output, element.Quantity, order); err != nil {
+			return false, false, err
+		}
+	}
+	if isCompromised {
+		potentialCompromised = false
+	}
+	return isCompromised, potentialCompromised, nil
+}
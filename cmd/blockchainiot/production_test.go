@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestProductionOrderConsumesComponents(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+	f.product("PRODUCT02")
+	f.material(importer.ParticipantID, "MAT03", "PRODUCT02")
+
+	order := func(orderID string, components ...Component) map[string]interface{} {
+		return map[string]interface{}{"POID": orderID, "ParticipantID": importer.ParticipantID, "MaterialID": "MAT03", "Quantity": 20, "UnitOfMeasure": "KG", "Components": components}
+	}
+	f.as(importer.ParticipantID).mustInvoke(http.StatusNotFound, "createProductionOrder", order("PRO-X", Component{importer.MaterialID, "UNKNOWN", 10}))
+	f.mustInvoke(http.StatusNotFound, "createProductionOrder", order("PRO-X", Component{grower.MaterialID, grower.BatchNumber, 10}))
+	f.mustInvoke(http.StatusBadRequest, "createProductionOrder", order("PRO-X", Component{importer.MaterialID, importer.BatchNumber, 0}))
+	f.mustInvoke(http.StatusBadRequest, "createProductionOrder", order("PRO-X", Component{importer.MaterialID, importer.BatchNumber, 60}, Component{importer.MaterialID, importer.BatchNumber, 50}))
+
+	f.mustInvoke(http.StatusCreated, "createProductionOrder", order("PRO-PB1", Component{importer.MaterialID, importer.BatchNumber, 30}))
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, importer.BatchNumber); batch.Quantity != 100 || batch.ReservedQuantity != 30 || batch.AvailableQuantity != 70 {
+		t.Fatalf("expected the component reserved %+v", batch)
+	}
+
+	f.mustInvoke(http.StatusCreated, "submitGoodsReceipt", map[string]interface{}{"GRNumber": "GR-PRO-PB1", "ReceivedBy": importer.ParticipantID, "Against": "PRODUCTION ORDER", "POID": "PRO-PB1", "BatchNumber": "PB1"})
+	if event := f.lastEvent(eventGoodsReceived); flatKeys(event) != "pro-pb1 product02 importer01-mat03 importer01-mat02 gr-pro-pb1" || len(event.Keys) != 9 {
+		t.Fatalf("expected the component and output materials in the event %+v", event)
+	}
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, importer.BatchNumber); batch.Quantity != 70 || batch.ReservedQuantity != 0 || batch.ConsumedQuantity != 30 {
+		t.Fatalf("expected the component consumed %+v", batch)
+	}
+	if material := f.getMaterial(importer.ParticipantID, importer.MaterialID); material.TotalQuantity != 70 || material.ConsumedQuantity != 30 {
+		t.Fatalf("unexpected component material %+v", material)
+	}
+	if batch := f.batch(importer.ParticipantID, "MAT03", "PB1"); batch.Quantity != 20 {
+		t.Fatalf("unexpected output batch %+v", batch)
+	}
+
+	// Both Products list the edge from the component to the output
+	for _, productID := range []string{"PRODUCT01", "PRODUCT02"} {
+		product := f.getProduct(productID)
+		found := false
+		for _, element := range product.Mappings {
+			for _, to := range element.To {
+				if to.BatchNumber == "PB1" && to.Quantity == 30 && len(to.ProductionOrders) == 1 && to.ProductionOrders[0] == "PRO-PB1" && len(to.PurchaseOrders) == 0 {
+					found = element.From.BatchNumber == importer.BatchNumber
+				}
+			}
+		}
+		if !found {
+			t.Fatalf("expected %s to map the component into PB1 %+v", productID, product.Mappings)
+		}
+	}
+
+	// Contamination of an ingredient reaches the processed goods
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "reportContamination", map[string]string{"ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "BatchNumber": grower.BatchNumber})
+	if batch := f.batch(importer.ParticipantID, "MAT03", "PB1"); !batch.IsCompromised {
+		t.Fatalf("expected the processed batch compromised %+v", batch)
+	}
+	if mapping := f.getProduct("PRODUCT02").Mappings[0]; !mapping.From.IsCompromised || !mapping.To[0].IsCompromised {
+		t.Fatalf("expected compromised mappings %+v", mapping)
+	}
+
+	// Goods produced from a compromised component are compromised on receipt
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createProductionOrder", order("PRO-PB2", Component{importer.MaterialID, importer.BatchNumber, 10}))
+	f.mustInvoke(http.StatusCreated, "submitGoodsReceipt", map[string]interface{}{"GRNumber": "GR-PRO-PB2", "ReceivedBy": importer.ParticipantID, "Against": "PRODUCTION ORDER", "POID": "PRO-PB2", "BatchNumber": "PB2"})
+	if batch := f.batch(importer.ParticipantID, "MAT03", "PB2"); !batch.IsCompromised || batch.PotentialCompromised {
+		t.Fatalf("expected the batch to inherit the contamination %+v", batch)
+	}
+}
+
+func TestCancelProductionOrderReleasesComponents(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+	f.material(importer.ParticipantID, "MAT03", "PRODUCT01")
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createProductionOrder", map[string]interface{}{"POID": "PRO-PB1", "ParticipantID": importer.ParticipantID, "MaterialID": "MAT03", "Quantity": 20, "UnitOfMeasure": "KG", "Components": []Component{{importer.MaterialID, importer.BatchNumber, 30}}})
+
+	// An open Production Order cannot be deleted with its Reservation
+	f.asAdmin().mustInvoke(http.StatusConflict, "deleteAsset", "PRO-PB1")
+	f.as(grower.ParticipantID).mustInvoke(http.StatusForbidden, "cancelProductionOrder", "PRO-PB1")
+	f.as(importer.ParticipantID).mustInvoke(http.StatusNotFound, "cancelProductionOrder", "PRO-UNKNOWN")
+
+	f.mustInvoke(http.StatusOK, "cancelProductionOrder", "PRO-PB1")
+	if event := f.lastEvent(eventProductionOrderCancelled); len(event.Keys) != 2 || event.Keys[1] != "importer01-mat02" || event.Status != "CANCELLED" {
+		t.Fatalf("unexpected event %+v", event)
+	}
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, importer.BatchNumber); batch.ReservedQuantity != 0 || batch.AvailableQuantity != 100 {
+		t.Fatalf("expected the reservation released %+v", batch)
+	}
+	f.mustInvoke(http.StatusBadRequest, "cancelProductionOrder", "PRO-PB1")
+	f.mustInvoke(http.StatusBadRequest, "submitGoodsReceipt", map[string]interface{}{"GRNumber": "GR-PRO-PB1", "ReceivedBy": importer.ParticipantID, "Against": "PRODUCTION ORDER", "POID": "PRO-PB1", "BatchNumber": "PB1"})
+	f.asAdmin().mustInvoke(http.StatusNoContent, "deleteAsset", "PRO-PB1")
+}
+
+func TestGoodsReceiptRejectsCancelledProductionOrder(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+	f.material(importer.ParticipantID, "MAT03", "PRODUCT01")
+	order := func(orderID string) map[string]interface{} {
+		return map[string]interface{}{"POID": orderID, "ParticipantID": importer.ParticipantID, "MaterialID": "MAT03", "Quantity": 20, "UnitOfMeasure": "KG", "Components": []Component{{importer.MaterialID, importer.BatchNumber, 30}}}
+	}
+	receipt := func(orderID string, batchNumber string) map[string]interface{} {
+		return map[string]interface{}{"GRNumber": "GR-" + orderID, "ReceivedBy": importer.ParticipantID, "Against": "PRODUCTION ORDER", "POID": orderID, "BatchNumber": batchNumber}
+	}
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createProductionOrder", order("PRO-A"))
+	f.mustInvoke(http.StatusOK, "cancelProductionOrder", "PRO-A")
+	f.mustInvoke(http.StatusCreated, "createProductionOrder", order("PRO-B"))
+
+	// The cancelled order cannot consume the stock reserved by another order
+	f.mustInvoke(http.StatusBadRequest, "submitGoodsReceipt", receipt("PRO-A", "PA"))
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, importer.BatchNumber); batch.Quantity != 100 || batch.ReservedQuantity != 30 {
+		t.Fatalf("expected the reservation of PRO-B untouched %+v", batch)
+	}
+	f.mustInvoke(http.StatusCreated, "submitGoodsReceipt", receipt("PRO-B", "PB"))
+}
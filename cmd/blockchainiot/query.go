@@ -0,0 +1,118 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Typed Queries
+//****Description: Structured queries over the BlockchainIOT assets, limited to the records the invoker may see
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//Asset types accepted by customQueries, keyed by the upper case name a client sends.
+//Owner fields name the Participants that may read a record, Products are the shared catalog.
+var assetSchemas = map[string]common.AssetSchema{
+	"PRODUCT":          common.NewAssetSchema("PRODUCT", Product{}),
+	"MATERIAL":         common.NewAssetSchema("MATERIAL", Material{}, "ParticipantID"),
+	"PRODUCTION ORDER": common.NewAssetSchema("PRODUCTION ORDER", ProductionOrder{}, "ParticipantID"),
+	"PURCHASE ORDER":   common.NewAssetSchema("PURCHASE ORDER", PurchaseOrder{}, "RequestorID", "VendorID"),
+	"SHIPMENT":         common.NewAssetSchema("SHIPMENT", Shipment{}, "RequestorID", "VendorID"),
+	"GOODS RECEIPT":    common.NewAssetSchema("Goods Receipt", GoodsReceipt{}, "ReceivedBy"),
+	"PARTICIPANT":      common.NewAssetSchema("PARTICIPANT", Participant{}, "ParticipantID"),
+}
+
+//queryScope returns the Participant whose records the invoker may read, empty for admins
+func queryScope(stub shim.ChaincodeStubInterface) (string, error) {
+	identity, err := getCreatorIdentity(stub)
+	if err != nil {
+		return "", fmt.Errorf("Invalid Identity - %s", err.Error())
+	}
+	if identity.IsAdmin() {
+		return "", nil
+	}
+	participant, err := getBoundParticipant(stub, identity)
+	if err != nil {
+		return "", err
+	}
+	if participant == nil {
+		return "", fmt.Errorf("Identity %s/%s is not enrolled as a Participant", identity.MSPID, identity.EnrollmentID)
+	}
+	return participant.ParticipantID, nil
+}
+
+//readableKey reports whether the Participant may read the record under a key, every record for admins.
+//A deleted record is judged on its last version, so its owners keep reading its history.
+func readableKey(stub shim.ChaincodeStubInterface, participantID string, key string) (bool, error) {
+	if participantID == "" {
+		return true, nil
+	}
+	value, err := stub.GetState(key)
+	if err != nil {
+		return false, err
+	}
+	if value == nil {
+		rawValue := func(key string, value []byte) interface{} { return json.RawMessage(value) }
+		records, err := common.GetHistory(stub, common.HistoryOptions{}, rawValue, key)
+		if err != nil {
+			return false, err
+		}
+		for i := len(records) - 1; i >= 0 && value == nil; i-- {
+			if !records[i].IsDelete {
+				value = records[i].Value.(json.RawMessage)
+			}
+		}
+	}
+	return value != nil && common.ReadableBy(assetSchemas, value, participantID), nil
+}
+
+//buildTypedQuery parses a typed query and builds its selector for the invoker.
+//The status tells the caller how to report an error.
+func buildTypedQuery(stub shim.ChaincodeStubInterface, data string) (common.TypedQuery, string, int, error) {
+	query := common.TypedQuery{}
+	if err := json.Unmarshal([]byte(data), &query); err != nil || query.AssetType == "" {
+		return query, "", http.StatusBadRequest, fmt.Errorf("Invalid Data - A typed query with an AssetType is expected")
+	}
+	participantID, err := queryScope(stub)
+	if err != nil {
+		return query, "", http.StatusForbidden, err
+	}
+	selector, err := query.Selector(assetSchemas, participantID)
+	if err != nil {
+		return query, "", http.StatusBadRequest, fmt.Errorf("Invalid Query - %s", err.Error())
+	}
+	return query, selector, http.StatusOK, nil
+}
+
+// Custom Queries - Arguments: Typed Query, Page Size (optional), Bookmark (optional)
+func (t *BlockchainIOT) customQueries(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+	query, selector, status, err := buildTypedQuery(stub, string(args[0]))
+	if err != nil {
+		return common.Error(int32(status), "Invoke Error: "+err.Error())
+	}
+
+	// The payload Limit and Bookmark take precedence over the page arguments
+	pageSize, bookmark, pageerr := common.PageArguments(args)
+	if pageerr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: "+pageerr.Error())
+	}
+	if query.Bookmark != "" {
+		bookmark = query.Bookmark
+	}
+	queryResults, err := common.QueryExecutionWithPagination(stub, selector, query.PageSize(pageSize), bookmark)
+	if err != nil {
+		return common.Error(http.StatusInternalServerError, err.Error())
+	}
+	return common.Success(http.StatusOK, "OK", queryResults)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+func typedSelector(f *fixture, data string) map[string]interface{} {
+	f.t.Helper()
+	_, selector, status, err := buildTypedQuery(f.stub, data)
+	if err != nil {
+		f.t.Fatalf("unexpected error %d: %s", status, err)
+	}
+	couchQuery := map[string]map[string]interface{}{}
+	json.Unmarshal([]byte(selector), &couchQuery)
+	return couchQuery["selector"]
+}
+
+func TestTypedQueryIsScopedToTheInvoker(t *testing.T) {
+	f := newFixture(t)
+	f.participant(grower.ParticipantID, grower.ParticipantType)
+
+	selector := typedSelector(f.as(grower.ParticipantID), `{"AssetType":"production order","Filters":{"Status":"OPEN"}}`)
+	if selector["Asset_Type"] != "PRODUCTION ORDER" || selector["ParticipantID"] != grower.ParticipantID || selector["Status"] != "OPEN" {
+		t.Fatalf("unexpected selector %v", selector)
+	}
+	selector = typedSelector(f, `{"AssetType":"GOODS RECEIPT"}`)
+	if selector["Asset_Type"] != "Goods Receipt" || selector["ReceivedBy"] != grower.ParticipantID {
+		t.Fatalf("unexpected selector %v", selector)
+	}
+	for _, assetType := range []string{"PURCHASE ORDER", "SHIPMENT"} {
+		if owners, _ := typedSelector(f, `{"AssetType":"`+assetType+`"}`)["$or"].([]interface{}); len(owners) != 2 {
+			t.Fatalf("expected requestor and vendor scopes on %s, got %v", assetType, owners)
+		}
+	}
+	if selector = typedSelector(f, `{"AssetType":"PARTICIPANT"}`); selector["ParticipantID"] != grower.ParticipantID {
+		t.Fatalf("unexpected selector %v", selector)
+	}
+	if selector = typedSelector(f, `{"AssetType":"PRODUCT"}`); len(selector) != 1 {
+		t.Fatalf("products are shared, got %v", selector)
+	}
+	if selector = typedSelector(f.asAdmin(), `{"AssetType":"MATERIAL"}`); len(selector) != 1 {
+		t.Fatalf("admins are not scoped, got %v", selector)
+	}
+}
+
+func TestReadsByKeyAreScopedToTheInvoker(t *testing.T) {
+	f := newFixture(t).recordHistory()
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+	f.participant(distributor.ParticipantID, distributor.ParticipantType)
+	history := func(status int32, key string) {
+		t.Helper()
+		if res := new(BlockchainIOT).getHistory(f.historyStub(), []string{key}); res.Status != status {
+			t.Fatalf("expected status %d for the history of %s, got %d: %s", status, key, res.Status, res.Message)
+		}
+	}
+
+	// Both parties read the Purchase Order, each one only its own Material
+	for _, tier := range []Tier{grower, importer} {
+		f.as(tier.ParticipantID).mustInvoke(http.StatusOK, "getAsset", "PO-"+importer.BatchNumber)
+		f.mustInvoke(http.StatusOK, "getMaterial", tier.ParticipantID, tier.MaterialID)
+		history(http.StatusOK, "PO-"+importer.BatchNumber)
+	}
+	f.mustInvoke(http.StatusNotFound, "getAsset", grower.ParticipantID+"-"+grower.MaterialID)
+	f.mustInvoke(http.StatusNotFound, "getMaterial", grower.ParticipantID, grower.MaterialID)
+	history(http.StatusNotFound, grower.ParticipantID+"-"+grower.MaterialID)
+
+	// Others only read the shared Products
+	f.as(distributor.ParticipantID).mustInvoke(http.StatusNotFound, "getAsset", "PO-"+importer.BatchNumber)
+	f.mustInvoke(http.StatusOK, "getAsset", "PRODUCT01")
+	history(http.StatusNotFound, "PO-"+importer.BatchNumber)
+	history(http.StatusOK, "PRODUCT01")
+	f.as("stranger").mustInvoke(http.StatusForbidden, "getAsset", "PRODUCT01")
+
+	// The history of a deleted record stays readable by its owners
+	f.asAdmin().mustInvoke(http.StatusNoContent, "deleteMaterial", grower.ParticipantID, grower.MaterialID)
+	f.as(grower.ParticipantID)
+	history(http.StatusOK, grower.ParticipantID+"-"+grower.MaterialID)
+	f.as(importer.ParticipantID)
+	history(http.StatusNotFound, grower.ParticipantID+"-"+grower.MaterialID)
+}
+
+func TestCustomQueriesRejectsRawSelectors(t *testing.T) {
+	f := newFixture(t)
+	f.participant(grower.ParticipantID, grower.ParticipantType)
+	f.as(grower.ParticipantID).mustInvoke(http.StatusBadRequest, "customQueries", `{"selector":{"Asset_Type":"MATERIAL"}}`)
+	f.mustInvoke(http.StatusBadRequest, "customQueries", `{"AssetType":"MATERIAL","Filters":{"Owner":"GROWER01"}}`)
+	f.mustInvoke(http.StatusBadRequest, "customQueries", `{"AssetType":"MATERIAL","Filters":{"ParticipantID":{"$regex":"."}}}`)
+	f.as("stranger").mustInvoke(http.StatusForbidden, "customQueries", `{"AssetType":"MATERIAL"}`)
+}
+
+func TestTypedQueriesAreIndexed(t *testing.T) {
+	indexes, err := common.ReadIndexes(common.IndexFolder)
+	if err != nil || len(indexes) == 0 {
+		t.Fatalf("cannot read the index definitions: %v", err)
+	}
+	covering := func(data string, participantID string) common.CouchIndex {
+		t.Helper()
+		query := common.TypedQuery{}
+		json.Unmarshal([]byte(data), &query)
+		selector, err := query.Selector(assetSchemas, participantID)
+		if err != nil {
+			t.Fatalf("%s: %s", data, err)
+		}
+		index, found := common.CoveringIndex(selector, indexes)
+		if !found {
+			t.Fatalf("%s as %q is not covered by an index", data, participantID)
+		}
+		return index
+	}
+	indexed := func(index common.CouchIndex, field string) bool {
+		for _, element := range index.Index.Fields {
+			if element == field {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Every asset type and field filter, for admins and Participants.
+	// A Participant scoped by one owner field must not fall back to an index on Asset_Type alone.
+	for assetType, schema := range assetSchemas {
+		for _, participantID := range []string{"", grower.ParticipantID} {
+			queries := []string{`{"AssetType":"` + assetType + `"}`}
+			for field := range schema.Fields {
+				if field != "Asset_Type" {
+					queries = append(queries, `{"AssetType":"`+assetType+`","Filters":{"`+field+`":"X"}}`)
+				}
+			}
+			for _, data := range queries {
+				index := covering(data, participantID)
+				if participantID != "" && len(schema.OwnerFields) == 1 && !indexed(index, schema.OwnerFields[0]) {
+					t.Fatalf("%s as %q uses %s, which does not index the owner field %s", data, participantID, index.Name, schema.OwnerFields[0])
+				}
+			}
+		}
+	}
+
+	// The common queries use their dedicated index, every index but the one on Asset_Type alone serves one of them
+	used := map[string]bool{"indexAssetType": true}
+	for _, query := range []struct{ data, participantID, index string }{
+		{`{"AssetType":"MATERIAL"}`, grower.ParticipantID, "indexParticipant"},
+		{`{"AssetType":"PARTICIPANT"}`, grower.ParticipantID, "indexParticipant"},
+		{`{"AssetType":"PURCHASE ORDER","Filters":{"RequestorID":"IMPORTER01","Status":"OPEN"}}`, importer.ParticipantID, "indexRequestorStatus"},
+		{`{"AssetType":"PURCHASE ORDER","Filters":{"VendorID":"GROWER01"},"Sort":[{"Field":"Status"}]}`, grower.ParticipantID, "indexVendorStatus"},
+		{`{"AssetType":"PRODUCTION ORDER","Filters":{"Status":"OPEN"}}`, grower.ParticipantID, "indexParticipantStatus"},
+		{`{"AssetType":"MATERIAL","Filters":{"ProductBCID":"PRODUCT01"}}`, grower.ParticipantID, "indexParticipantProduct"},
+		{`{"AssetType":"MATERIAL","Filters":{"ProductBCID":"PRODUCT01","Batches":{"$elemMatch":{"IsCompromised":true}}}}`, "", "indexProduct"},
+		{`{"AssetType":"SHIPMENT","Filters":{"Status":"SHIPPING"}}`, grower.ParticipantID, "indexAssetStatus"},
+		{`{"AssetType":"GOODS RECEIPT"}`, importer.ParticipantID, "indexReceivedBy"},
+	} {
+		if index := covering(query.data, query.participantID); index.Name != query.index {
+			t.Fatalf("%s: expected %s, got %s", query.data, query.index, index.Name)
+		}
+		used[query.index] = true
+	}
+	for _, index := range indexes {
+		if !used[index.Name] {
+			t.Fatalf("no common query uses %s", index.Name)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Batch Repacking
+//****Description: Split and merge of the batches of a Material, recorded as lineage edges
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//********************************************************************************************************
+//Struct for Batch Repacking
+//********************************************************************************************************
+
+//New batch split off a batch with part of its Quantity and Serial Numbers
+type BatchSplit struct {
+	BatchNumber   string   `json:"BatchNumber"`
+	Quantity      int      `json:"Quantity"`
+	SerialNumbers []string `json:"SerialNumbers,omitempty"`
+}
+
+//takeSerialNumbers removes the taken Serial Numbers from a batch, every one must belong to it
+func takeSerialNumbers(serialNumbers []string, taken []string) ([]string, error) {
+	remaining := append([]string{}, serialNumbers...)
+	for _, element := range taken {
+		found := false
+		for index, serialNumber := range remaining {
+			if serialNumber == element {
+				remaining = append(remaining[:index], remaining[index+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Serial Number %s is not in the Batch", element)
+		}
+	}
+	return remaining, nil
+}
+
+//batchNumbers lists the Batch Numbers of batches
+func batchNumbers(batches []BatchInfo) []string {
+	result := []string{}
+	for _, element := range batches {
+		result = append(result, element.BatchNumber)
+	}
+	return result
+}
+
+//repackEdge records the Quantity moved from one batch of a Material to another
+func repackEdge(stub shim.ChaincodeStubInterface, material *Material, from BatchInfo, to BatchInfo, quantity int) error {
+	fromInfo := BatchTradeInfo{ParticipantID: from.ParticipantID, MaterialID: from.MaterialID, BatchNumber: from.BatchNumber, SerialNumbers: from.SerialNumbers}
+	toInfo := BatchTradeInfo{ParticipantID: to.ParticipantID, MaterialID: to.MaterialID, BatchNumber: to.BatchNumber, SerialNumbers: to.SerialNumbers}
+	return putLineageEdge(stub, []string{material.ProductBCID}, fromInfo, toInfo, quantity, LineageOrder{})
+}
+
+//********************************************************************************************************
+// Batch Repacking Functions
+//********************************************************************************************************
+
+// Split a Batch into new Batches of the same Material
+// The Quantity and Serial Numbers of the new Batches are taken from the unreserved Quantity of the Batch
+func (t *BlockchainIOT) splitBatch(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ParticipantID string       `json:"ParticipantID"`
+		MaterialID    string       `json:"MaterialID"`
+		BatchNumber   string       `json:"BatchNumber"`
+		Splits        []BatchSplit `json:"Splits"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil || len(queryData.Splits) == 0 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	// Get Material
+	materialID := common.Key(queryData.ParticipantID, queryData.MaterialID)
+	materialValue, materialGetErr := stub.GetState(materialID)
+	if materialGetErr != nil || materialValue == nil {
+		return common.Error(http.StatusNotFound, "Material Does Not Exist! Please Check Participant ID and Material ID!")
+	}
+	material := Material{}
+	json.Unmarshal(materialValue, &material)
+
+	// Only the Owner repacks the Batches of a Material
+	participantID, scopeErr := queryScope(stub)
+	if scopeErr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeErr.Error())
+	}
+	if participantID != "" && !strings.EqualFold(participantID, material.ParticipantID) {
+		return common.Error(http.StatusForbidden, "Invoke Error: Only the Owner of the Material can Split its Batches")
+	}
+
+	index := findBatch(&material, queryData.BatchNumber)
+	if index < 0 {
+		return common.Error(http.StatusNotFound, "Batch Does Not Exist for this Material!")
+	}
+	original := material.Batches[index]
+	parent := original
+
+	// Take the Quantity and Serial Numbers of every new Batch from the parent
+	children := []BatchInfo{}
+	for _, element := range queryData.Splits {
+		if element.Quantity <= 0 {
+			return common.Error(http.StatusBadRequest, "Invoke Error: Split Quantity must be Positive")
+		}
+		if findBatch(&material, element.BatchNumber) >= 0 || containsFold(batchNumbers(children), element.BatchNumber) {
+			return common.Error(http.StatusConflict, "Batch "+element.BatchNumber+" Already Exists! \n Please Specify Another Batch Number")
+		}
+		if parent.Quantity-parent.ReservedQuantity < element.Quantity {
+			return common.Error(http.StatusBadRequest, "Not Enough Quantity Available in this Batch!")
+		}
+		remaining, serialErr := takeSerialNumbers(parent.SerialNumbers, element.SerialNumbers)
+		if serialErr != nil {
+			return common.Error(http.StatusBadRequest, "Invoke Error: "+serialErr.Error())
+		}
+		parent.SerialNumbers = remaining
+		parent.Quantity -= element.Quantity
+
+		child := BatchInfo{}
+		child.ParticipantID = parent.ParticipantID
+		child.MaterialID = parent.MaterialID
+		child.BatchNumber = element.BatchNumber
+		child.SerialNumbers = element.SerialNumbers
+		child.Quantity = element.Quantity
+		child.IsCompromised = parent.IsCompromised
+		child.PotentialCompromised = parent.PotentialCompromised
+		child.SetShelfLife(parent.ShelfLife())
+		children = append(children, child)
+	}
+
+	// Update Material, the Total Quantity does not change
+	material.Batches[index] = parent
+	material.Batches = append(material.Batches, children...)
+	material.UpdateQuantities()
+
+	// Update Lineage
+	for _, element := range children {
+		if puterr := repackEdge(stub, &material, original, element, element.Quantity); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+	}
+
+	// Store in Blockchain
+	jsonBytes, _ := json.Marshal(material)
+	if puterr := stub.PutState(materialID, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Emit Event
+	if eventerr := emitEvent(stub, ChaincodeEvent{EventType: eventBatchSplit, Participants: []string{material.ParticipantID}}); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	return common.Success(http.StatusCreated, "Batch Split", nil)
+}
+
+// Merge Batches of a Material into one Batch
+// The whole Quantity and every Serial Number of the merged Batches move to the target Batch, created if needed
+func (t *BlockchainIOT) mergeBatches(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ParticipantID string   `json:"ParticipantID"`
+		MaterialID    string   `json:"MaterialID"`
+		BatchNumbers  []string `json:"BatchNumbers"` // Batches merged and emptied
+		BatchNumber   string   `json:"BatchNumber"`  // Target Batch
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil || len(queryData.BatchNumbers) == 0 || queryData.BatchNumber == "" {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	// Get Material
+	materialID := common.Key(queryData.ParticipantID, queryData.MaterialID)
+	materialValue, materialGetErr := stub.GetState(materialID)
+	if materialGetErr != nil || materialValue == nil {
+		return common.Error(http.StatusNotFound, "Material Does Not Exist! Please Check Participant ID and Material ID!")
+	}
+	material := Material{}
+	json.Unmarshal(materialValue, &material)
+
+	// Only the Owner repacks the Batches of a Material
+	participantID, scopeErr := queryScope(stub)
+	if scopeErr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeErr.Error())
+	}
+	if participantID != "" && !strings.EqualFold(participantID, material.ParticipantID) {
+		return common.Error(http.StatusForbidden, "Invoke Error: Only the Owner of the Material can Merge its Batches")
+	}
+
+	// Get or Create the Target Batch
+	targetIndex := findBatch(&material, queryData.BatchNumber)
+	if targetIndex < 0 {
+		target := BatchInfo{}
+		target.ParticipantID = material.ParticipantID
+		target.MaterialID = material.MaterialMasterID
+		target.BatchNumber = queryData.BatchNumber
+		material.Batches = append(material.Batches, target)
+		targetIndex = len(material.Batches) - 1
+	}
+
+	// Empty every merged Batch into the Target
+	sources := []BatchInfo{}
+	for _, element := range queryData.BatchNumbers {
+		if strings.ToLower(element) == strings.ToLower(queryData.BatchNumber) || containsFold(batchNumbers(sources), element) {
+			return common.Error(http.StatusBadRequest, "Invoke Error: A Batch can only be Merged once and not into itself")
+		}
+		index := findBatch(&material, element)
+		if index < 0 {
+			return common.Error(http.StatusNotFound, "Batch "+element+" Does Not Exist for this Material!")
+		}
+		source := material.Batches[index]
+		if source.ReservedQuantity > 0 {
+			return common.Error(http.StatusBadRequest, "Batch "+element+" is Reserved by a Purchase Order or Production Order and cannot be Merged")
+		}
+		if source.Quantity <= 0 {
+			return common.Error(http.StatusBadRequest, "Batch "+element+" is Empty")
+		}
+		sources = append(sources, source)
+
+		target := material.Batches[targetIndex]
+		target.Quantity += source.Quantity
+		target.SerialNumbers = append(target.SerialNumbers, source.SerialNumbers...)
+		target.IsCompromised = target.IsCompromised || source.IsCompromised
+		target.PotentialCompromised = !target.IsCompromised && (target.PotentialCompromised || source.PotentialCompromised)
+		target.SetShelfLife(target.ShelfLife().Earliest(source.ShelfLife()))
+		material.Batches[targetIndex] = target
+
+		source.Quantity = 0
+		source.SerialNumbers = nil
+		material.Batches[index] = source
+	}
+
+	// Update Material, the Total Quantity does not change
+	material.UpdateQuantities()
+
+	// Update Lineage
+	target := material.Batches[targetIndex]
+	for _, element := range sources {
+		if puterr := repackEdge(stub, &material, element, target, element.Quantity); puterr != nil {
+			return common.Error(http.StatusInternalServerError, puterr.Error())
+		}
+	}
+
+	// Store in Blockchain
+	jsonBytes, _ := json.Marshal(material)
+	if puterr := stub.PutState(materialID, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+
+	// Emit Event
+	if eventerr := emitEvent(stub, ChaincodeEvent{EventType: eventBatchesMerged, Participants: []string{material.ParticipantID}}); eventerr != nil {
+		return common.Error(http.StatusInternalServerError, eventerr.Error())
+	}
+	return common.Success(http.StatusCreated, "Batches Merged", nil)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSplitAndMergeConserveBatches(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+	split := func(status int32, batchNumber string, splits ...BatchSplit) {
+		t.Helper()
+		f.as(importer.ParticipantID).mustInvoke(status, "splitBatch", map[string]interface{}{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumber": batchNumber, "Splits": splits})
+	}
+
+	split(http.StatusBadRequest, importer.BatchNumber, BatchSplit{"IB1-A", 60, nil}, BatchSplit{"IB1-B", 50, nil})
+	split(http.StatusConflict, importer.BatchNumber, BatchSplit{importer.BatchNumber, 10, nil})
+	split(http.StatusBadRequest, importer.BatchNumber, BatchSplit{"IB1-A", 10, []string{"S1"}})
+	split(http.StatusNotFound, "UNKNOWN", BatchSplit{"IB1-A", 10, nil})
+	f.as(grower.ParticipantID).mustInvoke(http.StatusForbidden, "splitBatch", map[string]interface{}{"ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "BatchNumber": grower.BatchNumber, "Splits": []BatchSplit{{"B1-A", 10, nil}}})
+
+	split(http.StatusCreated, importer.BatchNumber, BatchSplit{"IB1-A", 30, nil}, BatchSplit{"IB1-B", 50, nil})
+	if event := f.lastEvent(eventBatchSplit); flatKeys(event) != "importer01-mat02" || len(event.Keys) != 7 {
+		t.Fatalf("unexpected split event %+v", event)
+	}
+	for batchNumber, quantity := range map[string]int{importer.BatchNumber: 20, "IB1-A": 30, "IB1-B": 50} {
+		if batch := f.batch(importer.ParticipantID, importer.MaterialID, batchNumber); batch.Quantity != quantity {
+			t.Fatalf("expected %d in %s, got %+v", quantity, batchNumber, batch)
+		}
+	}
+
+	merge := map[string]interface{}{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumbers": []string{"IB1-A", "IB1-B"}, "BatchNumber": "IB1-C"}
+	f.mustInvoke(http.StatusCreated, "mergeBatches", merge)
+	f.mustInvoke(http.StatusBadRequest, "mergeBatches", merge)
+	f.mustInvoke(http.StatusBadRequest, "mergeBatches", map[string]interface{}{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumbers": []string{"IB1-C"}, "BatchNumber": "IB1-C"})
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, "IB1-C"); batch.Quantity != 80 || batch.AvailableQuantity != 80 {
+		t.Fatalf("unexpected merged batch %+v", batch)
+	}
+	if material := f.getMaterial(importer.ParticipantID, importer.MaterialID); material.TotalQuantity != 100 || material.AvailableQuantity != 100 || len(material.Batches) != 4 {
+		t.Fatalf("repacking must conserve the material quantity %+v", material)
+	}
+
+	// Trace and contamination follow the repacked goods
+	root := f.trace("traceBackward", Tier{importer.ParticipantID, importer.ParticipantType, importer.MaterialID, "IB1-C"})
+	if len(root.Children) != 2 || root.Children[0].Children[0].Batch.BatchNumber != importer.BatchNumber || root.Children[0].Children[0].Children[0].Batch.BatchNumber != grower.BatchNumber {
+		t.Fatalf("unexpected backward trace %+v", root)
+	}
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "reportContamination", map[string]string{"ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "BatchNumber": grower.BatchNumber})
+	for _, batchNumber := range []string{importer.BatchNumber, "IB1-A", "IB1-B", "IB1-C"} {
+		if batch := f.batch(importer.ParticipantID, importer.MaterialID, batchNumber); !batch.IsCompromised {
+			t.Fatalf("expected %s compromised %+v", batchNumber, batch)
+		}
+	}
+}
+
+func TestSplitAndMergeConserveSerialNumbers(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+	f.participant(importer.ParticipantID, importer.ParticipantType)
+	f.material(importer.ParticipantID, importer.MaterialID, "PRODUCT01")
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createProductionOrder", map[string]interface{}{"POID": "PRO-S", "ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "Quantity": 3, "UnitOfMeasure": "EA"})
+	f.mustInvoke(http.StatusCreated, "submitGoodsReceipt", map[string]interface{}{"GRNumber": "GR-S", "ReceivedBy": importer.ParticipantID, "Against": "PRODUCTION ORDER", "POID": "PRO-S", "BatchNumber": "S", "SerialNumbers": []string{"S1", "S2", "S3"}})
+
+	split := func(status int32, splits ...BatchSplit) {
+		t.Helper()
+		f.mustInvoke(status, "splitBatch", map[string]interface{}{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumber": "S", "Splits": splits})
+	}
+	split(http.StatusBadRequest, BatchSplit{"S-A", 1, []string{"S1"}}, BatchSplit{"S-B", 1, []string{"S1"}})
+	split(http.StatusCreated, BatchSplit{"S-A", 1, []string{"S1"}}, BatchSplit{"S-B", 1, []string{"S3"}})
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, "S"); batch.Quantity != 1 || len(batch.SerialNumbers) != 1 || batch.SerialNumbers[0] != "S2" {
+		t.Fatalf("unexpected parent batch %+v", batch)
+	}
+
+	f.mustInvoke(http.StatusCreated, "mergeBatches", map[string]interface{}{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumbers": []string{"S-A", "S-B"}, "BatchNumber": "S"})
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, "S"); batch.Quantity != 3 || len(batch.SerialNumbers) != 3 {
+		t.Fatalf("expected every serial number back in the batch %+v", batch)
+	}
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, "S-A"); batch.Quantity != 0 || len(batch.SerialNumbers) != 0 {
+		t.Fatalf("expected the merged batch emptied %+v", batch)
+	}
+}
+
+func TestOnlyTheOwnerRepacksBatches(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer)
+	f.participant(distributor.ParticipantID, distributor.ParticipantType)
+
+	f.as(distributor.ParticipantID).mustInvoke(http.StatusForbidden, "splitBatch", map[string]interface{}{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumber": importer.BatchNumber, "Splits": []BatchSplit{{"IB1-A", 10, nil}}})
+	f.mustInvoke(http.StatusForbidden, "mergeBatches", map[string]interface{}{"ParticipantID": importer.ParticipantID, "MaterialID": importer.MaterialID, "BatchNumbers": []string{importer.BatchNumber}, "BatchNumber": "IB2"})
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, importer.BatchNumber); batch.Quantity != 100 {
+		t.Fatalf("expected the batch untouched, got %+v", batch)
+	}
+}
@@ -0,0 +1,211 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - GPS Retention
+//****Description: Bounded GPS history of shipments, older readings rolled up into Merkle anchored segments
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//Composite key object type of the GPS Retention policy, apart from the keys of the assets
+const gpsRetentionObjectType = "gpsretention"
+
+//Policy used until setGPSRetention stores another one
+var defaultGPSRetention = GPSRetention{Asset_Type: "GPS RETENTION", RawReadings: 100, SegmentSize: 50, TrackPoints: 5, MaxTrackPoints: 500}
+
+//********************************************************************************************************
+//Struct for GPS Retention
+//********************************************************************************************************
+
+//How much of the GPS history a Shipment keeps
+type GPSRetention struct {
+	Asset_Type     string `json:"Asset_Type,omitempty"`
+	RawReadings    int    `json:"RawReadings"`    // Latest readings kept as recorded
+	SegmentSize    int    `json:"SegmentSize"`    // Older readings rolled up into one segment
+	TrackPoints    int    `json:"TrackPoints"`    // Readings of a segment kept in the downsampled track
+	MaxTrackPoints int    `json:"MaxTrackPoints"` // Size of the track before it is thinned out
+}
+
+//Readings rolled up out of a Shipment, anchored by the Merkle root of their gpsLeaf encodings.
+//The readings themselves stay in the transactions that recorded them and in off-chain stores.
+type GPSSegment struct {
+	Index      int    `json:"Index"`
+	From       string `json:"From"` // Timestamp of the first reading
+	To         string `json:"To"`   // Timestamp of the last reading
+	Count      int    `json:"Count"`
+	MerkleRoot string `json:"MerkleRoot"`
+}
+
+//validate checks the policy keeps at least one reading of every kind
+func (retention GPSRetention) validate() error {
+	if retention.RawReadings < 1 || retention.SegmentSize < 1 || retention.TrackPoints < 1 {
+		return fmt.Errorf("RawReadings, SegmentSize and TrackPoints must be positive")
+	}
+	if retention.MaxTrackPoints < retention.TrackPoints {
+		return fmt.Errorf("MaxTrackPoints must not be below TrackPoints")
+	}
+	return nil
+}
+
+//Key for fetching/storing the GPS Retention policy
+func gpsRetentionKey(stub shim.ChaincodeStubInterface) (string, error) {
+	return stub.CreateCompositeKey(gpsRetentionObjectType, []string{})
+}
+
+//getGPSRetention gets the policy from the ledger, falling back to the default policy
+//when none is stored or the stored one is not a valid policy
+func getGPSRetention(stub shim.ChaincodeStubInterface) (GPSRetention, error) {
+	key, err := gpsRetentionKey(stub)
+	if err != nil {
+		return defaultGPSRetention, err
+	}
+	value, err := stub.GetState(key)
+	if err != nil || value == nil {
+		return defaultGPSRetention, err
+	}
+	retention := GPSRetention{}
+	if err := json.Unmarshal(value, &retention); err != nil || retention.Asset_Type != defaultGPSRetention.Asset_Type || retention.validate() != nil {
+		return defaultGPSRetention, nil
+	}
+	return retention, nil
+}
+
+//gpsLeaf is the Merkle leaf of a reading, its JSON encoding with the fields in declaration order
+func gpsLeaf(reading GetGPSReading) []byte {
+	jsonBytes, _ := json.Marshal(reading)
+	return jsonBytes
+}
+
+//downsample keeps count readings spread evenly over the list, the first and the last included
+func downsample(readings []GetGPSReading, count int) []GetGPSReading {
+	if len(readings) <= count {
+		return append([]GetGPSReading{}, readings...)
+	}
+	if count == 1 {
+		return []GetGPSReading{readings[0]}
+	}
+	result := []GetGPSReading{}
+	for index := 0; index < count; index++ {
+		result = append(result, readings[index*(len(readings)-1)/(count-1)])
+	}
+	return result
+}
+
+//retainGPS rolls the oldest raw readings up into segments until at most RawReadings are left.
+//Each segment adds TrackPoints readings to the track, which is halved when it grows past MaxTrackPoints.
+func (shipment *Shipment) retainGPS(retention GPSRetention) {
+	if retention.validate() != nil {
+		return
+	}
+	for len(shipment.GPSReading) > retention.RawReadings {
+		count := retention.SegmentSize
+		if count > len(shipment.GPSReading) {
+			count = len(shipment.GPSReading)
+		}
+		if count < 1 {
+			return
+		}
+		rolled := shipment.GPSReading[:count]
+		leaves := [][]byte{}
+		for _, element := range rolled {
+			leaves = append(leaves, gpsLeaf(element))
+		}
+		shipment.GPSSegments = append(shipment.GPSSegments, GPSSegment{
+			Index:      len(shipment.GPSSegments),
+			From:       rolled[0].Timestamp,
+			To:         rolled[count-1].Timestamp,
+			Count:      count,
+			MerkleRoot: common.MerkleRoot(leaves),
+		})
+		shipment.GPSTrack = append(shipment.GPSTrack, downsample(rolled, retention.TrackPoints)...)
+		for len(shipment.GPSTrack) > retention.MaxTrackPoints {
+			shipment.GPSTrack = downsample(shipment.GPSTrack, (len(shipment.GPSTrack)+1)/2)
+		}
+		shipment.GPSReading = append([]GetGPSReading{}, shipment.GPSReading[count:]...)
+	}
+}
+
+//********************************************************************************************************
+// GPS Retention Functions
+//********************************************************************************************************
+
+// Set the GPS Retention policy - Arguments: {"RawReadings", "SegmentSize", "TrackPoints", "MaxTrackPoints"}
+// Shipments are rolled up to the new policy the next time they record a reading
+func (t *BlockchainIOT) setGPSRetention(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	retention := GPSRetention{}
+	err := json.Unmarshal([]byte(args[0]), &retention)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+	if validateErr := retention.validate(); validateErr != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: "+validateErr.Error())
+	}
+	retention.Asset_Type = defaultGPSRetention.Asset_Type
+
+	// Store in Blockchain
+	key, keyErr := gpsRetentionKey(stub)
+	if keyErr != nil {
+		return common.Error(http.StatusInternalServerError, keyErr.Error())
+	}
+	jsonBytes, _ := json.Marshal(retention)
+	if puterr := stub.PutState(key, jsonBytes); puterr != nil {
+		return common.Error(http.StatusInternalServerError, puterr.Error())
+	}
+	return common.Success(http.StatusCreated, "GPS Retention Updated", nil)
+}
+
+// Verify a GPS Reading against the Merkle root of a Segment - Arguments: {"ShipmentID", "Segment", "Reading", "Proof"}
+// The proof is the list of sibling hashes from the reading up to the root, as built by common.MerkleProof
+func (t *BlockchainIOT) verifyGPSReading(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		ShipmentID string             `json:"ShipmentID"`
+		Segment    int                `json:"Segment"`
+		Reading    GetGPSReading      `json:"Reading"`
+		Proof      []common.ProofStep `json:"Proof"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	// Check if Shipment Exists and Get Shipment
+	shipmentValue, shipmentGetErr := stub.GetState(common.Key(queryData.ShipmentID))
+	if shipmentGetErr != nil || shipmentValue == nil {
+		return common.Error(http.StatusNotFound, "Shipment Does Not Exists! \n Please Specify Another Shipment ID")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentValue, &shipment)
+	if queryData.Segment < 0 || queryData.Segment >= len(shipment.GPSSegments) {
+		return common.Error(http.StatusNotFound, "Segment Does Not Exist for this Shipment!")
+	}
+
+	segment := shipment.GPSSegments[queryData.Segment]
+	result := struct {
+		Verified bool       `json:"Verified"`
+		Segment  GPSSegment `json:"Segment"`
+	}{common.VerifyMerkleProof(gpsLeaf(queryData.Reading), queryData.Proof, segment.MerkleRoot), segment}
+	jsonBytes, _ := json.Marshal(result)
+	return common.Success(http.StatusOK, "OK", jsonBytes)
+}
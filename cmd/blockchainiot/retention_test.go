@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+func TestDownsampleKeepsTheEnds(t *testing.T) {
+	readings := []GetGPSReading{}
+	for index := 0; index < 10; index++ {
+		readings = append(readings, GetGPSReading{Latitude: float64(index)})
+	}
+	for count, expected := range map[int][]float64{1: {0}, 2: {0, 9}, 4: {0, 3, 6, 9}, 20: {0, 1, 2, 3, 4, 5, 6, 7, 8, 9}} {
+		result := downsample(readings, count)
+		if len(result) != len(expected) {
+			t.Fatalf("%d: unexpected readings %+v", count, result)
+		}
+		for index, element := range result {
+			if element.Latitude != expected[index] {
+				t.Fatalf("%d: unexpected readings %+v", count, result)
+			}
+		}
+	}
+}
+
+func TestGPSHistoryIsRolledUpIntoSegments(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+	for _, element := range []Tier{grower, importer} {
+		f.participant(element.ParticipantID, element.ParticipantType)
+		f.material(element.ParticipantID, element.MaterialID, "PRODUCT01")
+	}
+	f.produce("PRO1", grower.ParticipantID, grower.MaterialID, grower.BatchNumber, 40)
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{"POID": "PO1", "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID, "VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": grower.BatchNumber, "Quantity": 10, "UnitOfMeasure": "KG", "NetPrice": 10, "Currency": "USD"})
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createShipment", map[string]interface{}{"ShipmentID": "SH1", "ProductBCID": "PRODUCT01", "POID": "PO1", "UnsignedReadings": true})
+
+	f.mustInvoke(http.StatusForbidden, "setGPSRetention", map[string]int{"RawReadings": 4, "SegmentSize": 3, "TrackPoints": 2, "MaxTrackPoints": 4})
+	f.asAdmin().mustInvoke(http.StatusBadRequest, "setGPSRetention", map[string]int{"RawReadings": 4, "SegmentSize": 3, "TrackPoints": 2, "MaxTrackPoints": 1})
+	f.mustInvoke(http.StatusCreated, "setGPSRetention", map[string]int{"RawReadings": 4, "SegmentSize": 3, "TrackPoints": 2, "MaxTrackPoints": 4})
+
+	readings := []IngestReading{}
+	for minute := 0; minute < 10; minute++ {
+		readings = append(readings, IngestReading{ShipmentID: "SH1", Type: readingGPS, Timestamp: fmt.Sprintf("2020-01-01T10:%02d:00Z", minute), Latitude: float64(minute), Longitude: 1})
+	}
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "ingestReadings", map[string]interface{}{"Readings": readings})
+	shipment := f.getShipment("SH1")
+	if len(shipment.GPSReading) != 4 || shipment.GPSReading[0].Latitude != 6 || len(shipment.GPSSegments) != 2 || len(shipment.GPSTrack) != 4 {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+	if segment := shipment.GPSSegments[1]; segment.Index != 1 || segment.Count != 3 || segment.From != "2020-01-01T10:03:00Z" || segment.To != "2020-01-01T10:05:00Z" {
+		t.Fatalf("unexpected segment %+v", segment)
+	}
+
+	// Rolled up readings can no longer be replayed
+	report := IngestReport{}
+	json.Unmarshal(f.mustInvoke(http.StatusOK, "ingestReadings", map[string]interface{}{"Readings": readings[1:2]}).Payload, &report)
+	if len(report.Rejected) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	// The track is thinned out as segments are added
+	f.mustInvoke(http.StatusCreated, "trackShipment", map[string]interface{}{"ShipmentID": "SH1", "Latitude": 10, "Longitude": 1, "Timestamp": "2020-01-01T10:10:00Z"})
+	if shipment = f.getShipment("SH1"); len(shipment.GPSReading) != 2 || len(shipment.GPSSegments) != 3 || len(shipment.GPSTrack) != 3 {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+
+	// An off-chain store proves a rolled up reading against its segment
+	leaves := [][]byte{}
+	rolled := []GetGPSReading{}
+	for _, element := range readings[3:6] {
+		reading := GetGPSReading{ShipmentID: "SH1", Latitude: element.Latitude, Longitude: element.Longitude, Timestamp: element.Timestamp}
+		rolled = append(rolled, reading)
+		leaves = append(leaves, gpsLeaf(reading))
+	}
+	proof, _ := common.MerkleProof(leaves, 2)
+	verify := func(segment int, reading GetGPSReading) bool {
+		t.Helper()
+		result := struct{ Verified bool }{}
+		json.Unmarshal(f.mustInvoke(http.StatusOK, "verifyGPSReading", map[string]interface{}{"ShipmentID": "SH1", "Segment": segment, "Reading": reading, "Proof": proof}).Payload, &result)
+		return result.Verified
+	}
+	if !verify(1, rolled[2]) {
+		t.Fatalf("expected the reading verified against segment 1")
+	}
+	tampered := rolled[2]
+	tampered.Latitude = 50
+	if verify(1, tampered) || verify(0, rolled[2]) {
+		t.Fatalf("expected a tampered reading or another segment to fail verification")
+	}
+	f.mustInvoke(http.StatusNotFound, "verifyGPSReading", map[string]interface{}{"ShipmentID": "SH1", "Segment": 3})
+}
+
+func TestGPSRetentionIgnoresInvalidPolicies(t *testing.T) {
+	// A policy that keeps nothing leaves the readings as they are
+	shipment := Shipment{GPSReading: []GetGPSReading{{Latitude: 1}, {Latitude: 2}}}
+	shipment.retainGPS(GPSRetention{})
+	if len(shipment.GPSReading) != 2 || len(shipment.GPSSegments) != 0 {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+
+	// A Participant named after the flat key of the policy is not read as the policy
+	f := newFixture(t)
+	f.participant("GPSRETENTION", grower.ParticipantType)
+	if retention, err := getGPSRetention(f.stub); err != nil || retention != defaultGPSRetention {
+		t.Fatalf("unexpected retention %+v, error %v", retention, err)
+	}
+	key, _ := gpsRetentionKey(f.stub)
+	f.stub.MockTransactionStart("seed")
+	f.stub.PutState(key, []byte(`{"Asset_Type":"GPS RETENTION","RawReadings":0}`))
+	f.stub.MockTransactionEnd("seed")
+	if retention, err := getGPSRetention(f.stub); err != nil || retention != defaultGPSRetention {
+		t.Fatalf("expected the default policy, got %+v, error %v", retention, err)
+	}
+}
@@ -0,0 +1,108 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Shelf Life
+//****Description: Batches expiring within a number of days
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//********************************************************************************************************
+//Struct for Shelf Life
+//********************************************************************************************************
+
+//Batch expiring within the requested number of days
+type ExpiringBatch struct {
+	ProductBCID string    `json:"ProductBCID"`
+	Batch       BatchInfo `json:"Batch"`
+	Expired     bool      `json:"Expired"`
+}
+
+//expiringBatches lists the batches of the Materials holding Quantity and expiring within days of a time,
+//the first to expire first
+func expiringBatches(materials []Material, at time.Time, days int) []ExpiringBatch {
+	result := []ExpiringBatch{}
+	for _, material := range materials {
+		for _, element := range material.Batches {
+			if element.Quantity > 0 && element.ShelfLife().ExpiresWithin(at, days) {
+				result = append(result, ExpiringBatch{ProductBCID: material.ProductBCID, Batch: element, Expired: element.ShelfLife().Expired(at)})
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Batch.ExpiryDate < result[j].Batch.ExpiryDate })
+	return result
+}
+
+//********************************************************************************************************
+// Shelf Life Functions
+//********************************************************************************************************
+
+// Get Expiring Batches - Arguments: {"Days": n, "ParticipantID": optional}
+// Participants only see their own batches, already expired batches are listed as Expired
+func (t *BlockchainIOT) getExpiringBatches(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) < 1 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	type QueryData struct {
+		Days          int    `json:"Days"`
+		ParticipantID string `json:"ParticipantID,omitempty"`
+	}
+
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil || queryData.Days < 0 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Invalid Data - Check Payload")
+	}
+
+	txTime, timeErr := common.TxTime(stub)
+	if timeErr != nil {
+		return common.Error(http.StatusInternalServerError, timeErr.Error())
+	}
+	participantID, scopeErr := queryScope(stub)
+	if scopeErr != nil {
+		return common.Error(http.StatusForbidden, "Invoke Error: "+scopeErr.Error())
+	}
+
+	// Materials holding a batch that expires by the limit
+	limit := txTime.AddDate(0, 0, queryData.Days).Format(common.DateLayout)
+	query := common.TypedQuery{AssetType: "MATERIAL", Filters: map[string]interface{}{
+		"Batches": map[string]interface{}{"$elemMatch": map[string]interface{}{"ExpiryDate": map[string]interface{}{"$gt": "", "$lte": limit}}},
+	}}
+	if queryData.ParticipantID != "" {
+		query.Filters["ParticipantID"] = queryData.ParticipantID
+	}
+	selector, selectorErr := query.Selector(assetSchemas, participantID)
+	if selectorErr != nil {
+		return common.Error(http.StatusInternalServerError, selectorErr.Error())
+	}
+	queryResults, queryErr := common.QueryExecution(stub, selector)
+	if queryErr != nil {
+		return common.Error(http.StatusInternalServerError, queryErr.Error())
+	}
+
+	records := []struct {
+		Record Material `json:"Record"`
+	}{}
+	json.Unmarshal(queryResults, &records)
+	materials := []Material{}
+	for _, element := range records {
+		materials = append(materials, element.Record)
+	}
+
+	jsonBytes, _ := json.Marshal(expiringBatches(materials, txTime, queryData.Days))
+	return common.Success(http.StatusOK, "OK", jsonBytes)
+}
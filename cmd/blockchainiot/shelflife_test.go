@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestExpiringBatchesSortsByExpiry(t *testing.T) {
+	at := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	materials := []Material{
+		{ProductBCID: "P1", Batches: []BatchInfo{
+			{BatchNumber: "LATE", Quantity: 5, ExpiryDate: "2020-06-20T00:00:00Z"},
+			{BatchNumber: "EMPTY", Quantity: 0, ExpiryDate: "2020-05-01T00:00:00Z"},
+			{BatchNumber: "UNDATED", Quantity: 5},
+		}},
+		{ProductBCID: "P2", Batches: []BatchInfo{
+			{BatchNumber: "EXPIRED", Quantity: 5, ExpiryDate: "2020-05-01T00:00:00Z"},
+			{BatchNumber: "SOON", Quantity: 5, ExpiryDate: "2020-06-05T00:00:00Z"},
+		}},
+	}
+	result := expiringBatches(materials, at, 7)
+	if len(result) != 2 || result[0].Batch.BatchNumber != "EXPIRED" || !result[0].Expired || result[1].Batch.BatchNumber != "SOON" || result[1].Expired || result[1].ProductBCID != "P2" {
+		t.Fatalf("unexpected expiring batches %+v", result)
+	}
+	if result = expiringBatches(materials, at, 30); len(result) != 3 || result[2].Batch.BatchNumber != "LATE" {
+		t.Fatalf("unexpected expiring batches %+v", result)
+	}
+}
+
+func TestShelfLifeFollowsTheBatch(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+	for _, element := range []Tier{grower, importer} {
+		f.participant(element.ParticipantID, element.ParticipantType)
+		f.material(element.ParticipantID, element.MaterialID, "PRODUCT01")
+	}
+	receipt := func(status int32, orderID string, batchNumber string, dates map[string]interface{}) {
+		t.Helper()
+		payload := map[string]interface{}{"GRNumber": "GR-" + orderID, "ReceivedBy": grower.ParticipantID, "Against": "PRODUCTION ORDER", "POID": orderID, "BatchNumber": batchNumber}
+		for field, value := range dates {
+			payload[field] = value
+		}
+		f.mustInvoke(status, "submitGoodsReceipt", payload)
+	}
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "createProductionOrder", map[string]interface{}{"POID": "PRO1", "ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "Quantity": 40, "UnitOfMeasure": "KG"})
+	receipt(http.StatusBadRequest, "PRO1", "FRESH", map[string]interface{}{"ProductionDate": "01/02/2099"})
+	receipt(http.StatusBadRequest, "PRO1", "FRESH", map[string]interface{}{"ProductionDate": "2099-01-10", "ExpiryDate": "2099-01-01"})
+	receipt(http.StatusCreated, "PRO1", "FRESH", map[string]interface{}{"ProductionDate": "2099-01-01", "ShelfLifeDays": 14})
+	if batch := f.batch(grower.ParticipantID, grower.MaterialID, "FRESH"); batch.ProductionDate != "2099-01-01T00:00:00Z" || batch.ExpiryDate != "2099-01-15T00:00:00Z" {
+		t.Fatalf("expected the expiry derived from the shelf life %+v", batch)
+	}
+	f.mustInvoke(http.StatusCreated, "createProductionOrder", map[string]interface{}{"POID": "PRO2", "ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "Quantity": 10, "UnitOfMeasure": "KG"})
+	receipt(http.StatusCreated, "PRO2", "OLD", map[string]interface{}{"ProductionDate": "2000-01-01", "ExpiryDate": "2000-01-10"})
+
+	// The dates travel with the goods to the receiving batch
+	f.trade("PO1", Tier{grower.ParticipantID, grower.ParticipantType, grower.MaterialID, "FRESH"}, Tier{importer.ParticipantID, importer.ParticipantType, importer.MaterialID, "IMP-FRESH"}, 15)
+	if batch := f.batch(importer.ParticipantID, importer.MaterialID, "IMP-FRESH"); batch.ExpiryDate != "2099-01-15T00:00:00Z" || batch.ProductionDate != "2099-01-01T00:00:00Z" {
+		t.Fatalf("expected the dates inherited from the vendor batch %+v", batch)
+	}
+
+	// An expired batch cannot be shipped
+	f.as(importer.ParticipantID).mustInvoke(http.StatusCreated, "createPurchaseOrder", map[string]interface{}{"POID": "PO2", "RequestorID": importer.ParticipantID, "RequestorMaterialID": importer.MaterialID, "VendorID": grower.ParticipantID, "VendorMaterialID": grower.MaterialID, "VendorBatchNumber": "OLD", "Quantity": 5, "UnitOfMeasure": "KG", "NetPrice": 10, "Currency": "USD"})
+	f.as(grower.ParticipantID).mustInvoke(http.StatusBadRequest, "createShipment", map[string]string{"ShipmentID": "SH-PO2", "ProductBCID": "PRODUCT01", "POID": "PO2"})
+
+	f.mustInvoke(http.StatusBadRequest, "getExpiringBatches", map[string]interface{}{"Days": -1})
+}
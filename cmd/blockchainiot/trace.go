@@ -0,0 +1,143 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode - Batch Trace
+//****Description: Forward and backward genealogy of a batch built from the lineage edges
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//Maximum number of levels of a trace tree
+const defaultTraceDepth = 64
+
+//********************************************************************************************************
+//Struct for Batch Trace
+//********************************************************************************************************
+
+//Node of a genealogy tree. The root holds the traced batch with its current Quantity,
+//every other node holds the Quantity, orders and GoodsReceipts of the edge from its parent.
+//The contamination status of every node is the current status of its batch.
+//A batch is expanded once per trace: any other node of the batch is a Reference to it, without Children.
+type TraceNode struct {
+	Batch     BatchTradeInfo `json:"Batch"`
+	Reference bool           `json:"Reference,omitempty"` // The Children of the batch are under its first node
+	Truncated bool           `json:"Truncated,omitempty"` // The batch has Children beyond the depth limit
+	Children  []TraceNode    `json:"Children,omitempty"`
+}
+
+//batchStatus sets the contamination status of a batch from its Material, the edges do not follow
+//contamination reports
+func batchStatus(stub shim.ChaincodeStubInterface, materials *materialSet, batch BatchTradeInfo) (BatchTradeInfo, error) {
+	info := BatchInfo{}
+	material, err := materials.load(stub, common.Key(batch.ParticipantID, batch.MaterialID))
+	if err != nil {
+		return batch, err
+	}
+	if material != nil {
+		if index := findBatch(material, batch.BatchNumber); index >= 0 {
+			info = material.Batches[index]
+		}
+	}
+	batch.IsCompromised = info.IsCompromised
+	batch.PotentialCompromised = info.PotentialCompromised
+	return batch, nil
+}
+
+//buildTrace expands a node along the lineage edges of its batch. Every batch expanded in the trace
+//is remembered, so cyclic lineage and batches reached along several paths are only expanded once
+//and the tree grows with the number of edges instead of the number of paths.
+func buildTrace(stub shim.ChaincodeStubInterface, batch BatchTradeInfo, forward bool, materials *materialSet, expanded map[string]bool, depth int) (TraceNode, error) {
+	node := TraceNode{Batch: batch}
+	key := batchKey(batch.ParticipantID, batch.MaterialID, batch.BatchNumber)
+	if expanded[key] {
+		node.Reference = true
+		return node, nil
+	}
+	batches, err := neighbours(stub, batch.ParticipantID, batch.MaterialID, batch.BatchNumber, forward)
+	if err != nil {
+		return node, err
+	}
+	if depth >= defaultTraceDepth {
+		node.Truncated = len(batches) > 0
+		return node, nil
+	}
+	expanded[key] = true
+	for _, element := range batches {
+		if element, err = batchStatus(stub, materials, element); err != nil {
+			return node, err
+		}
+		child, err := buildTrace(stub, element, forward, materials, expanded, depth+1)
+		if err != nil {
+			return node, err
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
+
+//trace resolves the batch given as (participant, material, batch) and returns its genealogy tree
+func trace(stub shim.ChaincodeStubInterface, args []string, forward bool) peer.Response {
+	if len(args) < 3 {
+		return common.Error(http.StatusBadRequest, "Invoke Error: Incorrect number of arguments - Three Arguments expected (Participant, Material, Batch)")
+	}
+
+	// Get Material
+	materialValue, materialGetErr := stub.GetState(common.Key(args[0], args[1]))
+	if materialGetErr != nil || materialValue == nil {
+		return common.Error(http.StatusNotFound, "Material Does Not Exist! Please Check Participant ID and Material ID!")
+	}
+	material := Material{}
+	json.Unmarshal(materialValue, &material)
+
+	// Get the Batch
+	root := BatchTradeInfo{}
+	batchExists := false
+	for _, element := range material.Batches {
+		if strings.ToLower(element.BatchNumber) == strings.ToLower(args[2]) {
+			root.ParticipantID = element.ParticipantID
+			root.MaterialID = element.MaterialID
+			root.BatchNumber = element.BatchNumber
+			root.SerialNumbers = element.SerialNumbers
+			root.Quantity = element.Quantity
+			root.IsCompromised = element.IsCompromised
+			root.PotentialCompromised = element.PotentialCompromised
+			batchExists = true
+			break
+		}
+	}
+	if batchExists == false {
+		return common.Error(http.StatusNotFound, "Batch Does Not Exist for this Material!")
+	}
+
+	tree, err := buildTrace(stub, root, forward, newMaterialSet(), map[string]bool{}, 0)
+	if err != nil {
+		return common.Error(http.StatusInternalServerError, err.Error())
+	}
+	jsonBytes, _ := json.Marshal(tree)
+	return common.Success(http.StatusOK, "OK", jsonBytes)
+}
+
+//********************************************************************************************************
+// Trace Functions
+//********************************************************************************************************
+
+// Trace Forward - where did the batch go
+func (t *BlockchainIOT) traceForward(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	return trace(stub, args, true)
+}
+
+// Trace Backward - where did the batch come from
+func (t *BlockchainIOT) traceBackward(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	return trace(stub, args, false)
+}
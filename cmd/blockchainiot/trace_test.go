@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func (f *fixture) trace(function string, tier Tier) TraceNode {
+	f.t.Helper()
+	node := TraceNode{}
+	res := f.mustInvoke(http.StatusOK, function, tier.ParticipantID, tier.MaterialID, tier.BatchNumber)
+	if err := json.Unmarshal(res.Payload, &node); err != nil {
+		f.t.Fatalf("Cannot decode trace: %s", err)
+	}
+	return node
+}
+
+func TestTraceForwardBuildsGenealogyTree(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer, distributor)
+
+	// Split the distributor batch between two retailer batches
+	f.participant(retailer.ParticipantID, retailer.ParticipantType)
+	f.material(retailer.ParticipantID, retailer.MaterialID, "PRODUCT01")
+	f.trade("PO-RB1", distributor, retailer, 60)
+	f.trade("PO-RB2", distributor, Tier{retailer.ParticipantID, retailer.ParticipantType, retailer.MaterialID, "RB2"}, 40)
+
+	root := f.trace("traceForward", grower)
+	if root.Batch.BatchNumber != grower.BatchNumber || root.Batch.Quantity != 0 || len(root.Children) != 1 {
+		t.Fatalf("unexpected root %+v", root)
+	}
+	edge := root.Children[0]
+	if edge.Batch.BatchNumber != importer.BatchNumber || edge.Batch.Quantity != 100 ||
+		len(edge.Batch.PurchaseOrders) != 1 || edge.Batch.PurchaseOrders[0] != "PO-IB1" || edge.Batch.GoodsReceipts[0] != "GR-PO-IB1" {
+		t.Fatalf("unexpected importer edge %+v", edge.Batch)
+	}
+	leaves := edge.Children[0].Children
+	if len(leaves) != 2 || leaves[0].Batch.Quantity != 60 || leaves[1].Batch.Quantity != 40 || leaves[1].Batch.PurchaseOrders[0] != "PO-RB2" {
+		t.Fatalf("unexpected retailer edges %+v", leaves)
+	}
+}
+
+func TestTraceBackwardStopsOnCycles(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer, distributor)
+	f.trade("PO-CYCLE", distributor, importer, 10)
+
+	root := f.trace("traceBackward", importer)
+	if len(root.Children) != 2 {
+		t.Fatalf("expected grower and distributor as sources %+v", root)
+	}
+	for _, element := range root.Children {
+		if element.Batch.ParticipantID == distributor.ParticipantID {
+			if element.Batch.Quantity != 10 || len(element.Children) != 1 || !element.Children[0].Reference || len(element.Children[0].Children) != 0 {
+				t.Fatalf("cycle should end at the importer batch %+v", element)
+			}
+		}
+	}
+
+	f.mustInvoke(http.StatusNotFound, "traceBackward", importer.ParticipantID, importer.MaterialID, "NOPE")
+	f.mustInvoke(http.StatusBadRequest, "traceForward", importer.ParticipantID)
+}
+
+func TestTraceExpandsSharedBatchesOnce(t *testing.T) {
+	f := newFixture(t)
+	f.product("PRODUCT01")
+	for _, element := range []Tier{grower, importer, distributor} {
+		f.participant(element.ParticipantID, element.ParticipantType)
+		f.material(element.ParticipantID, element.MaterialID, "PRODUCT01")
+	}
+	f.produce("PRO1", grower.ParticipantID, grower.MaterialID, grower.BatchNumber, 100)
+
+	// Both importer batches of the grower batch end in the same distributor batch
+	second := Tier{importer.ParticipantID, importer.ParticipantType, importer.MaterialID, "IB2"}
+	f.trade("PO-IB1", grower, importer, 60)
+	f.trade("PO-IB2", grower, second, 40)
+	f.trade("PO-DB1", importer, distributor, 60)
+	f.trade("PO-DB2", second, distributor, 40)
+
+	root := f.trace("traceForward", grower)
+	if len(root.Children) != 2 {
+		t.Fatalf("expected both importer batches %+v", root)
+	}
+	expansions, references := 0, 0
+	for _, element := range root.Children {
+		if len(element.Children) != 1 || element.Children[0].Batch.BatchNumber != distributor.BatchNumber {
+			t.Fatalf("expected the distributor batch under %+v", element)
+		}
+		if element.Children[0].Reference {
+			references++
+		} else {
+			expansions++
+		}
+	}
+	if expansions != 1 || references != 1 {
+		t.Fatalf("the distributor batch must be expanded once and referenced once %+v", root)
+	}
+}
+
+func TestTraceReportsCurrentBatchStatus(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer, distributor)
+	f.as(grower.ParticipantID).mustInvoke(http.StatusCreated, "reportContamination", map[string]string{"ParticipantID": grower.ParticipantID, "MaterialID": grower.MaterialID, "BatchNumber": grower.BatchNumber})
+
+	root := f.trace("traceForward", grower)
+	if !root.Batch.IsCompromised || len(root.Children) != 1 || !root.Children[0].Batch.IsCompromised || !root.Children[0].Children[0].Batch.IsCompromised {
+		t.Fatalf("expected every batch downstream compromised %+v", root)
+	}
+	source := f.trace("traceBackward", distributor)
+	if !source.Children[0].Batch.IsCompromised || !source.Children[0].Children[0].Batch.IsCompromised {
+		t.Fatalf("expected the sources compromised %+v", source)
+	}
+}
+
+func TestTraceMarksTruncatedNodes(t *testing.T) {
+	f := newFixture(t)
+	f.supplyChain("PRODUCT01", 100, grower, importer, distributor)
+
+	// One level below the depth limit the children are not expanded
+	root := BatchTradeInfo{ParticipantID: grower.ParticipantID, MaterialID: grower.MaterialID, BatchNumber: grower.BatchNumber}
+	node, err := buildTrace(f.stub, root, true, newMaterialSet(), map[string]bool{}, defaultTraceDepth-1)
+	if err != nil || node.Truncated || len(node.Children) != 1 {
+		t.Fatalf("unexpected root %+v (%v)", node, err)
+	}
+	if child := node.Children[0]; !child.Truncated || len(child.Children) != 0 {
+		t.Fatalf("expected the importer batch truncated %+v", child)
+	}
+
+	// A batch without further edges is complete at the limit
+	leaf := BatchTradeInfo{ParticipantID: distributor.ParticipantID, MaterialID: distributor.MaterialID, BatchNumber: distributor.BatchNumber}
+	if node, _ := buildTrace(f.stub, leaf, true, newMaterialSet(), map[string]bool{}, defaultTraceDepth); node.Truncated {
+		t.Fatalf("a leaf is not truncated %+v", node)
+	}
+}
@@ -0,0 +1,2872 @@
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Blockchain IoT Chaincode
+//****Description: Chaincode logic for IoT Enabled Blockchain for Supply Chain of Perishable Goods
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package main
+
+//Importing 3 libraries for encoding, reading and writing JSON and string manipulation and formatting.
+//Importing 2 Hyperledger Specific Libraries for Smart Contract and the shared chaincode library.
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+//Define the Smart Contract structure.
+type Testing1 struct {
+}
+
+//Dividing the keys stored inside Blockchain into 3 main categories:
+//1. Participants
+//2. Assets
+//3. Transactions
+
+//*****************************
+// 1. PARTICIPANTS STRUCTS
+//*****************************
+
+//Define the Participant structure, with 7 properties.
+//Structure tags are used by encoding/json library.
+type Participant struct {
+	Asset_Type      string `json:"Asset_Type"`
+	ParticipantID   string `json:"ParticipantID"`
+	ParticipantType string `json:"ParticipantType"`
+	OrgName         string `json:"OrgName"`
+	Email           string `json:"Email"`
+	MSPID           string `json:"MSPID"`
+	EnrollmentID    string `json:"EnrollmentID"`
+}
+
+//Define the Participant Identity structure, binding an X.509 identity to a Participant.
+//Stored under the PARTICIPANT namespace, keyed by MSP ID and enrollment attribute.
+type ParticipantIdentity struct {
+	Asset_Type    string `json:"Asset_Type,omitempty"`
+	MSPID         string `json:"MSPID"`
+	EnrollmentID  string `json:"EnrollmentID"`
+	ParticipantID string `json:"ParticipantID"`
+}
+
+//*****************************
+// 2. Assets STRUCTS
+//*****************************
+
+//Define the Material structure, with XXX properties.
+//Structure tags are used by encoding/json library.
+type Material struct {
+	Asset_Type           string                    `json:"Asset_Type"`
+	MaterialID           string                    `json:"MaterialID"`
+	OpenPurchaseOrders   []MaterialPurchaseOrder   `json:"OpenPurchaseOrders"`
+	ClosedPurchaseOrders []MaterialPurchaseOrder   `json:"ClosedPurchaseOrders"`
+	ProductionOrders     []MaterialProductionOrder `json:"ProductionOrders"`
+	ActiveBatches        []MaterialBatches         `json:"ActiveBatches"`
+	Batches              []MaterialBatches         `json:"Batches"`
+	TemperatureLimits    common.TemperatureLimits  `json:"TemperatureLimits"`
+}
+
+type MaterialPurchaseOrder struct {
+	PurchaseOrderID       string                         `json:"PurchaseOrderID"`
+	Owner                 string                         `json:"Owner"`
+	AssociatedSalesOrders []MaterialAssociatedSalesOrder `json:"AssociatedSalesOrders"`
+	Deleted               bool                           `json:"Deleted"`
+}
+
+type MaterialAssociatedSalesOrder struct {
+	SalesOrderID string `json:"SalesOrderID"`
+	Owner        string `json:"Owner"`
+	Deleted      bool   `json:"Deleted"`
+}
+
+type MaterialProductionOrder struct {
+	ProductionOrderID string `json:"ProductionOrderID"`
+	Owner             string `json:"Owner"`
+	Deleted           bool   `json:"Deleted"`
+}
+
+type MaterialBatches struct {
+	BatchNumber string `json:"BatchNumber"`
+	Owner       string `json:"Owner"`
+	Deleted     bool   `json:"Deleted"`
+}
+
+//Define the Purchase Order structure, with XXX properties.
+//Structure tags are used by encoding/json library.
+type PurchaseOrder struct {
+	Asset_Type      string                  `json:"Asset_Type"`
+	PurchaseOrderID string                  `json:"PurchaseOrderID"`
+	Owner           string                  `json:"Owner"`
+	Vendor          string                  `json:"Vendor"`
+	LineItems       []PurchaseOrderLineItem `json:"LineItems"`
+	Status          string                  `json:"Status"`
+	TargetBatch     string                  `json:"TargetBatch"`
+}
+
+type PurchaseOrderLineItem struct {
+	LineItemNumber string `json:"LineItemNumber"`
+	MaterialID     string `json:"MaterialID"`
+	Quantity       int    `json:"Quantity"`
+}
+
+//Define the Sales Order structure, with XXX properties.
+//Structure tags are used by encoding/json library.
+type SalesOrder struct {
+	Asset_Type     string               `json:"Asset_Type"`
+	SalesOrderID   string               `json:"SalesOrderID"`
+	Owner          string               `json:"Owner"`
+	POReference    string               `json:"POReference"`
+	POOwner        string               `json:"POOwner"`
+	LineItems      []SalesOrderLineItem `json:"LineItems"`
+	DeliveryNumber string               `json:"DeliveryNumber"`
+	Status         string               `json:"Status"`
+}
+
+type SalesOrderLineItem struct {
+	LineItemNumber string `json:"LineItemNumber"`
+	MaterialID     string `json:"MaterialID"`
+	Quantity       int    `json:"Quantity"`
+}
+
+//Define the Batch structure, with XXX properties.
+//Structure tags are used by encoding/json library.
+type Batch struct {
+	Asset_Type           string              `json:"Asset_Type,omitempty"`
+	BatchNumber          string              `json:"BatchNumber"`
+	MaterialID           string              `json:"MaterialID"`
+	Owner                string              `json:"Owner"`
+	Plant                string              `json:"Plant"`
+	StorageLocation      string              `json:"StorageLocation"`
+	AvailableQuantity    int                 `json:"AvailableQuantity"`
+	HandlingUnits        []BatchHandlingUnit `json:"HandlingUnits,omitempty"`
+	Status               string              `json:"Status"`
+	PotentialCompromised bool                `json:"PotentialCompromised"`
+	ProductionDate       string              `json:"ProductionDate,omitempty"`
+	ExpiryDate           string              `json:"ExpiryDate,omitempty"`
+	ShelfLifeDays        int                 `json:"ShelfLifeDays,omitempty"`
+}
+
+//Dates of a Batch
+func (batch Batch) ShelfLife() common.ShelfLife {
+	return common.ShelfLife{ProductionDate: batch.ProductionDate, ExpiryDate: batch.ExpiryDate, ShelfLifeDays: batch.ShelfLifeDays}
+}
+
+func (batch *Batch) SetShelfLife(life common.ShelfLife) {
+	batch.ProductionDate = life.ProductionDate
+	batch.ExpiryDate = life.ExpiryDate
+	batch.ShelfLifeDays = life.ShelfLifeDays
+}
+
+type BatchHandlingUnit struct {
+	HUID           string `json:"HUID"`
+	Quantity       int    `json:"Quantity"`
+	DeliveryNumber string `json:"DeliveryNumber"`
+}
+
+//Define the Production Order structure, with XXX properties.
+//Structure tags are used by encoding/json library.
+type ProductionOrder struct {
+	Asset_Type        string `json:"Asset_Type"`
+	ProductionOrderID string `json:"ProductionOrderID"`
+	MaterialID        string `json:"MaterialID"`
+	Owner             string `json:"Owner"`
+	Quantity          int    `json:"Quantity"`
+	TargetBatch       string `json:"TargetBatch"`
+}
+
+//Define the Delivery Document structure, with XXX properties.
+//Structure tags are used by encoding/json library.
+type Delivery struct {
+	Asset_Type     string             `json:"Asset_Type"`
+	DeliveryNumber string             `json:"DeliveryNumber"`
+	SalesOrderID   string             `json:"SalesOrderID"`
+	Owner          string             `json:"Owner"`
+	LineItems      []DeliveryLineItem `json:"LineItems"`
+	Shipments      []string           `json:"Shipments"`
+}
+
+type DeliveryLineItem struct {
+	LineItemNumber string `json:"LineItemNumber"`
+	MaterialID     string `json:"MaterialID"`
+	Quantity       int    `json:"Quantity"`
+	SourceBatch    string `json:"SourceBatch"`
+}
+
+//Define the Shipment structure, with XXX properties.
+//Structure tags are used by encoding/json library.
+type Shipment struct {
+	Asset_Type       string                 `json:"Asset_Type,omitempty"`
+	ShipmentID       string                 `json:"ShipmentID"`
+	Owner            string                 `json:"Owner"`
+	DeliveryNumber   string                 `json:"DeliveryNumber"`
+	SalesOrderID     string                 `json:"SalesOrderID,omitempty"`
+	Status           string                 `json:"Status"`
+	SensorReadings   []common.SensorReading `json:"SensorReadings,omitempty"`
+	Excursions       []common.Excursion     `json:"Excursions,omitempty"`
+	Devices          []string               `json:"Devices,omitempty"`
+	UnsignedReadings bool                   `json:"UnsignedReadings,omitempty"` //Readings without a Device signature are accepted until a Device is attached
+}
+
+//Define the Device structure, with XXX properties.
+//Structure tags are used by encoding/json library.
+type Device struct {
+	Asset_Type string   `json:"Asset_Type,omitempty"`
+	DeviceID   string   `json:"DeviceID"`
+	Owner      string   `json:"Owner"`
+	KeyType    string   `json:"KeyType"`
+	PublicKey  string   `json:"PublicKey"`
+	Shipments  []string `json:"Shipments,omitempty"`
+	Sequence   uint64   `json:"Sequence"`
+}
+
+// Main function (only used for Unit Testing)
+func main() {
+	if err := shim.Start(new(Testing1)); err != nil {
+		fmt.Printf("Error starting Testing1 chaincode: %s", err)
+	}
+}
+
+//Initializing new logger for logging objects used by chaincode
+var logger = shim.NewLogger("Testing1")
+
+//The Init method is called when the Smart Contract "Testing1" is instantiated by the blockchain network
+func (t *Testing1) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	//Retrieves the arguments when instantiating chaincode
+	_, args := stub.GetFunctionAndParameters()
+	//Checks and returns error if any argument exists at the time of chaincode instantiation
+	if len(args) > 0 {
+		return shim.Error("Init Error: Incorrect number of arguments - NO ARGUMENT EXPECTED")
+	}
+	return shim.Success(nil)
+}
+
+//The Invoke method is called as a result of an application request to run the Smart Contract "Testing1".
+//The calling application program must specify the particular smart contract function to be called, with arguments.
+func (t *Testing1) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	//Get the requested Smart Contract function and arguments
+	function, args := stub.GetFunctionAndParameters()
+
+	// Route to the appropriate handler function to interact with the ledger appropriately
+	switch function {
+	case "createParticipant":
+		return t.createParticipant(stub, args)
+	case "getParticipant":
+		return t.getParticipant(stub, args)
+	case "deleteParticipant":
+		return t.deleteParticipant(stub, args)
+	case "createPurchaseOrder":
+		return t.createPurchaseOrder(stub, args)
+	case "getPurchaseOrder":
+		return t.getPurchaseOrder(stub, args)
+	case "deletePurchaseOrder":
+		return t.deletePurchaseOrder(stub, args)
+	case "reportProductionOrderGR":
+		return t.reportProductionOrderGR(stub, args)
+	case "getProductionOrder":
+		return t.getProductionOrder(stub, args)
+	case "deleteProductionOrder":
+		return t.deleteProductionOrder(stub, args)
+	case "getBatch":
+		return t.getBatch(stub, args)
+	case "deleteBatch":
+		return t.deleteBatch(stub, args)
+	case "createSalesOrder":
+		return t.createSalesOrder(stub, args)
+	case "getSalesOrder":
+		return t.getSalesOrder(stub, args)
+	case "deleteSalesOrder":
+		return t.deleteSalesOrder(stub, args)
+	case "createDelivery":
+		return t.createDelivery(stub, args)
+	case "getDelivery":
+		return t.getDelivery(stub, args)
+	case "deleteDelivery":
+		return t.deleteDelivery(stub, args)
+	case "createShipment":
+		return t.createShipment(stub, args)
+	case "getShipment":
+		return t.getShipment(stub, args)
+	case "deleteShipment":
+		return t.deleteShipment(stub, args)
+	case "reportPurchaseOrderGR":
+		return t.reportPurchaseOrderGR(stub, args)
+	case "getMaterial":
+		return t.getMaterial(stub, args)
+	case "deleteMaterial":
+		return t.deleteMaterial(stub, args)
+	case "getHistory":
+		return t.getHistory(stub, args)
+	case "customQueries":
+		return t.customQueries(stub, args)
+	case "listPurchaseOrders":
+		return t.listPurchaseOrders(stub, args)
+	case "listProductionOrders":
+		return t.listProductionOrders(stub, args)
+	case "listBatches":
+		return t.listBatches(stub, args)
+	case "listSalesOrders":
+		return t.listSalesOrders(stub, args)
+	case "listDeliveries":
+		return t.listDeliveries(stub, args)
+	case "listShipments":
+		return t.listShipments(stub, args)
+	case "listMaterials":
+		return t.listMaterials(stub, args)
+	case "listExpiringBatches":
+		return t.listExpiringBatches(stub, args)
+	case "setTemperatureLimits":
+		return t.setTemperatureLimits(stub, args)
+	case "recordSensorReading":
+		return t.recordSensorReading(stub, args)
+	case "registerDevice":
+		return t.registerDevice(stub, args)
+	case "attachDevice":
+		return t.attachDevice(stub, args)
+	case "migrateKeys":
+		return t.migrateKeys(stub, args)
+	default:
+		logger.Warningf("Invalid Function Call - Function '%s' does not exist", function)
+		return shim.Error("Invoke Error: Invalid Function Call - Function does not exist")
+	}
+}
+
+//Function Definitions
+
+// CASE 01 Create a Participant
+func (t *Testing1) createParticipant(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		ParticipantID   string `json:"ParticipantID"`
+		ParticipantType string `json:"ParticipantType"`
+		OrgName         string `json:"OrgName"`
+		Email           string `json:"Email"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil || queryData.ParticipantID == "" {
+		return shim.Error("Invoke Error (Create Participant):  Invalid Data - Check Payload")
+	}
+	//Get the X.509 identity enrolling the Participant
+	identity, iderr := getCreatorIdentity(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Create Participant): Invalid Identity - " + iderr.Error())
+	}
+	participantID := queryData.ParticipantID
+	//Define Namespace
+	namespace := "PARTICIPANT"
+
+	//Define a new Participant
+	participant := Participant{}
+	participant.Asset_Type = namespace
+	participant.ParticipantID = participantID
+	participant.ParticipantType = queryData.ParticipantType
+	participant.OrgName = queryData.OrgName
+	participant.Email = queryData.Email
+	participant.MSPID = identity.MSPID
+	participant.EnrollmentID = identity.EnrollmentID
+
+	//Define the binding between the identity and the Participant
+	participantIdentity := ParticipantIdentity{}
+	participantIdentity.Asset_Type = namespace
+	participantIdentity.MSPID = identity.MSPID
+	participantIdentity.EnrollmentID = identity.EnrollmentID
+	participantIdentity.ParticipantID = participantID
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, participantID)
+	identitykeystring := identityKey(stub, identity)
+
+	//Check if Participant already exists.
+	if value, geterr := getState(stub, keystring); !(geterr == nil && value == nil) {
+		return shim.Error("Invoke Error (Create Participant): Participant Already Exists! Please Specify Another ID")
+	}
+
+	//Check if the identity is already bound to another Participant.
+	if value, geterr := getState(stub, identitykeystring); !(geterr == nil && value == nil) {
+		return shim.Error("Invoke Error (Create Participant): Identity Already Enrolled as a Participant!")
+	}
+
+	//Store Participant in Blockchain
+	jsonBytes, _ := json.Marshal(participant) //Get Bytes from struct
+	if puterr := putState(stub, keystring, jsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Create Participant): Error while storing data into Blockchain")
+	}
+	//Store Participant Identity in Blockchain
+	identityJsonBytes, _ := json.Marshal(participantIdentity) //Get Bytes from struct
+	if puterr := putState(stub, identitykeystring, identityJsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Create Participant - Identity): Error while storing data into Blockchain")
+	}
+	return shim.Success(nil)
+}
+
+// CASE 02 Get a Participant Info
+func (t *Testing1) getParticipant(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+	//Get Data
+	data := string(args[0])
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	if _, iderr := getInvokingParticipant(stub); iderr != nil {
+		return shim.Error("Invoke Error (Get Participant): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "PARTICIPANT"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, data)
+
+	//Get the Asset from Blockchain
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Get Participant): Error while fetching data from Blockchain")
+	}
+	return shim.Success(value)
+}
+
+// CASE 03 Delete a Participant
+func (t *Testing1) deleteParticipant(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+	//Get Data
+	data := string(args[0])
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Delete Participant): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "PARTICIPANT"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, data)
+
+	//Check if Asset exists and get the Asset.
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Delete Participant): Error while fetching data from Blockchain")
+	}
+	participant := Participant{}
+	json.Unmarshal(value, &participant)
+
+	//Check if Invoking Participant is authorised for Delete
+	if strings.ToLower(participantID) == strings.ToLower(participant.ParticipantID) {
+		// Delete if Exists
+		if delerr := delState(stub, keystring); delerr != nil {
+			return shim.Error("Invoke Error (Delete Participant): Error while deleting data from Blockchain")
+		}
+		// Delete the Identity binding
+		identity := common.Identity{MSPID: participant.MSPID, EnrollmentID: participant.EnrollmentID}
+		if delerr := delState(stub, identityKey(stub, identity)); delerr != nil {
+			return shim.Error("Invoke Error (Delete Participant - Identity): Error while deleting data from Blockchain")
+		}
+		return shim.Success(nil)
+	} else {
+		return shim.Error("Invoke Error (Delete Participant): Not Authorized to Delete Participant")
+	}
+}
+
+// CASE 04 Create a Purchase Order
+func (t *Testing1) createPurchaseOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		PurchaseOrderID string `json:"PurchaseOrderID"`
+		Vendor          string `json:"Vendor"`
+		LineItemNumber  string `json:"LineItemNumber"`
+		MaterialID      string `json:"MaterialID"`
+		Quantity        int    `json:"Quantity"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Create Purchase Order):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Create Purchase Order): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "PURCHASEORDER"
+
+	//One Assets will be created:
+	//(1) Purchase Order
+	//One Asset will be updated:
+	//(1) Material
+
+	//Define a new Purchase Order and Line Item.
+	//Line Item
+	poLineItem := PurchaseOrderLineItem{}
+	poLineItem.LineItemNumber = queryData.LineItemNumber
+	poLineItem.MaterialID = queryData.MaterialID
+	poLineItem.Quantity = queryData.Quantity
+	//Purchase Order
+	purchaseOrder := PurchaseOrder{}
+	purchaseOrder.Asset_Type = namespace
+	purchaseOrder.PurchaseOrderID = queryData.PurchaseOrderID
+	purchaseOrder.Owner = participantID
+	purchaseOrder.Vendor = queryData.Vendor
+	purchaseOrder.Status = "OPEN"
+	purchaseOrder.LineItems = append(purchaseOrder.LineItems, poLineItem)
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, participantID, queryData.PurchaseOrderID)
+
+	//Check If PO already exists.
+	if value, geterr := getState(stub, keystring); !(geterr == nil && value == nil) {
+		return shim.Error("Invoke Error (Create Purchase Order): PO Already Exists! Please Specify Another ID")
+	}
+
+	//**************************************************
+	//Updating new PO information inside Material Asset
+	//**************************************************
+	//Check If Material Exists and get Material, else create new Material
+	matNamespace := "MATERIAL"
+	matkeystring := assetKey(stub, matNamespace, queryData.MaterialID)
+	matValue, matGetErr := getState(stub, matkeystring)
+	if matGetErr != nil || matValue == nil {
+		//If Material does not exist,
+		//Create New Material
+		material := Material{}
+		material.Asset_Type = matNamespace
+		material.MaterialID = queryData.MaterialID
+
+		//Define new Open Purchase Order information for Material
+		matOpenPO := MaterialPurchaseOrder{}
+		matOpenPO.PurchaseOrderID = queryData.PurchaseOrderID
+		matOpenPO.Owner = participantID
+		matOpenPO.Deleted = false
+		//Update Material with new Open PO information
+		material.OpenPurchaseOrders = append(material.OpenPurchaseOrders, matOpenPO)
+
+		// Store Material in Blockchain
+		matJsonBytes, _ := json.Marshal(material) //Get Bytes from struct
+		if puterr := putState(stub, matkeystring, matJsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (Create PO - Create Material): Error while storing data into Blockchain")
+		}
+		// Store Purchase Order in Blockchain
+		jsonBytes, _ := json.Marshal(purchaseOrder) //Get Bytes from struct
+		if puterr := putState(stub, keystring, jsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (Create Purchase Order): Error while storing data into Blockchain")
+		}
+		return shim.Success(nil)
+	} else {
+		//If Material already exists,
+		//Fetch Material from Blockchain
+		material := Material{}
+		json.Unmarshal(matValue, &material)
+
+		//Check if PO is Present in Material
+		// presentFlag := false
+		// for _, element := range material.OpenPurchaseOrders {
+		// 	if (strings.ToLower(element.PurchaseOrderID) == strings.ToLower(queryData.PurchaseOrderID)) && (strings.ToLower(element.Owner) == strings.ToLower(participantID)) && (element.Deleted == false) {
+		// 		presentFlag = true
+		// 		break
+		// 	}
+		// }
+		// if presentFlag == true {
+		// 	// Store Purchase Order in Blockchain
+		// 	jsonBytes, _ := json.Marshal(purchaseOrder) //Get Bytes from struct
+		// 	if puterr := putState(stub, keystring, jsonBytes); puterr != nil {
+		// 		return shim.Error("Invoke Error (Create Purchase Order): Error while storing data into Blockchain")
+		// 	}
+		// 	return shim.Success(nil)
+		// }
+
+		//Define new Open Purchase Order information for Material
+		matOpenPO := MaterialPurchaseOrder{}
+		matOpenPO.PurchaseOrderID = queryData.PurchaseOrderID
+		matOpenPO.Owner = participantID
+		matOpenPO.Deleted = false
+		//Update Material with new Open PO information
+		material.OpenPurchaseOrders = append(material.OpenPurchaseOrders, matOpenPO)
+
+		// Store Material in Blockchain
+		matJsonBytes, _ := json.Marshal(material) //Get Bytes from struct
+		if puterr := putState(stub, matkeystring, matJsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (Create PO - Update Material): Error while storing data into Blockchain")
+		}
+		// Store Purchase Order in Blockchain
+		jsonBytes, _ := json.Marshal(purchaseOrder) //Get Bytes from struct
+		if puterr := putState(stub, keystring, jsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (Create Purchase Order): Error while storing data into Blockchain")
+		}
+		return shim.Success(nil)
+	}
+}
+
+// CASE 05 Get a Purchase Order Info
+func (t *Testing1) getPurchaseOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		Owner           string `json:"Owner"`
+		PurchaseOrderID string `json:"PurchaseOrderID"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Get Purchase Order):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Get Purchase Order): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "PURCHASEORDER"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.Owner, queryData.PurchaseOrderID)
+
+	//Get the Asset from Blockchain, if the invoking Participant may read it
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil || !common.ReadableBy(assetSchemas, value, participantID) {
+		return shim.Error("Invoke Error (Get Purchase Order): Error while fetching data from Blockchain")
+	}
+	return shim.Success(value)
+}
+
+// CASE 06 Delete a Purchase Order
+func (t *Testing1) deletePurchaseOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		Owner           string `json:"Owner"`
+		PurchaseOrderID string `json:"PurchaseOrderID"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Delete Purchase Order):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Delete Purchase Order): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "PURCHASEORDER"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.Owner, queryData.PurchaseOrderID)
+
+	//Check if Asset exists and get the Asset.
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Delete Purchase Order): Error while fetching data from Blockchain")
+	}
+	purchaseOrder := PurchaseOrder{}
+	json.Unmarshal(value, &purchaseOrder)
+
+	//Check if Invoking Participant is authorised for Delete
+	if strings.ToLower(participantID) == strings.ToLower(purchaseOrder.Owner) {
+		// Delete if Exists
+		if delerr := delState(stub, keystring); delerr != nil {
+			return shim.Error("Invoke Error (Delete Purchase Order): Error while deleting data from Blockchain")
+		}
+		return shim.Success(nil)
+	} else {
+		return shim.Error("Invoke Error (Delete Purchase Order): Not Authorized to Delete Purchase Order")
+	}
+}
+
+// CASE 07 Report a Production Order Goods Receipt
+func (t *Testing1) reportProductionOrderGR(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		ProductionOrderID string `json:"ProductionOrderID"`
+		MaterialID        string `json:"MaterialID"`
+		Quantity          int    `json:"Quantity"`
+		Plant             string `json:"Plant"`
+		StorageLocation   string `json:"StorageLocation"`
+		BatchNumber       string `json:"BatchNumber"`
+		ProductionDate    string `json:"ProductionDate,omitempty"`
+		ExpiryDate        string `json:"ExpiryDate,omitempty"`
+		ShelfLifeDays     int    `json:"ShelfLifeDays,omitempty"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (GR Production Order):  Invalid Data - Check Payload")
+	}
+	//Validate the Dates, the Expiry Date is derived from the Shelf Life if not given
+	shelfLife, shelfLifeErr := common.ShelfLife{ProductionDate: queryData.ProductionDate, ExpiryDate: queryData.ExpiryDate, ShelfLifeDays: queryData.ShelfLifeDays}.Normalize()
+	if shelfLifeErr != nil {
+		return shim.Error("Invoke Error (GR Production Order):  Invalid Data - " + shelfLifeErr.Error())
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (GR Production Order): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	productionOrderNamespace := "PRODUCTIONORDER"
+	batchNamespace := "BATCH"
+
+	//One Assets will be created:
+	//(1) Production Order
+	//Two Asset will be updated:
+	//(1) Batch
+	//(2) Material
+
+	//Define a new Production Order.
+	productionOrder := ProductionOrder{}
+	productionOrder.Asset_Type = productionOrderNamespace
+	productionOrder.ProductionOrderID = queryData.ProductionOrderID
+	productionOrder.MaterialID = queryData.MaterialID
+	productionOrder.Owner = participantID
+	productionOrder.Quantity = queryData.Quantity
+	productionOrder.TargetBatch = queryData.BatchNumber
+
+	//Key for fetching/storing the Asset
+	productionorderkeystring := assetKey(stub, productionOrderNamespace, participantID, queryData.ProductionOrderID)
+	batchkeystring := assetKey(stub, batchNamespace, participantID, queryData.MaterialID, queryData.BatchNumber)
+
+	//Check If Production Order already exists.
+	if value, geterr := getState(stub, productionorderkeystring); !(geterr == nil && value == nil) {
+		return shim.Error("Invoke Error (GR Production Order): Production Order Already Exists! Please Specify Another ID")
+	}
+
+	// Check If Batch already Exists and get the Batch to update quantity, else create a new Batch.
+	batchValue, batchGetErr := getState(stub, batchkeystring)
+	batch := Batch{}
+	if batchGetErr != nil || batchValue == nil {
+		//If Batch does not exist,
+		//Create New Batch
+		batch.Asset_Type = batchNamespace
+		batch.BatchNumber = queryData.BatchNumber
+		batch.MaterialID = queryData.MaterialID
+		batch.Owner = participantID
+		batch.Plant = queryData.Plant
+		batch.StorageLocation = queryData.StorageLocation
+		batch.AvailableQuantity = queryData.Quantity
+		batch.Status = "OK"
+		batch.SetShelfLife(shelfLife)
+	} else {
+		//If Batch exists,
+		//Get the Batch, the earliest Expiry Date wins
+		json.Unmarshal(batchValue, &batch)
+		batch.AvailableQuantity += queryData.Quantity
+		batch.Plant = queryData.Plant
+		batch.StorageLocation = queryData.StorageLocation
+		batch.SetShelfLife(batch.ShelfLife().Earliest(shelfLife))
+	}
+
+	//****************************************************************
+	//Updating new Production Order information inside Material Asset
+	//****************************************************************
+	// Check If Material Exists and get Material, else create new Material
+	matNamespace := "MATERIAL"
+	matkeystring := assetKey(stub, matNamespace, queryData.MaterialID)
+	matValue, matGetErr := getState(stub, matkeystring)
+	if matGetErr != nil || matValue == nil {
+		//If Material does not exist,
+		//Create New Material
+		material := Material{}
+		material.Asset_Type = matNamespace
+		material.MaterialID = queryData.MaterialID
+
+		//Define new Material Production Order information for Material
+		matProdOrder := MaterialProductionOrder{}
+		matProdOrder.ProductionOrderID = queryData.ProductionOrderID
+		matProdOrder.Owner = participantID
+		matProdOrder.Deleted = false
+		//Define new Material Batch information for Material
+		matBatch := MaterialBatches{}
+		matBatch.BatchNumber = queryData.BatchNumber
+		matBatch.Owner = participantID
+		matBatch.Deleted = false
+		//Update Material with new Material Production Order and Batch information
+		material.ProductionOrders = append(material.ProductionOrders, matProdOrder)
+		material.ActiveBatches = append(material.ActiveBatches, matBatch)
+		material.Batches = append(material.Batches, matBatch)
+
+		// Store Material in Blockchain
+		matJsonBytes, _ := json.Marshal(material) //Get Bytes from struct
+		if puterr := putState(stub, matkeystring, matJsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (GR Production Order - Create Material): Error while storing data into Blockchain")
+		}
+		// Store Production Order in Blockchain
+		jsonBytes, _ := json.Marshal(productionOrder) //Get Bytes from struct
+		if puterr := putState(stub, productionorderkeystring, jsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (GR Production Order): Error while storing data into Blockchain")
+		}
+		// Store Batch in Blockchain
+		batchjsonBytes, _ := json.Marshal(batch) //Get Bytes from struct
+		if puterr := putState(stub, batchkeystring, batchjsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (GR Production Order - Batch): Error while storing data into Blockchain")
+		}
+		return shim.Success(nil)
+	} else {
+		//If Material already exists,
+		//Fetch Material from Blockchain
+		material := Material{}
+		json.Unmarshal(matValue, &material)
+
+		//Check if PO is Present in Material
+		// presentFlag := false
+		// for _, element := range material.ProductionOrders {
+		// 	if (strings.ToLower(element.ProductionOrderID) == strings.ToLower(queryData.ProductionOrderID)) && (strings.ToLower(element.Owner) == strings.ToLower(participantID)) && (element.Deleted == false) {
+		// 		presentFlag = true
+		// 		break
+		// 	}
+		// }
+		// if presentFlag == true {
+		// 	// Store Production Order in Blockchain
+		// 	jsonBytes, _ := json.Marshal(productionOrder) //Get Bytes from struct
+		// 	if puterr := putState(stub, productionorderkeystring, jsonBytes); puterr != nil {
+		// 		return shim.Error("Invoke Error (GR Production Order): Error while storing data into Blockchain")
+		// 	}
+		// 	// Store Batch in Blockchain
+		// 	batchjsonBytes, _ := json.Marshal(batch) //Get Bytes from struct
+		// 	if puterr := putState(stub, batchkeystring, batchjsonBytes); puterr != nil {
+		// 		return shim.Error("Invoke Error (GR Production Order - Batch): Error while storing data into Blockchain")
+		// 	}
+		// 	return shim.Success(nil)
+		// }
+
+		//Define new Material Production Order information for Material
+		matProdOrder := MaterialProductionOrder{}
+		matProdOrder.ProductionOrderID = queryData.ProductionOrderID
+		matProdOrder.Owner = participantID
+		matProdOrder.Deleted = false
+		//Define new Material Batch information for Material
+		matBatch := MaterialBatches{}
+		matBatch.BatchNumber = queryData.BatchNumber
+		matBatch.Owner = participantID
+		matBatch.Deleted = false
+		//Update Material with new Material Production Order and Batch information
+		material.ProductionOrders = append(material.ProductionOrders, matProdOrder)
+		material.ActiveBatches = append(material.ActiveBatches, matBatch)
+		material.Batches = append(material.Batches, matBatch)
+
+		// Store Material in Blockchain
+		matJsonBytes, _ := json.Marshal(material) //Get Bytes from struct
+		if puterr := putState(stub, matkeystring, matJsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (GR Production Order - Update Material): Error while storing data into Blockchain")
+		}
+		// Store Production Order in Blockchain
+		jsonBytes, _ := json.Marshal(productionOrder) //Get Bytes from struct
+		if puterr := putState(stub, productionorderkeystring, jsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (GR Production Order): Error while storing data into Blockchain")
+		}
+		// Store Batch in Blockchain
+		batchjsonBytes, _ := json.Marshal(batch) //Get Bytes from struct
+		if puterr := putState(stub, batchkeystring, batchjsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (GR Production Order - Batch): Error while storing data into Blockchain")
+		}
+		return shim.Success(nil)
+	}
+}
+
+// CASE 08 Get Production Order Info
+func (t *Testing1) getProductionOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		Owner             string `json:"Owner"`
+		ProductionOrderID string `json:"ProductionOrderID"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Get Production Order):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Get Production Order): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "PRODUCTIONORDER"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.Owner, queryData.ProductionOrderID)
+
+	//Get the Asset from Blockchain, if the invoking Participant may read it
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil || !common.ReadableBy(assetSchemas, value, participantID) {
+		return shim.Error("Invoke Error (Get Production Order): Error while fetching data from Blockchain")
+	}
+	return shim.Success(value)
+}
+
+// CASE 09 Delete a Production Order
+func (t *Testing1) deleteProductionOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		Owner             string `json:"Owner"`
+		ProductionOrderID string `json:"ProductionOrderID"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Delete Production Order):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Delete Production Order): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "PRODUCTIONORDER"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.Owner, queryData.ProductionOrderID)
+
+	//Check if Asset exists and get the Asset.
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Delete Production Order): Error while fetching data from Blockchain")
+	}
+	productionOrder := ProductionOrder{}
+	json.Unmarshal(value, &productionOrder)
+
+	//Check if Invoking Participant is authorised for Delete
+	if strings.ToLower(participantID) == strings.ToLower(productionOrder.Owner) {
+		// Delete if Exists
+		if delerr := delState(stub, keystring); delerr != nil {
+			return shim.Error("Invoke Error (Delete Production Order): Error while deleting data from Blockchain")
+		}
+		return shim.Success(nil)
+	} else {
+		return shim.Error("Invoke Error (Delete Production Order): Not Authorized to Delete Production Order")
+	}
+}
+
+// CASE 10 Get Batch Info
+func (t *Testing1) getBatch(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		Owner       string `json:"Owner"`
+		MaterialID  string `json:"MaterialID"`
+		BatchNumber string `json:"BatchNumber"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Get Batch):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Get Batch): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "BATCH"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.Owner, queryData.MaterialID, queryData.BatchNumber)
+
+	//Get the Asset from Blockchain, if the invoking Participant may read it
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil || !common.ReadableBy(assetSchemas, value, participantID) {
+		return shim.Error("Invoke Error (Get Batch): Error while fetching data from Blockchain")
+	}
+	return shim.Success(value)
+}
+
+// CASE 11 Delete a Batch
+func (t *Testing1) deleteBatch(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		Owner       string `json:"Owner"`
+		MaterialID  string `json:"MaterialID"`
+		BatchNumber string `json:"BatchNumber"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Delete Batch):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Delete Batch): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "BATCH"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.Owner, queryData.MaterialID, queryData.BatchNumber)
+
+	//Check if Asset exists and get the Asset.
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Delete Batch): Error while fetching data from Blockchain")
+	}
+	batch := Batch{}
+	json.Unmarshal(value, &batch)
+
+	//Check if Invoking Participant is authorised for Delete
+	if strings.ToLower(participantID) == strings.ToLower(batch.Owner) {
+		// Delete if Exists
+		if delerr := delState(stub, keystring); delerr != nil {
+			return shim.Error("Invoke Error (Delete Batch): Error while deleting data from Blockchain")
+		}
+		return shim.Success(nil)
+	} else {
+		return shim.Error("Invoke Error (Delete Batch): Not Authorized to Delete Batch")
+	}
+}
+
+// CASE 12 Create a Sales Order
+func (t *Testing1) createSalesOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		SalesOrderID   string `json:"SalesOrderID"`
+		POReference    string `json:"POReference"`
+		LineItemNumber string `json:"LineItemNumber"`
+		MaterialID     string `json:"MaterialID"`
+		Quantity       int    `json:"Quantity"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Create Sales Order):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Create Sales Order): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "SALESORDER"
+
+	//One Assets will be created:
+	//(1) Sales Order
+	//One Asset will be updated:
+	//(1) Material
+
+	//Define a new Purchase Order and Line Item.
+	//Line Item
+	salesOrderLineItem := SalesOrderLineItem{}
+	salesOrderLineItem.LineItemNumber = queryData.LineItemNumber
+	salesOrderLineItem.MaterialID = queryData.MaterialID
+	salesOrderLineItem.Quantity = queryData.Quantity
+	//Purchase Order
+	salesOrder := SalesOrder{}
+	salesOrder.Asset_Type = namespace
+	salesOrder.SalesOrderID = queryData.SalesOrderID
+	salesOrder.Owner = participantID
+	salesOrder.POReference = queryData.POReference
+	salesOrder.Status = "OPEN"
+	salesOrder.LineItems = append(salesOrder.LineItems, salesOrderLineItem)
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, participantID, queryData.SalesOrderID)
+
+	//Check If Sales Order already exists.
+	if value, geterr := getState(stub, keystring); !(geterr == nil && value == nil) {
+		return shim.Error("Invoke Error (Create Sales Order): Sales Order Already Exists! Please Specify Another ID")
+	}
+
+	//***********************************************************
+	//Updating new Sales Order information inside Material Asset
+	//***********************************************************
+	//Get Material
+	matNamespace := "MATERIAL"
+	matkeystring := assetKey(stub, matNamespace, queryData.MaterialID)
+	matValue, matGetErr := getState(stub, matkeystring)
+	if matGetErr != nil || matValue == nil {
+		return shim.Error("Invoke Error (Create Sales Order): Material Does Not Exists! Please Check Payload")
+	}
+	material := Material{}
+	json.Unmarshal(matValue, &material)
+
+	//Add Sales Order information to Open PO in Material
+	for index, element := range material.OpenPurchaseOrders {
+		if strings.ToLower(element.PurchaseOrderID) == strings.ToLower(queryData.POReference) {
+			//Define Material Sales Order Information
+			materialAssociatedSalesOrder := MaterialAssociatedSalesOrder{}
+			materialAssociatedSalesOrder.SalesOrderID = queryData.SalesOrderID
+			materialAssociatedSalesOrder.Owner = participantID
+			materialAssociatedSalesOrder.Deleted = false
+
+			//Update Sales Order POOwner information
+			salesOrder.POOwner = element.Owner
+
+			//Update the OpenPO in Material with Sales Order Information
+			element.AssociatedSalesOrders = append(element.AssociatedSalesOrders, materialAssociatedSalesOrder)
+			material.OpenPurchaseOrders[index] = element
+		} else {
+			return shim.Error("Invoke Error (Create Sales Order): PO Does not exists in Material! Please Specify Open PO Reference!")
+		}
+	}
+
+	// Store Material in Blockchain
+	matJsonBytes, _ := json.Marshal(material) //Get Bytes from struct
+	if puterr := putState(stub, matkeystring, matJsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Create Sales Order - Material Update): Error while storing data into Blockchain")
+	}
+	// Store Sales Order in Blockchain
+	jsonBytes, _ := json.Marshal(salesOrder) //Get Bytes from struct
+	if puterr := putState(stub, keystring, jsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Create Sales Order): Error while storing data into Blockchain")
+	}
+	return shim.Success(nil)
+}
+
+// CASE 13 Get Sales Order Info
+func (t *Testing1) getSalesOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		Owner        string `json:"Owner"`
+		SalesOrderID string `json:"SalesOrderID"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Get Sales Order):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Get Sales Order): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "SALESORDER"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.Owner, queryData.SalesOrderID)
+
+	//Get the Asset from Blockchain, if the invoking Participant may read it
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil || !common.ReadableBy(assetSchemas, value, participantID) {
+		return shim.Error("Invoke Error (Get Sales Order): Error while fetching data from Blockchain")
+	}
+	return shim.Success(value)
+}
+
+// CASE 14 Delete a Sales Order
+func (t *Testing1) deleteSalesOrder(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		Owner        string `json:"Owner"`
+		SalesOrderID string `json:"SalesOrderID"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Delete Sales Order):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Delete Sales Order): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "SALESORDER"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.Owner, queryData.SalesOrderID)
+
+	//Check if Asset exists and get the Asset.
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Delete Sales Order): Error while fetching data from Blockchain")
+	}
+	salesOrder := SalesOrder{}
+	json.Unmarshal(value, &salesOrder)
+
+	//Check if Invoking Participant is authorised for Delete
+	if strings.ToLower(participantID) == strings.ToLower(salesOrder.Owner) {
+		// Delete if Exists
+		if delerr := delState(stub, keystring); delerr != nil {
+			return shim.Error("Invoke Error (Delete Sales Order): Error while deleting data from Blockchain")
+		}
+		return shim.Success(nil)
+	} else {
+		return shim.Error("Invoke Error (Delete Sales Order): Not Authorized to Delete Sales Order")
+	}
+}
+
+// CASE 15 Create a Delivery
+func (t *Testing1) createDelivery(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		DeliveryNumber string `json:"DeliveryNumber"`
+		MaterialID     string `json:"MaterialID"`
+		HUID           string `json:"HUID"`
+		Quantity       int    `json:"Quantity"`
+		BatchNumber    string `json:"BatchNumber"`
+		SalesOrderID   string `json:"SalesOrderID"`
+		LineItemNumber string `json:"LineItemNumber"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Create Delivery):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Create Delivery): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "DELIVERY"
+
+	//One Assets will be created:
+	//(1) Delivery
+	//Two Asset will be updated:
+	//(1) Sales Order
+	//(2) Batch
+
+	//Define a new Delivery and Line Item.
+	//Line Item
+	deliveryLineItem := DeliveryLineItem{}
+	deliveryLineItem.LineItemNumber = queryData.LineItemNumber
+	deliveryLineItem.MaterialID = queryData.MaterialID
+	deliveryLineItem.Quantity = queryData.Quantity
+	deliveryLineItem.SourceBatch = queryData.BatchNumber
+	//Delivery
+	delivery := Delivery{}
+	delivery.Asset_Type = namespace
+	delivery.DeliveryNumber = queryData.DeliveryNumber
+	delivery.Owner = participantID
+	delivery.SalesOrderID = queryData.SalesOrderID
+	delivery.LineItems = append(delivery.LineItems, deliveryLineItem)
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, participantID, queryData.SalesOrderID, queryData.DeliveryNumber)
+
+	//Check If Delivery already exists.
+	if value, geterr := getState(stub, keystring); !(geterr == nil && value == nil) {
+		return shim.Error("Invoke Error (Create Delivery): Delivery Already Exists! Please Specify Another ID")
+	}
+
+	//***********************************************************
+	//Updating new Delivery information inside Sales Order Asset
+	//***********************************************************
+	//Get Sales Order
+	salesOrderNamespace := "SALESORDER"
+	salesOrderkeystring := assetKey(stub, salesOrderNamespace, participantID, queryData.SalesOrderID)
+	salesOrderValue, salesOrderGetErr := getState(stub, salesOrderkeystring)
+	if salesOrderGetErr != nil || salesOrderValue == nil {
+		return shim.Error("Invoke Error (Create Delivery): Sales Order Does Not Exists! Please Check Payload")
+	}
+	salesOrder := SalesOrder{}
+	json.Unmarshal(salesOrderValue, &salesOrder)
+
+	//Update Sales Order with Delivery information
+	salesOrder.DeliveryNumber = queryData.DeliveryNumber
+
+	//***********************************************************
+	//Updating new Delivery information inside Batch Asset
+	//***********************************************************
+	//Get Sales Order
+	batchNamespace := "BATCH"
+	batchkeystring := assetKey(stub, batchNamespace, participantID, queryData.MaterialID, queryData.BatchNumber)
+	batchValue, batchGetErr := getState(stub, batchkeystring)
+	if batchGetErr != nil || batchValue == nil {
+		return shim.Error("Invoke Error (Create Delivery): Sales Order Does Not Exists! Please Check Payload")
+	}
+	batch := Batch{}
+	json.Unmarshal(batchValue, &batch)
+
+	//Check the Batch has not Expired
+	txTime, timeErr := common.TxTime(stub)
+	if timeErr != nil {
+		return shim.Error("Invoke Error (Create Delivery): " + timeErr.Error())
+	}
+	if batch.ShelfLife().Expired(txTime) {
+		return shim.Error("Invoke Error (Create Delivery): Batch Expired on " + batch.ExpiryDate + " and cannot be Delivered")
+	}
+
+	//Update Batch with Delivery information and Handling Unit
+	//Create Handling Unit information for Batch
+	batchHU := BatchHandlingUnit{}
+	batchHU.DeliveryNumber = queryData.DeliveryNumber
+	batchHU.HUID = queryData.HUID
+	batchHU.Quantity = queryData.Quantity
+	//Update Batch Available Quantity and HU
+	batch.AvailableQuantity -= queryData.Quantity
+	batch.HandlingUnits = append(batch.HandlingUnits, batchHU)
+
+	// Store Batch in Blockchain
+	batchJsonBytes, _ := json.Marshal(batch) //Get Bytes from struct
+	if puterr := putState(stub, batchkeystring, batchJsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Create Delivery - Batch Update): Error while storing data into Blockchain")
+	}
+	// Store Sales Order in Blockchain
+	salesOrderjsonBytes, _ := json.Marshal(salesOrder) //Get Bytes from struct
+	if puterr := putState(stub, salesOrderkeystring, salesOrderjsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Create Delivery - Sales Order Update): Error while storing data into Blockchain")
+	}
+	// Store Delivery in Blockchain
+	jsonBytes, _ := json.Marshal(delivery) //Get Bytes from struct
+	if puterr := putState(stub, keystring, jsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Create Delivery): Error while storing data into Blockchain")
+	}
+	return shim.Success(nil)
+}
+
+// CASE 16 Get Delivery Info
+func (t *Testing1) getDelivery(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		Owner          string `json:"Owner"`
+		SalesOrderID   string `json:"SalesOrderID"`
+		DeliveryNumber string `json:"DeliveryNumber"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Get Delivery):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Get Delivery): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "DELIVERY"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.Owner, queryData.SalesOrderID, queryData.DeliveryNumber)
+
+	//Get the Asset from Blockchain, if the invoking Participant may read it
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil || !common.ReadableBy(assetSchemas, value, participantID) {
+		return shim.Error("Invoke Error (Get Delivery): Error while fetching data from Blockchain")
+	}
+	return shim.Success(value)
+}
+
+// CASE 17 Delete a Delivery
+func (t *Testing1) deleteDelivery(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		Owner          string `json:"Owner"`
+		SalesOrderID   string `json:"SalesOrderID"`
+		DeliveryNumber string `json:"DeliveryNumber"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Delete Delivery):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Delete Delivery): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "DELIVERY"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.Owner, queryData.SalesOrderID, queryData.DeliveryNumber)
+
+	//Check if Asset exists and get the Asset.
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Delete Delivery): Error while fetching data from Blockchain")
+	}
+	delivey := Delivery{}
+	json.Unmarshal(value, &delivey)
+
+	//Check if Invoking Participant is authorised for Delete
+	if strings.ToLower(participantID) == strings.ToLower(delivey.Owner) {
+		// Delete if Exists
+		if delerr := delState(stub, keystring); delerr != nil {
+			return shim.Error("Invoke Error (Delete Delivery): Error while deleting data from Blockchain")
+		}
+		return shim.Success(nil)
+	} else {
+		return shim.Error("Invoke Error (Delete Delivery): Not Authorized to Delete Delivery")
+	}
+}
+
+// CASE 18 Delete a Shipment
+func (t *Testing1) createShipment(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		ShipmentID       string `json:"ShipmentID"`
+		DeliveryNumber   string `json:"DeliveryNumber"`
+		SalesOrderID     string `json:"SalesOrderID"`
+		UnsignedReadings bool   `json:"UnsignedReadings"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Create Shipment):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Create Shipment): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "SHIPMENT"
+
+	//One Assets will be created:
+	//(1) Shipment
+	//One Asset will be updated:
+	//(1) Delivery
+
+	//Define a new Shipment
+	shipment := Shipment{}
+	shipment.Asset_Type = namespace
+	shipment.ShipmentID = queryData.ShipmentID
+	shipment.Owner = participantID
+	shipment.DeliveryNumber = queryData.DeliveryNumber
+	shipment.SalesOrderID = queryData.SalesOrderID
+	shipment.Status = "OPEN"
+	shipment.UnsignedReadings = queryData.UnsignedReadings
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.ShipmentID)
+
+	//Check If Shipment already exists.
+	if value, geterr := getState(stub, keystring); !(geterr == nil && value == nil) {
+		return shim.Error("Invoke Error (Create Shipment): Shipment Already Exists! Please Specify Another ID")
+	}
+
+	//***********************************************************
+	//Updating new Shipment information inside Delivery
+	//***********************************************************
+	//Get Delivery
+	deliveryNamespace := "DELIVERY"
+	deliverykeystring := assetKey(stub, deliveryNamespace, participantID, queryData.SalesOrderID, queryData.DeliveryNumber)
+	deliveryValue, deliveryGetErr := getState(stub, deliverykeystring)
+	if deliveryGetErr != nil || deliveryValue == nil {
+		return shim.Error("Invoke Error (Create Shipment): Delvery Does Not Exists! Please Check Payload")
+	}
+	delivery := Delivery{}
+	json.Unmarshal(deliveryValue, &delivery)
+
+	//Check no Source Batch of the Delivery has Expired
+	txTime, timeErr := common.TxTime(stub)
+	if timeErr != nil {
+		return shim.Error("Invoke Error (Create Shipment): " + timeErr.Error())
+	}
+	for _, element := range delivery.LineItems {
+		batchValue, batchGetErr := getState(stub, assetKey(stub, "BATCH", delivery.Owner, element.MaterialID, element.SourceBatch))
+		if batchGetErr != nil {
+			return shim.Error("Invoke Error (Create Shipment): Error while fetching data from Blockchain")
+		}
+		batch := Batch{}
+		json.Unmarshal(batchValue, &batch)
+		if batchValue != nil && batch.ShelfLife().Expired(txTime) {
+			return shim.Error("Invoke Error (Create Shipment): Batch " + batch.BatchNumber + " Expired on " + batch.ExpiryDate + " and cannot be Shipped")
+		}
+	}
+
+	//Update Delivery
+	delivery.Shipments = append(delivery.Shipments, queryData.ShipmentID)
+
+	// Store Delivery in Blockchain
+	deliveryJsonBytes, _ := json.Marshal(delivery) //Get Bytes from struct
+	if puterr := putState(stub, deliverykeystring, deliveryJsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Create Shipment - Update Delivery): Error while storing data into Blockchain")
+	}
+	// Store Shipment in Blockchain
+	jsonBytes, _ := json.Marshal(shipment) //Get Bytes from struct
+	if puterr := putState(stub, keystring, jsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Create Shipment): Error while storing data into Blockchain")
+	}
+	return shim.Success(nil)
+}
+
+// CASE 19 Get Shipment Info
+func (t *Testing1) getShipment(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Get Data
+	data := string(args[0])
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Get Shipment): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "SHIPMENT"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, data)
+
+	//Get the Asset from Blockchain, if the invoking Participant may read it
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil || !common.ReadableBy(assetSchemas, value, participantID) {
+		return shim.Error("Invoke Error (Get Shipment): Error while fetching data from Blockchain")
+	}
+	return shim.Success(value)
+}
+
+// CASE 20 Delete a Shipment
+func (t *Testing1) deleteShipment(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Get Data
+	data := string(args[0])
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	if _, iderr := getInvokingParticipant(stub); iderr != nil {
+		return shim.Error("Invoke Error (Delete Shipment): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "SHIPMENT"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, data)
+
+	//Check if Asset exists.
+	if value, geterr := getState(stub, keystring); geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Delete Shipment): Shipment Does Not Exist in Blockchain")
+	}
+
+	//Delete Shipment
+	if delerr := delState(stub, keystring); delerr != nil {
+		return shim.Error("Invoke Error (Delete Shipment): Error while deleting data from Blockchain")
+	}
+	return shim.Success(nil)
+}
+
+// CASE 21 Report a Purchase Order Goods Receipt
+func (t *Testing1) reportPurchaseOrderGR(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		PurchaseOrderID string `json:"PurchaseOrderID"`
+		LineItemNumber  string `json:"LineItemNumber"`
+		MaterialID      string `json:"MaterialID"`
+		Quantity        int    `json:"Quantity"`
+		Plant           string `json:"Plant"`
+		StorageLocation string `json:"StorageLocation"`
+		BatchNumber     string `json:"BatchNumber"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (GR Purchase Order):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Delete Shipment): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	matNamespace := "MATERIAL"
+	batchNamespace := "BATCH"
+	poNamespace := "PURCHASEORDER"
+	salesOrderNamespace := "SALESORDER"
+	deliveryNamespace := "DELIVERY"
+	shipmentNamespace := "SHIPMENT"
+
+	//Six Asset will be updated:
+	//(1) Material
+	//(2) Batch
+	//(3) Purchase Order
+	//(4) Sales Order
+	//(5) Shipment
+
+	//****************************************************************
+	//Updating new PO Goods Receipt information inside Material
+	//****************************************************************
+	//Key for fetching/storing the Asset
+	materialkeystring := assetKey(stub, matNamespace, queryData.MaterialID)
+	//Get Material
+	matValue, matGetErr := getState(stub, materialkeystring)
+	if matGetErr != nil || matValue == nil {
+		return shim.Error("Invoke Error (GR Purchase Order): Material Does Not Exists! Please Check Payload")
+	}
+	material := Material{}
+	json.Unmarshal(matValue, &material)
+
+	//Check if Batch Exist in material or Create New Material Batch Info
+	activeBatchPresentFlag := false
+	for _, element := range material.ActiveBatches {
+		if (strings.ToLower(element.BatchNumber) == strings.ToLower(queryData.BatchNumber)) && (strings.ToLower(element.Owner) == strings.ToLower(participantID)) && (element.Deleted == false) {
+			//If Batch Exists inside Material,
+			//No need for update
+			activeBatchPresentFlag = true
+			break
+		}
+	}
+	batchPresentFlag := false
+	for _, element := range material.Batches {
+		if (strings.ToLower(element.BatchNumber) == strings.ToLower(queryData.BatchNumber)) && (strings.ToLower(element.Owner) == strings.ToLower(participantID)) && (element.Deleted == false) {
+			//If Batch Exists inside Material,
+			//No need for update
+			batchPresentFlag = true
+			break
+		}
+	}
+
+	if activeBatchPresentFlag == true {
+		//If batch is not present,
+		//Include new Batch info in Material
+
+		//Create new Material Batch Information
+		materialBatch := MaterialBatches{}
+		materialBatch.BatchNumber = queryData.BatchNumber
+		materialBatch.Owner = participantID
+		materialBatch.Deleted = false
+
+		//Update Material Active Batches
+		material.ActiveBatches = append(material.ActiveBatches, materialBatch)
+	}
+	if batchPresentFlag == true {
+		//If batch is not present,
+		//Include new Batch info in Material
+
+		//Create new Material Batch Information
+		materialBatch := MaterialBatches{}
+		materialBatch.BatchNumber = queryData.BatchNumber
+		materialBatch.Owner = participantID
+		materialBatch.Deleted = false
+
+		//Update Material Batches
+		material.Batches = append(material.Batches, materialBatch)
+	}
+
+	//Update the Purchase Order in Material as closed PO
+	//Keep Track of Associated Sales Order to be used later
+	matSalesOrderInfo := MaterialAssociatedSalesOrder{}
+	for index, element := range material.OpenPurchaseOrders {
+		if (strings.ToLower(element.PurchaseOrderID) == strings.ToLower(queryData.PurchaseOrderID)) && (strings.ToLower(element.Owner) == strings.ToLower(participantID)) && (element.Deleted == false) {
+			material.ClosedPurchaseOrders = append(material.ClosedPurchaseOrders, element)
+			material.OpenPurchaseOrders = append(material.OpenPurchaseOrders[:index], material.OpenPurchaseOrders[index+1:]...)
+			matSalesOrderInfo = element.AssociatedSalesOrders[0]
+			break
+		} else {
+			shim.Error("Invoke Error (GR Purchase Order):  Material Does Not Contain the Purchase Order Information")
+		}
+	}
+
+	//****************************************************************
+	//Updating new PO Goods Receipt information inside Batch
+	//****************************************************************
+	//Key for fetching/storing the Asset
+	batchkeystring := assetKey(stub, batchNamespace, participantID, queryData.MaterialID, queryData.BatchNumber)
+
+	// Check If Batch already Exists and get the Batch to update quantity, else create a new Batch.
+	batchValue, batchGetErr := getState(stub, batchkeystring)
+	batch := Batch{}
+	if batchGetErr != nil || batchValue == nil {
+		//If Batch does not exist,
+		//Create New Batch
+		batch.Asset_Type = batchNamespace
+		batch.BatchNumber = queryData.BatchNumber
+		batch.MaterialID = queryData.MaterialID
+		batch.Owner = participantID
+		batch.Plant = queryData.Plant
+		batch.StorageLocation = queryData.StorageLocation
+		batch.AvailableQuantity = queryData.Quantity
+		batch.Status = "OK"
+	} else {
+		//If Batch exists,
+		//Get the Batch
+		json.Unmarshal(batchValue, &batch)
+		batch.AvailableQuantity += queryData.Quantity
+		batch.Plant = queryData.Plant
+		batch.StorageLocation = queryData.StorageLocation
+	}
+
+	//****************************************************************
+	//Updating new PO Goods Receipt information inside Purchase Order
+	//****************************************************************
+	//Key for fetching/storing the Asset
+	pokeystring := assetKey(stub, poNamespace, participantID, queryData.PurchaseOrderID)
+	//Get Purchase Order
+	poValue, poGetErr := getState(stub, pokeystring)
+	if poGetErr != nil || poValue == nil {
+		return shim.Error("Invoke Error (GR Purchase Order): Purchase Order Does Not Exists! Please Check Payload")
+	}
+	purchaseOrder := PurchaseOrder{}
+	json.Unmarshal(poValue, &purchaseOrder)
+
+	//Update Purchase Order
+	purchaseOrder.Status = "COMPLETED"
+	purchaseOrder.TargetBatch = queryData.BatchNumber
+
+	//****************************************************************
+	//Updating new PO Goods Receipt information inside Sales Order
+	//****************************************************************
+	//Key for fetching/storing the Asset
+	salesOrderkeystring := assetKey(stub, salesOrderNamespace, matSalesOrderInfo.Owner, matSalesOrderInfo.SalesOrderID)
+	//Get Sales Order
+	salesOrderValue, salesOrderGetErr := getState(stub, salesOrderkeystring)
+	if salesOrderGetErr != nil || salesOrderValue == nil {
+		return shim.Error("Invoke Error (GR Purchase Order): Sales Order Does Not Exists! Please Check Payload")
+	}
+	salesOrder := SalesOrder{}
+	json.Unmarshal(salesOrderValue, &salesOrder)
+
+	//Update Sales Order
+	salesOrder.Status = "COMPLETED"
+
+	//****************************************************************
+	//Updating new PO Goods Receipt information inside Shipment
+	//****************************************************************
+	//Get Delivery Information
+	//Key for fetching/storing the Asset
+	deliverykeystring := assetKey(stub, deliveryNamespace, salesOrder.Owner, salesOrder.SalesOrderID, salesOrder.DeliveryNumber)
+	//Get Delivery
+	deliveryValue, deliveryGetErr := getState(stub, deliverykeystring)
+	if deliveryGetErr != nil || deliveryValue == nil {
+		return shim.Error("Invoke Error (GR Purchase Order): Delivery Does Not Exists! Please Check Payload")
+	}
+	delivery := Delivery{}
+	json.Unmarshal(deliveryValue, &delivery)
+
+	//The received Batch inherits the Dates of the delivered Source Batches, the earliest Expiry Date wins
+	shelfLife := batch.ShelfLife()
+	for _, element := range delivery.LineItems {
+		if strings.ToLower(element.MaterialID) != strings.ToLower(queryData.MaterialID) {
+			continue
+		}
+		sourceBatchValue, sourceBatchGetErr := getState(stub, assetKey(stub, batchNamespace, delivery.Owner, element.MaterialID, element.SourceBatch))
+		if sourceBatchGetErr != nil {
+			return shim.Error("Invoke Error (GR Purchase Order): Error while fetching data from Blockchain")
+		}
+		sourceBatch := Batch{}
+		json.Unmarshal(sourceBatchValue, &sourceBatch)
+		shelfLife = shelfLife.Earliest(sourceBatch.ShelfLife())
+		batch.PotentialCompromised = batch.PotentialCompromised || sourceBatch.PotentialCompromised
+	}
+	batch.SetShelfLife(shelfLife)
+
+	//Get Shipment Information
+	//Key for fetching/storing the Asset
+	shipmentkeystring := assetKey(stub, shipmentNamespace, delivery.Shipments[0])
+	//Get Delivery
+	shipmentValue, shipmentGetErr := getState(stub, shipmentkeystring)
+	if shipmentGetErr != nil || shipmentValue == nil {
+		return shim.Error("Invoke Error (GR Purchase Order): Shipment Does Not Exists! Please Check Payload")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentValue, &shipment)
+
+	//Goods that left the Temperature Limits of the Material during the Shipment may be spoiled
+	if len(shipment.Excursions) > 0 {
+		batch.PotentialCompromised = true
+	}
+
+	//Update Shipment
+	shipment.Status = "COMPLETED"
+
+	// Store Assets inside Blockchain
+	// Store Material in Blockchain
+	matJsonBytes, _ := json.Marshal(material) //Get Bytes from struct
+	if puterr := putState(stub, materialkeystring, matJsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (GR Purchase Order - Update Material): Error while storing data into Blockchain")
+	}
+	// Store Batch in Blockchain
+	batchJsonBytes, _ := json.Marshal(batch) //Get Bytes from struct
+	if puterr := putState(stub, batchkeystring, batchJsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (GR Purchase Order - Update Batch): Error while storing data into Blockchain")
+	}
+	// Store Purchase Order in Blockchain
+	poJsonBytes, _ := json.Marshal(purchaseOrder) //Get Bytes from struct
+	if puterr := putState(stub, pokeystring, poJsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (GR Purchase Order - Update Purchase Order): Error while storing data into Blockchain")
+	}
+	// Store Sales Order in Blockchain
+	salesOrderJsonBytes, _ := json.Marshal(salesOrder) //Get Bytes from struct
+	if puterr := putState(stub, salesOrderkeystring, salesOrderJsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (GR Purchase Order - Update Sales Order): Error while storing data into Blockchain")
+	}
+	// Store Shipment in Blockchain
+	shipmentJsonBytes, _ := json.Marshal(shipment) //Get Bytes from struct
+	if puterr := putState(stub, shipmentkeystring, shipmentJsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (GR Purchase Order - Update Shipment): Error while storing data into Blockchain")
+	}
+	return shim.Success(nil)
+}
+
+// CASE XX Get Material Info
+func (t *Testing1) getMaterial(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Get Data
+	data := string(args[0])
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	if _, iderr := getInvokingParticipant(stub); iderr != nil {
+		return shim.Error("Invoke Error (Get Material): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "MATERIAL"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, data)
+
+	//Get the Asset from Blockchain
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Get Material): Error while fetching data from Blockchain")
+	}
+	return shim.Success(value)
+}
+
+// CASE XX Delete a Delivery
+func (t *Testing1) deleteMaterial(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Get Data
+	data := string(args[0])
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	if _, iderr := getInvokingParticipant(stub); iderr != nil {
+		return shim.Error("Invoke Error (Delete Material): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "MATERIAL"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, data)
+
+	//Check if Asset exists.
+	if value, geterr := getState(stub, keystring); geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Delete Material): Material Does Not Exist in Blockchain")
+	}
+
+	//Delete Material
+	if delerr := delState(stub, keystring); delerr != nil {
+		return shim.Error("Invoke Error (Delete Material): Error while deleting data from Blockchain")
+	}
+	return shim.Success(nil)
+}
+
+// CASE 22 List Purchase Orders of an Owner
+func (t *Testing1) listPurchaseOrders(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	queryData, err := listArguments(stub, args)
+	if err != nil {
+		return shim.Error("Invoke Error (List Purchase Orders): " + err.Error())
+	}
+	return listAssets(stub, "List Purchase Orders", "PURCHASEORDER", queryData.Owner)
+}
+
+// CASE 23 List Production Orders of an Owner
+func (t *Testing1) listProductionOrders(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	queryData, err := listArguments(stub, args)
+	if err != nil {
+		return shim.Error("Invoke Error (List Production Orders): " + err.Error())
+	}
+	return listAssets(stub, "List Production Orders", "PRODUCTIONORDER", queryData.Owner)
+}
+
+// CASE 24 List Batches of an Owner, optionally of one Material
+func (t *Testing1) listBatches(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	queryData, err := listArguments(stub, args)
+	if err != nil {
+		return shim.Error("Invoke Error (List Batches): " + err.Error())
+	}
+	if queryData.MaterialID != "" {
+		return listAssets(stub, "List Batches", "BATCH", queryData.Owner, queryData.MaterialID)
+	}
+	return listAssets(stub, "List Batches", "BATCH", queryData.Owner)
+}
+
+// CASE 25 List Sales Orders of an Owner
+func (t *Testing1) listSalesOrders(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	queryData, err := listArguments(stub, args)
+	if err != nil {
+		return shim.Error("Invoke Error (List Sales Orders): " + err.Error())
+	}
+	return listAssets(stub, "List Sales Orders", "SALESORDER", queryData.Owner)
+}
+
+// CASE 26 List Deliveries of an Owner, optionally of one Sales Order
+func (t *Testing1) listDeliveries(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	queryData, err := listArguments(stub, args)
+	if err != nil {
+		return shim.Error("Invoke Error (List Deliveries): " + err.Error())
+	}
+	if queryData.SalesOrderID != "" {
+		return listAssets(stub, "List Deliveries", "DELIVERY", queryData.Owner, queryData.SalesOrderID)
+	}
+	return listAssets(stub, "List Deliveries", "DELIVERY", queryData.Owner)
+}
+
+// CASE 27 List all Shipments
+func (t *Testing1) listShipments(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if _, err := listArguments(stub, args); err != nil {
+		return shim.Error("Invoke Error (List Shipments): " + err.Error())
+	}
+	return listAssets(stub, "List Shipments", "SHIPMENT")
+}
+
+// CASE 28 List all Materials
+func (t *Testing1) listMaterials(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if _, err := listArguments(stub, args); err != nil {
+		return shim.Error("Invoke Error (List Materials): " + err.Error())
+	}
+	return listAssets(stub, "List Materials", "MATERIAL")
+}
+
+// CASE 29 List Batches of an Owner expiring within a number of Days, optionally of one Material
+func (t *Testing1) listExpiringBatches(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	queryData, err := listArguments(stub, args)
+	if err != nil || queryData.Days < 0 {
+		return shim.Error("Invoke Error (List Expiring Batches): Invalid Data - Check Payload")
+	}
+	parts := []string{queryData.Owner}
+	if queryData.MaterialID != "" {
+		parts = append(parts, queryData.MaterialID)
+	}
+	records, listErr := listRecords(stub, "BATCH", parts...)
+	if listErr != nil {
+		return shim.Error("Invoke Error (List Expiring Batches): Error while fetching data from Blockchain")
+	}
+	txTime, timeErr := common.TxTime(stub)
+	if timeErr != nil {
+		return shim.Error("Invoke Error (List Expiring Batches): " + timeErr.Error())
+	}
+
+	//Keep the Batches holding Quantity that expire by the limit, the first to expire first
+	batches := []Batch{}
+	for _, element := range records {
+		batch := Batch{}
+		json.Unmarshal(element.Record, &batch)
+		if batch.AvailableQuantity > 0 && batch.ShelfLife().ExpiresWithin(txTime, queryData.Days) {
+			batches = append(batches, batch)
+		}
+	}
+	sort.SliceStable(batches, func(i, j int) bool { return batches[i].ExpiryDate < batches[j].ExpiryDate })
+	jsonBytes, _ := json.Marshal(batches)
+	return shim.Success(jsonBytes)
+}
+
+// CASE 30 Set the Temperature Limits of a Material, checked against the Sensor Readings of its Shipments
+func (t *Testing1) setTemperatureLimits(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		MaterialID     string   `json:"MaterialID"`
+		MinTemperature *float64 `json:"MinTemperature,omitempty"`
+		MaxTemperature *float64 `json:"MaxTemperature,omitempty"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Set Temperature Limits):  Invalid Data - Check Payload")
+	}
+	//A limit left out is not checked
+	limits := common.TemperatureLimits{MinTemperature: queryData.MinTemperature, MaxTemperature: queryData.MaxTemperature}
+	if limitErr := limits.Validate(); limitErr != nil {
+		return shim.Error("Invoke Error (Set Temperature Limits):  Invalid Data - " + limitErr.Error())
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	if _, iderr := getInvokingParticipant(stub); iderr != nil {
+		return shim.Error("Invoke Error (Set Temperature Limits): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "MATERIAL"
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.MaterialID)
+
+	//Get Material
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Set Temperature Limits): Material Does Not Exist in Blockchain")
+	}
+	material := Material{}
+	json.Unmarshal(value, &material)
+
+	//Update Material
+	material.TemperatureLimits = limits
+
+	// Store Material in Blockchain
+	jsonBytes, _ := json.Marshal(material) //Get Bytes from struct
+	if puterr := putState(stub, keystring, jsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Set Temperature Limits): Error while storing data into Blockchain")
+	}
+	return shim.Success(nil)
+}
+
+// CASE 31 Record a Sensor Reading of a Shipment
+// A temperature outside the limits of a shipped Material records an excursion and flags its Source Batch PotentialCompromised
+func (t *Testing1) recordSensorReading(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		ShipmentID         string   `json:"ShipmentID"`
+		TemperatureCelsius *float64 `json:"TemperatureCelsius"`
+		Humidity           float64  `json:"Humidity,omitempty"`
+		Shock              float64  `json:"Shock,omitempty"`
+		Timestamp          string   `json:"Timestamp,omitempty"`
+		common.DeviceSignature
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil || queryData.TemperatureCelsius == nil {
+		return shim.Error("Invoke Error (Record Sensor Reading):  Invalid Data - Check Payload")
+	}
+	sent := common.SensorReading{TemperatureCelsius: *queryData.TemperatureCelsius, Humidity: queryData.Humidity, Shock: queryData.Shock, Timestamp: queryData.Timestamp}
+	//Readings without Timestamp are taken at the transaction time
+	reading, readingErr := sent.Normalize(stub)
+	if readingErr != nil {
+		return shim.Error("Invoke Error (Record Sensor Reading):  Invalid Data - " + readingErr.Error())
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	if _, iderr := getInvokingParticipant(stub); iderr != nil {
+		return shim.Error("Invoke Error (Record Sensor Reading): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "SHIPMENT"
+
+	//One Asset will be updated:
+	//(1) Shipment
+	//Source Batches of the Delivery will be updated on an excursion
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.ShipmentID)
+
+	//Get Shipment
+	value, geterr := getState(stub, keystring)
+	if geterr != nil || value == nil {
+		return shim.Error("Invoke Error (Record Sensor Reading): Shipment Does Not Exist in Blockchain")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(value, &shipment)
+	if shipment.Status == "COMPLETED" {
+		return shim.Error("Invoke Error (Record Sensor Reading): Shipment is already Completed")
+	}
+
+	//A Shipment only accepts readings signed by its Devices, with a growing Sequence,
+	//unless it was created with UnsignedReadings and no Device is attached yet
+	if queryData.DeviceID == "" && (len(shipment.Devices) > 0 || !shipment.UnsignedReadings) {
+		return shim.Error("Invoke Error (Record Sensor Reading): Shipment only accepts Readings signed by its Devices")
+	}
+	if queryData.DeviceID != "" {
+		if !containsString(shipment.Devices, queryData.DeviceID) {
+			return shim.Error("Invoke Error (Record Sensor Reading): Device is not attached to the Shipment")
+		}
+		devicekeystring := assetKey(stub, "DEVICE", queryData.DeviceID)
+		deviceValue, deviceGetErr := getState(stub, devicekeystring)
+		if deviceGetErr != nil || deviceValue == nil {
+			return shim.Error("Invoke Error (Record Sensor Reading): Device Does Not Exist in Blockchain")
+		}
+		device := Device{}
+		json.Unmarshal(deviceValue, &device)
+		if checkErr := queryData.DeviceSignature.Check(common.DeviceKey{KeyType: device.KeyType, PublicKey: device.PublicKey}, device.Sequence, sent.SignedFields(queryData.ShipmentID)...); checkErr != nil {
+			return shim.Error("Invoke Error (Record Sensor Reading): " + checkErr.Error())
+		}
+		//Update Device
+		device.Sequence = queryData.Sequence
+		deviceJsonBytes, _ := json.Marshal(device) //Get Bytes from struct
+		if puterr := putState(stub, devicekeystring, deviceJsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (Record Sensor Reading - Update Device): Error while storing data into Blockchain")
+		}
+	}
+
+	//Get Delivery of the Shipment
+	deliveryValue, deliveryGetErr := getState(stub, assetKey(stub, "DELIVERY", shipment.Owner, shipment.SalesOrderID, shipment.DeliveryNumber))
+	if deliveryGetErr != nil || deliveryValue == nil {
+		return shim.Error("Invoke Error (Record Sensor Reading): Delivery Does Not Exists! Please Check Payload")
+	}
+	delivery := Delivery{}
+	json.Unmarshal(deliveryValue, &delivery)
+
+	//Update Shipment
+	shipment.SensorReadings = append(shipment.SensorReadings, reading)
+
+	//Check the Reading against the Limits of every delivered Material, each Source Batch is flagged once
+	excursion := false
+	for _, element := range delivery.LineItems {
+		materialValue, materialGetErr := getState(stub, assetKey(stub, "MATERIAL", element.MaterialID))
+		if materialGetErr != nil {
+			return shim.Error("Invoke Error (Record Sensor Reading): Error while fetching data from Blockchain")
+		}
+		material := Material{}
+		json.Unmarshal(materialValue, &material)
+		materialExcursion := material.TemperatureLimits.Check(reading)
+		if materialExcursion == nil {
+			continue
+		}
+		if !excursion {
+			shipment.Excursions = append(shipment.Excursions, *materialExcursion)
+			excursion = true
+		}
+
+		//Flag the Source Batch
+		batchkeystring := assetKey(stub, "BATCH", delivery.Owner, element.MaterialID, element.SourceBatch)
+		batchValue, batchGetErr := getState(stub, batchkeystring)
+		if batchGetErr != nil {
+			return shim.Error("Invoke Error (Record Sensor Reading): Error while fetching data from Blockchain")
+		}
+		if batchValue == nil {
+			continue
+		}
+		batch := Batch{}
+		json.Unmarshal(batchValue, &batch)
+		batch.PotentialCompromised = true
+		batchJsonBytes, _ := json.Marshal(batch) //Get Bytes from struct
+		if puterr := putState(stub, batchkeystring, batchJsonBytes); puterr != nil {
+			return shim.Error("Invoke Error (Record Sensor Reading - Update Batch): Error while storing data into Blockchain")
+		}
+	}
+
+	// Store Shipment in Blockchain
+	jsonBytes, _ := json.Marshal(shipment) //Get Bytes from struct
+	if puterr := putState(stub, keystring, jsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Record Sensor Reading): Error while storing data into Blockchain")
+	}
+	return shim.Success(nil)
+}
+
+// CASE 32 Register a Device of the Invoking Participant, with the public key it signs its readings with
+func (t *Testing1) registerDevice(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		DeviceID  string `json:"DeviceID"`
+		KeyType   string `json:"KeyType"`
+		PublicKey string `json:"PublicKey"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil || queryData.DeviceID == "" {
+		return shim.Error("Invoke Error (Register Device):  Invalid Data - Check Payload")
+	}
+	//The Public Key must be an ED25519 or ECDSA key
+	key, keyErr := common.DeviceKey{KeyType: queryData.KeyType, PublicKey: queryData.PublicKey}.Normalize()
+	if keyErr != nil {
+		return shim.Error("Invoke Error (Register Device):  Invalid Data - " + keyErr.Error())
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Register Device): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Define Namespace
+	namespace := "DEVICE"
+
+	//Define a new Device
+	device := Device{}
+	device.Asset_Type = namespace
+	device.DeviceID = queryData.DeviceID
+	device.Owner = participantID
+	device.KeyType = key.KeyType
+	device.PublicKey = key.PublicKey
+
+	//Key for fetching/storing the Asset
+	keystring := assetKey(stub, namespace, queryData.DeviceID)
+
+	//Check If Device already exists.
+	if value, geterr := getState(stub, keystring); !(geterr == nil && value == nil) {
+		return shim.Error("Invoke Error (Register Device): Device Already Exists! Please Specify Another ID")
+	}
+
+	// Store Device in Blockchain
+	jsonBytes, _ := json.Marshal(device) //Get Bytes from struct
+	if puterr := putState(stub, keystring, jsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Register Device): Error while storing data into Blockchain")
+	}
+	return shim.Success(nil)
+}
+
+// CASE 33 Attach a Device to a Shipment, both owned by the Invoking Participant
+func (t *Testing1) attachDevice(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		DeviceID   string `json:"DeviceID"`
+		ShipmentID string `json:"ShipmentID"`
+	}
+
+	//Get Data
+	data := string(args[0])
+	queryData := QueryData{}
+	err := json.Unmarshal([]byte(data), &queryData)
+	if err != nil {
+		return shim.Error("Invoke Error (Attach Device):  Invalid Data - Check Payload")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Attach Device): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+
+	//Two Assets will be updated:
+	//(1) Device
+	//(2) Shipment
+
+	//Get Device
+	devicekeystring := assetKey(stub, "DEVICE", queryData.DeviceID)
+	deviceValue, deviceGetErr := getState(stub, devicekeystring)
+	if deviceGetErr != nil || deviceValue == nil {
+		return shim.Error("Invoke Error (Attach Device): Device Does Not Exist in Blockchain")
+	}
+	device := Device{}
+	json.Unmarshal(deviceValue, &device)
+
+	//Get Shipment
+	shipmentkeystring := assetKey(stub, "SHIPMENT", queryData.ShipmentID)
+	shipmentValue, shipmentGetErr := getState(stub, shipmentkeystring)
+	if shipmentGetErr != nil || shipmentValue == nil {
+		return shim.Error("Invoke Error (Attach Device): Shipment Does Not Exist in Blockchain")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentValue, &shipment)
+
+	//Check Ownership
+	if device.Owner != participantID || shipment.Owner != participantID {
+		return shim.Error("Invoke Error (Attach Device): Device and Shipment must be owned by the Invoking Participant")
+	}
+	if shipment.Status == "COMPLETED" {
+		return shim.Error("Invoke Error (Attach Device): Shipment is already Completed")
+	}
+	if containsString(shipment.Devices, device.DeviceID) {
+		return shim.Error("Invoke Error (Attach Device): Device is already attached to the Shipment")
+	}
+
+	//Update Device and Shipment
+	device.Shipments = append(device.Shipments, shipment.ShipmentID)
+	shipment.Devices = append(shipment.Devices, device.DeviceID)
+
+	// Store Device in Blockchain
+	deviceJsonBytes, _ := json.Marshal(device) //Get Bytes from struct
+	if puterr := putState(stub, devicekeystring, deviceJsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Attach Device - Update Device): Error while storing data into Blockchain")
+	}
+	// Store Shipment in Blockchain
+	shipmentJsonBytes, _ := json.Marshal(shipment) //Get Bytes from struct
+	if puterr := putState(stub, shipmentkeystring, shipmentJsonBytes); puterr != nil {
+		return shim.Error("Invoke Error (Attach Device - Update Shipment): Error while storing data into Blockchain")
+	}
+	return shim.Success(nil)
+}
+
+//********************************************************************************************************
+// Micellanious Functions
+//********************************************************************************************************
+
+//Checks if a list holds a value
+func containsString(list []string, value string) bool {
+	for _, element := range list {
+		if element == value {
+			return true
+		}
+	}
+	return false
+}
+
+//Resolves the X.509 identity of the transaction creator (replaced by unit tests)
+var getCreatorIdentity = common.GetIdentity
+
+//Asset types accepted by customQueries, keyed by their namespace.
+//Owner fields name the Participants that may read a record, assets without owner fields are shared.
+var assetSchemas = map[string]common.AssetSchema{
+	"PARTICIPANT":     common.NewAssetSchema("PARTICIPANT", Participant{}),
+	"MATERIAL":        common.NewAssetSchema("MATERIAL", Material{}),
+	"PURCHASEORDER":   common.NewAssetSchema("PURCHASEORDER", PurchaseOrder{}, "Owner", "Vendor"),
+	"SALESORDER":      common.NewAssetSchema("SALESORDER", SalesOrder{}, "Owner", "POOwner"),
+	"PRODUCTIONORDER": common.NewAssetSchema("PRODUCTIONORDER", ProductionOrder{}, "Owner"),
+	"BATCH":           common.NewAssetSchema("BATCH", Batch{}, "Owner"),
+	"DELIVERY":        common.NewAssetSchema("DELIVERY", Delivery{}, "Owner"),
+	"SHIPMENT":        common.NewAssetSchema("SHIPMENT", Shipment{}, "Owner"),
+	"DEVICE":          common.NewAssetSchema("DEVICE", Device{}, "Owner"),
+}
+
+//Key for fetching/storing the binding between an identity and a Participant, in the PARTICIPANT namespace.
+//Participant keys have a single ID part, so a binding never shares the key of a Participant.
+func identityKey(stub shim.ChaincodeStubInterface, identity common.Identity) string {
+	return assetKey(stub, "PARTICIPANT", "IDENTITY", identity.MSPID, identity.EnrollmentID)
+}
+
+//ID fields of the Assets of each namespace, in key order
+var keyFields = map[string][]string{
+	"PARTICIPANT":     {"ParticipantID"},
+	"MATERIAL":        {"MaterialID"},
+	"PURCHASEORDER":   {"Owner", "PurchaseOrderID"},
+	"PRODUCTIONORDER": {"Owner", "ProductionOrderID"},
+	"BATCH":           {"Owner", "MaterialID", "BatchNumber"},
+	"SALESORDER":      {"Owner", "SalesOrderID"},
+	"DELIVERY":        {"Owner", "SalesOrderID", "DeliveryNumber"},
+	"SHIPMENT":        {"ShipmentID"},
+	"DEVICE":          {"DeviceID"},
+}
+
+//Key for fetching/storing an Asset: a composite key of the namespace and the lower case ID parts.
+//IDs the ledger cannot store give an empty key, which every state call rejects.
+func assetKey(stub shim.ChaincodeStubInterface, namespace string, parts ...string) string {
+	attributes := []string{}
+	for _, part := range parts {
+		attributes = append(attributes, strings.ToLower(part))
+	}
+	keystring, err := stub.CreateCompositeKey(namespace, attributes)
+	if err != nil {
+		return ""
+	}
+	return keystring
+}
+
+//Dash separated key the Asset was stored under before composite keys, empty if there is none
+func legacyKey(stub shim.ChaincodeStubInterface, keystring string) string {
+	if keystring == "" || keystring[0] != 0 {
+		return ""
+	}
+	namespace, parts, err := stub.SplitCompositeKey(keystring)
+	if err != nil {
+		return ""
+	}
+	return common.Key(append([]string{namespace}, parts...)...)
+}
+
+//Compatibility reader: get an Asset by its composite key, falling back to its legacy key until it is migrated
+func getState(stub shim.ChaincodeStubInterface, keystring string) ([]byte, error) {
+	value, err := stub.GetState(keystring)
+	if err != nil || value != nil {
+		return value, err
+	}
+	if legacy := legacyKey(stub, keystring); legacy != "" {
+		return stub.GetState(legacy)
+	}
+	return nil, nil
+}
+
+//Store an Asset under its composite key, removing the copy under its legacy key
+func putState(stub shim.ChaincodeStubInterface, keystring string, value []byte) error {
+	if err := stub.PutState(keystring, value); err != nil {
+		return err
+	}
+	return delLegacyState(stub, keystring)
+}
+
+//Delete an Asset under both key formats
+func delState(stub shim.ChaincodeStubInterface, keystring string) error {
+	if err := stub.DelState(keystring); err != nil {
+		return err
+	}
+	return delLegacyState(stub, keystring)
+}
+
+func delLegacyState(stub shim.ChaincodeStubInterface, keystring string) error {
+	legacy := legacyKey(stub, keystring)
+	if legacy == "" {
+		return nil
+	}
+	if value, err := stub.GetState(legacy); err != nil || value == nil {
+		return err
+	}
+	return stub.DelState(legacy)
+}
+
+//Get the Participant bound to the identity that created the transaction
+func getInvokingParticipant(stub shim.ChaincodeStubInterface) (string, error) {
+	identity, err := getCreatorIdentity(stub)
+	if err != nil {
+		return "", err
+	}
+
+	//Get the Identity binding
+	value, geterr := getState(stub, identityKey(stub, identity))
+	if geterr != nil || value == nil {
+		return "", fmt.Errorf("identity %s/%s is not enrolled", identity.MSPID, identity.EnrollmentID)
+	}
+	participantIdentity := ParticipantIdentity{}
+	json.Unmarshal(value, &participantIdentity)
+
+	//Check if the bound Participant still exists
+	if value, geterr := getState(stub, assetKey(stub, "PARTICIPANT", participantIdentity.ParticipantID)); geterr != nil || value == nil {
+		return "", fmt.Errorf("participant %s does not exist", participantIdentity.ParticipantID)
+	}
+	return participantIdentity.ParticipantID, nil
+}
+
+//Check the Participant may read the Asset under the keys, as customQueries would return it.
+//A deleted Asset is judged on its last version, so its owners keep reading its history.
+func readableKeys(stub shim.ChaincodeStubInterface, participantID string, keys ...string) (bool, error) {
+	value, err := getState(stub, keys[0])
+	if err != nil {
+		return false, err
+	}
+	if value == nil {
+		rawValue := func(keystring string, value []byte) interface{} { return json.RawMessage(value) }
+		records, err := common.GetHistory(stub, common.HistoryOptions{}, rawValue, keys...)
+		if err != nil {
+			return false, err
+		}
+		for i := len(records) - 1; i >= 0 && value == nil; i-- {
+			if !records[i].IsDelete {
+				value = records[i].Value.(json.RawMessage)
+			}
+		}
+	}
+	return value != nil && common.ReadableBy(assetSchemas, value, participantID), nil
+}
+
+//Arguments of the list functions, the Owner defaults to the invoking Participant
+type ListData struct {
+	Owner        string `json:"Owner"`
+	MaterialID   string `json:"MaterialID,omitempty"`
+	SalesOrderID string `json:"SalesOrderID,omitempty"`
+	Days         int    `json:"Days,omitempty"` // Expiry horizon of listExpiringBatches
+}
+
+//Read the optional arguments of a list function, return error if the invoker is not enrolled
+func listArguments(stub shim.ChaincodeStubInterface, args []string) (ListData, error) {
+	queryData := ListData{}
+	if len(args) > 0 && args[0] != "" {
+		if err := json.Unmarshal([]byte(args[0]), &queryData); err != nil {
+			return queryData, fmt.Errorf("Invalid Data - Check Payload")
+		}
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return queryData, fmt.Errorf("Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	if queryData.Owner == "" {
+		queryData.Owner = participantID
+	}
+	return queryData, nil
+}
+
+//Key and value of a listed record
+type ListRecord struct {
+	Key    string          `json:"Key"`
+	Record json.RawMessage `json:"Record"`
+}
+
+//List the records under a namespace and key prefix with a range scan, supported by LevelDB and CouchDB
+//Assets not migrated yet are listed from their legacy keys as well
+func listRecords(stub shim.ChaincodeStubInterface, namespace string, parts ...string) ([]ListRecord, error) {
+	records := []ListRecord{}
+	for _, list := range []func() ([]byte, error){
+		func() ([]byte, error) { return common.PartialKeyExecution(stub, namespace, parts...) },
+		func() ([]byte, error) { return common.PrefixExecution(stub, append([]string{namespace}, parts...)...) },
+	} {
+		listResults, err := list()
+		if err != nil {
+			return nil, err
+		}
+		listRecords := []ListRecord{}
+		json.Unmarshal(listResults, &listRecords)
+		records = append(records, listRecords...)
+	}
+	return records, nil
+}
+
+//Respond with the records under a namespace and key prefix
+func listAssets(stub shim.ChaincodeStubInterface, operation string, namespace string, parts ...string) peer.Response {
+	records, err := listRecords(stub, namespace, parts...)
+	if err != nil {
+		return shim.Error("Invoke Error (" + operation + "): Error while fetching data from Blockchain")
+	}
+	jsonBytes, _ := json.Marshal(records)
+	return shim.Success(jsonBytes)
+}
+
+//Structs the versions of each namespace are decoded into
+var historyRecords = map[string]func() interface{}{
+	"PARTICIPANT":     func() interface{} { return &Participant{} },
+	"MATERIAL":        func() interface{} { return &Material{} },
+	"PURCHASEORDER":   func() interface{} { return &PurchaseOrder{} },
+	"PRODUCTIONORDER": func() interface{} { return &ProductionOrder{} },
+	"BATCH":           func() interface{} { return &Batch{} },
+	"SALESORDER":      func() interface{} { return &SalesOrder{} },
+	"DELIVERY":        func() interface{} { return &Delivery{} },
+	"SHIPMENT":        func() interface{} { return &Shipment{} },
+}
+
+//Namespace of a composite or legacy key, empty if the key belongs to no namespace
+func keyNamespace(stub shim.ChaincodeStubInterface, keystring string) string {
+	if keystring != "" && keystring[0] == 0 {
+		namespace, _, _ := stub.SplitCompositeKey(keystring)
+		return namespace
+	}
+	for namespace := range keyFields {
+		if strings.HasPrefix(keystring, assetKeyPrefix(namespace)) {
+			return namespace
+		}
+	}
+	return ""
+}
+
+// Get Transactions History From Blockchain - Arguments: Key, Options (optional)
+// Key: a raw key, or {"Namespace": "BATCH", "IDs": ["Owner", "MaterialID", "BatchNumber"]}
+// Options: {"From": RFC3339 time, "To": RFC3339 time, "ChangesOnly": true to keep the changed fields only}
+func (t *Testing1) getHistory(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+	//Define the structure for an Asset Key as argument
+	type QueryData struct {
+		Namespace string   `json:"Namespace"`
+		IDs       []string `json:"IDs"`
+	}
+
+	//Get Key, the history of an Asset not migrated yet is under its legacy key
+	keys := []string{}
+	queryData := QueryData{}
+	if err := json.Unmarshal([]byte(args[0]), &queryData); err == nil && queryData.Namespace != "" {
+		keystring := assetKey(stub, strings.ToUpper(queryData.Namespace), queryData.IDs...)
+		keys = append(keys, keystring)
+		if legacy := legacyKey(stub, keystring); legacy != "" {
+			keys = append(keys, legacy)
+		}
+	} else if args[0] != "" && args[0][0] == 0 {
+		keys = append(keys, args[0])
+	} else {
+		keys = append(keys, common.Key(args[0]))
+	}
+	//Get Options
+	options := common.HistoryOptions{}
+	if len(args) > 1 {
+		var opterr error
+		if options, opterr = common.ParseHistoryOptions(args[1]); opterr != nil {
+			return shim.Error("Invoke Error (Get History): Invalid Options - " + opterr.Error())
+		}
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Get History): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+	//Check the invoking Participant may read the Asset
+	if readable, readerr := readableKeys(stub, participantID, keys...); readerr != nil {
+		return shim.Error("Invoke Error (Get History): Error while fetching history")
+	} else if !readable {
+		return shim.Error("Invoke Error (Get History): Asset Not Found")
+	}
+
+	decode := func(keystring string, value []byte) interface{} {
+		return common.DecodeValue(value, historyRecords[keyNamespace(stub, keystring)])
+	}
+	historyResult, err := common.GetHistoryExecution(stub, options, decode, keys...)
+	if err != nil {
+		return shim.Error("Invoke Error (Get History): Error while fetching history")
+	}
+	return shim.Success(historyResult)
+}
+
+// Custom Queries - Arguments: Typed Query, Page Size (optional), Bookmark (optional)
+func (t *Testing1) customQueries(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Checks appropriate number of arguments in incoming invoke request
+	if len(args) < 1 {
+		return shim.Error("Invoke Error: Incorrect number of arguments - One Argument expected")
+	}
+	//Get Typed Query
+	query := common.TypedQuery{}
+	if err := json.Unmarshal([]byte(args[0]), &query); err != nil || query.AssetType == "" {
+		return shim.Error("Invoke Error (Custom Query): Invalid Data - A typed query with an AssetType is expected")
+	}
+	//Get Invoking Participant from the transaction creator identity, return error if not enrolled.
+	participantID, iderr := getInvokingParticipant(stub)
+	if iderr != nil {
+		return shim.Error("Invoke Error (Custom Query): Invoking Participant Does Not Exists! Please Enroll Participant")
+	}
+
+	//Build the selector, limited to the records of the invoking Participant
+	selector, selerr := participantsOnly(query).Selector(assetSchemas, participantID)
+	if selerr != nil {
+		return shim.Error("Invoke Error (Custom Query): Invalid Query - " + selerr.Error())
+	}
+
+	//Get Page Size and Bookmark, the payload Limit and Bookmark take precedence
+	pageSize, bookmark, pageerr := common.PageArguments(args)
+	if pageerr != nil {
+		return shim.Error("Invoke Error (Custom Query): " + pageerr.Error())
+	}
+	if query.Bookmark != "" {
+		bookmark = query.Bookmark
+	}
+
+	queryResults, err := common.QueryExecutionWithPagination(stub, selector, query.PageSize(pageSize), bookmark)
+	if err != nil {
+		return shim.Error("Invoke Error (Custom Query): Error while fetching Query")
+	}
+	return shim.Success(queryResults)
+}
+
+//Identity bindings are stored in the PARTICIPANT namespace without a ParticipantType,
+//a query on Participants only matches the records that have one
+func participantsOnly(query common.TypedQuery) common.TypedQuery {
+	if strings.ToUpper(strings.TrimSpace(query.AssetType)) != "PARTICIPANT" {
+		return query
+	}
+	filters := map[string]interface{}{"ParticipantType": map[string]interface{}{"$gt": nil}}
+	for field, value := range query.Filters {
+		filters[field] = value
+	}
+	query.Filters = filters
+	return query
+}
+
+// Migrate the Assets stored under legacy dash separated keys to composite keys (Admin only)
+// Arguments: optional JSON {"Limit": maximum number of Assets migrated by the transaction}
+func (t *Testing1) migrateKeys(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	//Define the structure for expected incoming JSON as argument
+	type QueryData struct {
+		Limit int `json:"Limit"`
+	}
+
+	//Get Data
+	queryData := QueryData{}
+	if len(args) > 0 && args[0] != "" {
+		if err := json.Unmarshal([]byte(args[0]), &queryData); err != nil || queryData.Limit < 0 {
+			return shim.Error("Invoke Error (Migrate Keys): Invalid Data - Check Payload")
+		}
+	}
+	//Only an admin identity may rewrite the keys of every Participant
+	identity, iderr := getCreatorIdentity(stub)
+	if iderr != nil || !identity.IsAdmin() {
+		return shim.Error("Invoke Error (Migrate Keys): Not Authorized to Migrate Keys")
+	}
+
+	//Define the structure for the migration report
+	type Report struct {
+		Migrated  int      `json:"Migrated"`
+		Skipped   []string `json:"Skipped,omitempty"`
+		Remaining bool     `json:"Remaining"`
+	}
+	report := Report{}
+
+	//Go through the namespaces in a fixed order so a limited migration resumes where it stopped
+	namespaces := []string{}
+	for namespace := range keyFields {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		//Read the whole range before writing, the iterator must not see its own writes
+		legacyResults, err := common.PrefixExecution(stub, namespace)
+		if err != nil {
+			return shim.Error("Invoke Error (Migrate Keys): Error while fetching data from Blockchain")
+		}
+		legacyRecords := []struct {
+			Key    string                 `json:"Key"`
+			Record map[string]interface{} `json:"Record"`
+		}{}
+		json.Unmarshal(legacyResults, &legacyRecords)
+
+		for _, legacy := range legacyRecords {
+			keystring := migratedKey(stub, namespace, legacy.Key, legacy.Record)
+			if keystring == "" {
+				report.Skipped = append(report.Skipped, legacy.Key)
+				continue
+			}
+			if queryData.Limit > 0 && report.Migrated == queryData.Limit {
+				report.Remaining = true
+				break
+			}
+
+			//An Asset already stored under its composite key keeps that copy
+			value, geterr := stub.GetState(keystring)
+			if geterr != nil {
+				return shim.Error("Invoke Error (Migrate Keys): Error while fetching data from Blockchain")
+			}
+			if value == nil {
+				value, _ = json.Marshal(legacy.Record)
+				if puterr := stub.PutState(keystring, value); puterr != nil {
+					return shim.Error("Invoke Error (Migrate Keys): Error while storing data into Blockchain")
+				}
+			}
+			if delerr := stub.DelState(legacy.Key); delerr != nil {
+				return shim.Error("Invoke Error (Migrate Keys): Error while deleting data from Blockchain")
+			}
+			report.Migrated++
+		}
+		if report.Remaining {
+			break
+		}
+	}
+
+	jsonBytes, _ := json.Marshal(report)
+	return shim.Success(jsonBytes)
+}
+
+//Composite key of an Asset stored under a legacy key, empty if the key cannot be rebuilt from the Asset.
+//The ID parts come from the Asset, the legacy key alone is ambiguous.
+func migratedKey(stub shim.ChaincodeStubInterface, namespace string, legacy string, record map[string]interface{}) string {
+	parts := []string{}
+	for _, field := range keyFields[namespace] {
+		parts = append(parts, fmt.Sprint(record[field]))
+	}
+	keystring := assetKey(stub, namespace, parts...)
+	if namespace == "PARTICIPANT" && legacyKey(stub, keystring) != legacy {
+		//Identity bindings share the PARTICIPANT namespace
+		keystring = identityKey(stub, common.Identity{MSPID: fmt.Sprint(record["MSPID"]), EnrollmentID: fmt.Sprint(record["EnrollmentID"])})
+	}
+	if keystring == "" || legacyKey(stub, keystring) != legacy {
+		return ""
+	}
+	return keystring
+}
+
+//Legacy key prefix of the Assets of a namespace
+func assetKeyPrefix(namespace string) string {
+	return common.Key(namespace) + common.KeySeparator
+}
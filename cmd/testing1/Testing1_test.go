@@ -0,0 +1,489 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/rosolanki/EventsAppCloud/common"
+)
+
+// fixture wraps a MockStub running Testing1 and signs every transaction as the current identity
+type fixture struct {
+	t        *testing.T
+	stub     *shim.MockStub
+	tx       int
+	identity common.Identity
+}
+
+func newFixture(t *testing.T) *fixture {
+	f := &fixture{t: t, stub: shim.NewMockStub("testing1", new(Testing1))}
+	getCreatorIdentity = func(stub shim.ChaincodeStubInterface) (common.Identity, error) {
+		if f.identity.EnrollmentID == "" {
+			return f.identity, fmt.Errorf("no identity")
+		}
+		return f.identity, nil
+	}
+	t.Cleanup(func() { getCreatorIdentity = common.GetIdentity })
+	if res := f.stub.MockInit("init", [][]byte{[]byte("init")}); res.Status != shim.OK {
+		t.Fatalf("Init failed: %s", res.Message)
+	}
+	return f
+}
+
+// as switches the identity used for the following transactions
+func (f *fixture) as(mspID string, enrollmentID string) *fixture {
+	f.identity = common.Identity{MSPID: mspID, EnrollmentID: enrollmentID}
+	return f
+}
+
+func (f *fixture) invoke(function string, payload interface{}) peer.Response {
+	f.t.Helper()
+	args := [][]byte{[]byte(function)}
+	switch value := payload.(type) {
+	case string:
+		args = append(args, []byte(value))
+	default:
+		jsonBytes, _ := json.Marshal(value)
+		args = append(args, jsonBytes)
+	}
+	f.tx++
+	return f.stub.MockInvoke(fmt.Sprintf("tx%d", f.tx), args)
+}
+
+func (f *fixture) mustSucceed(function string, payload interface{}) peer.Response {
+	f.t.Helper()
+	res := f.invoke(function, payload)
+	if res.Status != shim.OK {
+		f.t.Fatalf("%s failed: %s", function, res.Message)
+	}
+	return res
+}
+
+func (f *fixture) mustFail(function string, payload interface{}) peer.Response {
+	f.t.Helper()
+	res := f.invoke(function, payload)
+	if res.Status == shim.OK {
+		f.t.Fatalf("%s should have failed", function)
+	}
+	return res
+}
+
+func (f *fixture) enroll(participantID string, participantType string) {
+	f.t.Helper()
+	f.mustSucceed("createParticipant", map[string]string{"ParticipantID": participantID, "ParticipantType": participantType, "OrgName": participantID})
+}
+
+func TestEnrollmentBindsIdentity(t *testing.T) {
+	f := newFixture(t)
+	f.as("Org1MSP", "user1").enroll("IMPORTER01", "IMPORTER")
+
+	participant := Participant{}
+	json.Unmarshal(f.mustSucceed("getParticipant", "IMPORTER01").Payload, &participant)
+	if participant.MSPID != "Org1MSP" || participant.EnrollmentID != "user1" {
+		t.Fatalf("unexpected participant %+v", participant)
+	}
+
+	binding := ParticipantIdentity{}
+	json.Unmarshal(f.stub.State[identityKey(f.stub, f.identity)], &binding)
+	if binding.ParticipantID != "IMPORTER01" || binding.Asset_Type != "PARTICIPANT" {
+		t.Fatalf("unexpected binding %+v", binding)
+	}
+	f.as("Org1MSP", "user2").mustFail("createParticipant", map[string]string{"ParticipantType": "IMPORTER"})
+	f.as("Org1MSP", "user1")
+
+	// Same identity cannot enroll twice, same participant cannot be claimed by another identity
+	f.mustFail("createParticipant", map[string]string{"ParticipantID": "IMPORTER02", "ParticipantType": "IMPORTER"})
+	f.as("Org2MSP", "user1").mustFail("createParticipant", map[string]string{"ParticipantID": "IMPORTER01", "ParticipantType": "IMPORTER"})
+}
+
+func TestUnenrolledIdentityIsRejected(t *testing.T) {
+	f := newFixture(t)
+	f.as("Org1MSP", "user1").enroll("IMPORTER01", "IMPORTER")
+
+	f.as("Org1MSP", "stranger").mustFail("getParticipant", "IMPORTER01")
+	f.as("", "").mustFail("createParticipant", map[string]string{"ParticipantID": "IMPORTER02", "ParticipantType": "IMPORTER"})
+}
+
+func TestOwnershipUsesVerifiedIdentity(t *testing.T) {
+	f := newFixture(t)
+	f.as("Org1MSP", "importer").enroll("IMPORTER01", "IMPORTER")
+	f.as("Org2MSP", "vendor").enroll("VENDOR01", "GROWER")
+
+	order := map[string]interface{}{"PurchaseOrderID": "PO1", "Vendor": "VENDOR01", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5}
+	f.as("Org1MSP", "importer").mustSucceed("createPurchaseOrder", order)
+
+	purchaseOrder := PurchaseOrder{}
+	json.Unmarshal(f.mustSucceed("getPurchaseOrder", map[string]string{"Owner": "IMPORTER01", "PurchaseOrderID": "PO1"}).Payload, &purchaseOrder)
+	if purchaseOrder.Owner != "IMPORTER01" {
+		t.Fatalf("expected owner IMPORTER01, got %s", purchaseOrder.Owner)
+	}
+
+	// The vendor cannot delete the importer's order, the importer can
+	f.as("Org2MSP", "vendor").mustFail("deletePurchaseOrder", map[string]string{"Owner": "IMPORTER01", "PurchaseOrderID": "PO1"})
+	f.as("Org1MSP", "importer").mustSucceed("deletePurchaseOrder", map[string]string{"Owner": "IMPORTER01", "PurchaseOrderID": "PO1"})
+}
+
+func TestReadsByKeyAreScopedToOwners(t *testing.T) {
+	f := newFixture(t)
+	f.as("Org1MSP", "importer").enroll("IMPORTER01", "IMPORTER")
+	f.as("Org2MSP", "vendor").enroll("VENDOR01", "GROWER")
+	f.as("Org2MSP", "other").enroll("GROWER02", "GROWER")
+
+	order := map[string]string{"Owner": "IMPORTER01", "PurchaseOrderID": "PO1"}
+	batch := map[string]string{"Owner": "VENDOR01", "MaterialID": "MAT01", "BatchNumber": "B1"}
+	f.as("Org1MSP", "importer").mustSucceed("createPurchaseOrder", map[string]interface{}{"PurchaseOrderID": "PO1", "Vendor": "VENDOR01", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5})
+	f.as("Org2MSP", "vendor").mustSucceed("reportProductionOrderGR", map[string]interface{}{"ProductionOrderID": "PRO1", "MaterialID": "MAT01", "Quantity": 10, "BatchNumber": "B1"})
+
+	// Both parties read the Purchase Order, only the owner reads the Batch
+	f.mustSucceed("getPurchaseOrder", order)
+	f.mustSucceed("getBatch", batch)
+	f.as("Org1MSP", "importer").mustSucceed("getPurchaseOrder", order)
+	f.mustFail("getBatch", batch)
+	f.as("Org2MSP", "other").mustFail("getPurchaseOrder", order)
+	f.mustSucceed("getParticipant", "IMPORTER01")
+
+	// MockStub has no history, a readable Asset gets as far as fetching it
+	batchKey := `{"Namespace":"BATCH","IDs":["VENDOR01","MAT01","B1"]}`
+	if res := f.mustFail("getHistory", batchKey); !strings.Contains(res.Message, "Asset Not Found") {
+		t.Fatalf("expected the batch history to be hidden, got %q", res.Message)
+	}
+	if res := f.as("Org2MSP", "vendor").mustFail("getHistory", batchKey); !strings.Contains(res.Message, "Error while fetching history") {
+		t.Fatalf("expected the batch history to be read, got %q", res.Message)
+	}
+}
+
+func TestDeleteParticipantRemovesBinding(t *testing.T) {
+	f := newFixture(t)
+	f.as("Org1MSP", "user1").enroll("IMPORTER01", "IMPORTER")
+	f.mustSucceed("deleteParticipant", "IMPORTER01")
+	if _, exists := f.stub.State[identityKey(f.stub, f.identity)]; exists {
+		t.Fatal("identity binding should be deleted with the participant")
+	}
+	f.enroll("IMPORTER02", "IMPORTER")
+}
+
+func TestCustomQueriesValidatesTypedQuery(t *testing.T) {
+	f := newFixture(t)
+	f.as("Org1MSP", "importer").enroll("IMPORTER01", "IMPORTER")
+
+	for payload, message := range map[string]string{
+		`{"selector":{"Asset_Type":"BATCH"}}`:                     "AssetType",
+		`{"AssetType":"BATCH","Filters":{"Vendor":"IMPORTER01"}}`: "Invalid Query",
+		`{"AssetType":"SHIPMENT","Sort":[{"Field":"Owner"}]}`:     "Error while fetching Query",
+	} {
+		if res := f.mustFail("customQueries", payload); !strings.Contains(res.Message, message) {
+			t.Fatalf("%s: expected %q in %q", payload, message, res.Message)
+		}
+	}
+	f.as("Org1MSP", "stranger").mustFail("customQueries", `{"AssetType":"BATCH"}`)
+}
+
+func TestParticipantQueriesSkipIdentityBindings(t *testing.T) {
+	for data, expected := range map[string]string{
+		`{"AssetType":"participant","Filters":{"OrgName":"Org1"}}`:           `{"selector":{"Asset_Type":"PARTICIPANT","OrgName":"Org1","ParticipantType":{"$gt":null}}}`,
+		`{"AssetType":"PARTICIPANT","Filters":{"ParticipantType":"GROWER"}}`: `{"selector":{"Asset_Type":"PARTICIPANT","ParticipantType":"GROWER"}}`,
+		`{"AssetType":"MATERIAL"}`:                                           `{"selector":{"Asset_Type":"MATERIAL"}}`,
+	} {
+		query := common.TypedQuery{}
+		json.Unmarshal([]byte(data), &query)
+		if selector, err := participantsOnly(query).Selector(assetSchemas, "IMPORTER01"); err != nil || selector != expected {
+			t.Fatalf("%s: unexpected selector %s (%v)", data, selector, err)
+		}
+	}
+}
+
+func TestTypedQueriesAreIndexed(t *testing.T) {
+	indexes, err := common.ReadIndexes(common.IndexFolder)
+	if err != nil || len(indexes) == 0 {
+		t.Fatalf("cannot read the index definitions: %v", err)
+	}
+	covering := func(data string) string {
+		t.Helper()
+		query := common.TypedQuery{}
+		json.Unmarshal([]byte(data), &query)
+		selector, err := participantsOnly(query).Selector(assetSchemas, "IMPORTER01")
+		if err != nil {
+			t.Fatalf("%s: %s", data, err)
+		}
+		index, found := common.CoveringIndex(selector, indexes)
+		if !found {
+			t.Fatalf("%s is not covered by an index", data)
+		}
+		return index.Name
+	}
+
+	// Every asset type and field filter
+	for assetType, schema := range assetSchemas {
+		covering(`{"AssetType":"` + assetType + `"}`)
+		for field := range schema.Fields {
+			if field != "Asset_Type" {
+				covering(`{"AssetType":"` + assetType + `","Filters":{"` + field + `":"X"}}`)
+			}
+		}
+	}
+
+	// The common queries use their dedicated index
+	for data, index := range map[string]string{
+		`{"AssetType":"PURCHASEORDER","Filters":{"Owner":"IMPORTER01","Status":"OPEN"}}`:  "indexOwnerStatus",
+		`{"AssetType":"PURCHASEORDER","Filters":{"Vendor":"IMPORTER01","Status":"OPEN"}}`: "indexVendorStatus",
+		`{"AssetType":"SALESORDER","Filters":{"POOwner":"IMPORTER01","Status":"OPEN"}}`:   "indexPOOwnerStatus",
+		`{"AssetType":"BATCH","Filters":{"MaterialID":"MAT01"}}`:                          "indexOwnerMaterial",
+		`{"AssetType":"SHIPMENT","Filters":{"Status":"OPEN"}}`:                            "indexOwnerStatus",
+		`{"AssetType":"SHIPMENT","Sort":[{"Field":"Status","Direction":"desc"}]}`:         "indexOwnerStatus",
+		`{"AssetType":"MATERIAL","Filters":{"MaterialID":"MAT01"}}`:                       "indexMaterial",
+	} {
+		if covering(data) != index {
+			t.Fatalf("%s: expected %s, got %s", data, index, covering(data))
+		}
+	}
+}
+
+func TestListFunctionsUseRangeScans(t *testing.T) {
+	f := newFixture(t)
+	f.as("Org1MSP", "importer").enroll("IMPORTER01", "IMPORTER")
+	f.as("Org2MSP", "grower").enroll("GROWER01", "GROWER")
+
+	f.as("Org1MSP", "importer")
+	for _, orderID := range []string{"PO1", "PO2"} {
+		f.mustSucceed("createPurchaseOrder", map[string]interface{}{"PurchaseOrderID": orderID, "Vendor": "GROWER01", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5})
+	}
+	f.as("Org2MSP", "grower")
+	for i, materialID := range []string{"MAT01", "MAT01", "MAT02"} {
+		f.mustSucceed("reportProductionOrderGR", map[string]interface{}{"ProductionOrderID": fmt.Sprintf("PRO%d", i), "MaterialID": materialID, "Quantity": 10, "BatchNumber": fmt.Sprintf("B%d", i)})
+	}
+
+	count := func(function string, payload interface{}) int {
+		t.Helper()
+		records := []map[string]interface{}{}
+		if err := json.Unmarshal(f.mustSucceed(function, payload).Payload, &records); err != nil {
+			t.Fatalf("%s: %s", function, err)
+		}
+		return len(records)
+	}
+	for _, list := range []struct {
+		function string
+		payload  interface{}
+		expected int
+	}{
+		{"listPurchaseOrders", map[string]string{"Owner": "IMPORTER01"}, 2},
+		{"listPurchaseOrders", "", 0},
+		{"listProductionOrders", "", 3},
+		{"listBatches", "", 3},
+		{"listBatches", map[string]string{"MaterialID": "MAT01"}, 2},
+		{"listBatches", map[string]string{"Owner": "IMPORTER01"}, 0},
+		{"listMaterials", "", 2},
+	} {
+		if found := count(list.function, list.payload); found != list.expected {
+			t.Fatalf("%s %v: expected %d records, got %d", list.function, list.payload, list.expected, found)
+		}
+	}
+
+	f.mustFail("listBatches", "not json")
+	f.as("Org1MSP", "stranger").mustFail("listPurchaseOrders", "")
+}
+
+//putLegacy stores an Asset under the dash separated key used before composite keys
+func (f *fixture) putLegacy(value interface{}, parts ...string) {
+	f.t.Helper()
+	jsonBytes, _ := json.Marshal(value)
+	f.stub.MockTransactionStart("legacy")
+	f.stub.PutState(common.Key(parts...), jsonBytes)
+	f.stub.MockTransactionEnd("legacy")
+}
+
+func TestCompositeKeysKeepDashedIDsApart(t *testing.T) {
+	f := newFixture(t)
+	f.as("Org1MSP", "grower").enroll("a-b", "GROWER")
+	f.mustSucceed("reportProductionOrderGR", map[string]interface{}{"ProductionOrderID": "PRO1", "MaterialID": "c", "Quantity": 10, "BatchNumber": "B1"})
+
+	// Owner a with material b-c used to share the key of owner a-b with material c
+	f.mustFail("getBatch", map[string]string{"Owner": "a", "MaterialID": "b-c", "BatchNumber": "B1"})
+	f.mustSucceed("getBatch", map[string]string{"Owner": "a-b", "MaterialID": "c", "BatchNumber": "B1"})
+}
+
+func TestMigrateKeys(t *testing.T) {
+	f := newFixture(t)
+	f.putLegacy(Participant{Asset_Type: "PARTICIPANT", ParticipantID: "GROWER01", ParticipantType: "GROWER", MSPID: "Org1MSP", EnrollmentID: "grower"}, "PARTICIPANT", "GROWER01")
+	f.putLegacy(ParticipantIdentity{Asset_Type: "PARTICIPANT", MSPID: "Org1MSP", EnrollmentID: "grower", ParticipantID: "GROWER01"}, "PARTICIPANT", "IDENTITY", "Org1MSP", "grower")
+	for _, batchNumber := range []string{"B1", "B2"} {
+		f.putLegacy(Batch{Asset_Type: "BATCH", BatchNumber: batchNumber, MaterialID: "MAT01", Owner: "GROWER01", AvailableQuantity: 10}, "BATCH", "GROWER01", "MAT01", batchNumber)
+	}
+	f.putLegacy(Batch{Asset_Type: "BATCH", BatchNumber: "B3", MaterialID: "MAT01", Owner: "GROWER01"}, "BATCH", "GROWER01", "B3")
+
+	// Legacy keys are read during the transition
+	f.as("Org1MSP", "grower")
+	f.mustSucceed("getBatch", map[string]string{"Owner": "GROWER01", "MaterialID": "MAT01", "BatchNumber": "B1"})
+	var batches []interface{}
+	json.Unmarshal(f.mustSucceed("listBatches", map[string]string{"MaterialID": "MAT01"}).Payload, &batches)
+	if len(batches) != 2 {
+		t.Fatalf("expected the two legacy batches of MAT01, got %d", len(batches))
+	}
+
+	// Only an admin migrates, in limited steps
+	f.mustFail("migrateKeys", "")
+	f.identity = common.Identity{MSPID: "Org1MSP", EnrollmentID: "admin", Type: "admin"}
+	type Report struct {
+		Migrated  int
+		Skipped   []string
+		Remaining bool
+	}
+	report := Report{}
+	json.Unmarshal(f.mustSucceed("migrateKeys", map[string]int{"Limit": 1}).Payload, &report)
+	if report.Migrated != 1 || !report.Remaining {
+		t.Fatalf("unexpected first step %+v", report)
+	}
+	json.Unmarshal(f.mustSucceed("migrateKeys", "").Payload, &report)
+	if report.Migrated != 3 || report.Remaining || len(report.Skipped) != 1 || report.Skipped[0] != "batch-grower01-b3" {
+		t.Fatalf("unexpected last step %+v", report)
+	}
+
+	for key := range f.stub.State {
+		if key[0] != 0 && key != "batch-grower01-b3" {
+			t.Fatalf("legacy key %s left after the migration", key)
+		}
+	}
+	binding := ParticipantIdentity{}
+	json.Unmarshal(f.stub.State[identityKey(f.stub, common.Identity{MSPID: "Org1MSP", EnrollmentID: "grower"})], &binding)
+	if binding.ParticipantID != "GROWER01" || binding.Asset_Type != "PARTICIPANT" {
+		t.Fatalf("unexpected migrated binding %+v", binding)
+	}
+	f.as("Org1MSP", "grower").mustSucceed("getParticipant", "GROWER01")
+	f.mustSucceed("getBatch", map[string]string{"Owner": "GROWER01", "MaterialID": "MAT01", "BatchNumber": "B2"})
+}
+
+func TestHistoryKeyNamespace(t *testing.T) {
+	f := newFixture(t)
+	for keystring, namespace := range map[string]string{
+		assetKey(f.stub, "BATCH", "GROWER01", "MAT01", "B1"):                      "BATCH",
+		identityKey(f.stub, common.Identity{MSPID: "Org1MSP", EnrollmentID: "u"}): "PARTICIPANT",
+		common.Key("PARTICIPANT", "IDENTITY", "Org1MSP", "u"):                     "PARTICIPANT",
+		common.Key("PARTICIPANT", "GROWER01"):                                     "PARTICIPANT",
+		"unknown-key":                                                             "",
+	} {
+		if found := keyNamespace(f.stub, keystring); found != namespace {
+			t.Fatalf("%q: expected namespace %q, got %q", keystring, namespace, found)
+		}
+	}
+
+	f.as("Org1MSP", "grower").enroll("GROWER01", "GROWER")
+	if res := f.stub.MockInvoke("history", [][]byte{[]byte("getHistory"), []byte(`{"Namespace":"PARTICIPANT","IDs":["GROWER01"]}`), []byte(`{"ChangesOnly":"yes"}`)}); !strings.Contains(res.Message, "Invalid Options") {
+		t.Fatalf("expected invalid options, got %q", res.Message)
+	}
+}
+
+func TestBatchShelfLife(t *testing.T) {
+	f := newFixture(t)
+	f.as("Org2MSP", "grower").enroll("GROWER01", "GROWER")
+
+	f.as("Org2MSP", "grower")
+	f.mustFail("reportProductionOrderGR", map[string]interface{}{"ProductionOrderID": "PRO0", "MaterialID": "MAT01", "Quantity": 10, "BatchNumber": "B0", "ExpiryDate": "soon"})
+	f.mustSucceed("reportProductionOrderGR", map[string]interface{}{"ProductionOrderID": "PRO1", "MaterialID": "MAT01", "Quantity": 10, "BatchNumber": "FRESH", "ProductionDate": "2099-01-01", "ShelfLifeDays": 10})
+	f.mustSucceed("reportProductionOrderGR", map[string]interface{}{"ProductionOrderID": "PRO2", "MaterialID": "MAT01", "Quantity": 10, "BatchNumber": "OLD", "ProductionDate": "2000-01-01", "ExpiryDate": "2000-01-05"})
+
+	batch := Batch{}
+	json.Unmarshal(f.mustSucceed("getBatch", map[string]string{"Owner": "GROWER01", "MaterialID": "MAT01", "BatchNumber": "FRESH"}).Payload, &batch)
+	if batch.ExpiryDate != "2099-01-11T00:00:00Z" {
+		t.Fatalf("expected the expiry derived from the shelf life %+v", batch)
+	}
+
+	expiring := []Batch{}
+	json.Unmarshal(f.mustSucceed("listExpiringBatches", map[string]interface{}{"Owner": "GROWER01", "Days": 30}).Payload, &expiring)
+	if len(expiring) != 1 || expiring[0].BatchNumber != "OLD" {
+		t.Fatalf("expected only the expired batch, got %+v", expiring)
+	}
+	f.mustFail("listExpiringBatches", map[string]interface{}{"Days": -1})
+
+	//An expired batch cannot be delivered
+	f.mustSucceed("createSalesOrder", map[string]interface{}{"SalesOrderID": "SO1", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5})
+	f.mustFail("createDelivery", map[string]interface{}{"DeliveryNumber": "D1", "SalesOrderID": "SO1", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5, "BatchNumber": "OLD"})
+	f.mustSucceed("createDelivery", map[string]interface{}{"DeliveryNumber": "D1", "SalesOrderID": "SO1", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5, "BatchNumber": "FRESH"})
+}
+
+func TestSensorReadingExcursion(t *testing.T) {
+	f := newFixture(t)
+	f.as("Org2MSP", "grower").enroll("GROWER01", "GROWER")
+
+	f.as("Org2MSP", "grower")
+	f.mustSucceed("reportProductionOrderGR", map[string]interface{}{"ProductionOrderID": "PRO1", "MaterialID": "MAT01", "Quantity": 10, "BatchNumber": "B1"})
+	f.mustFail("setTemperatureLimits", map[string]interface{}{"MaterialID": "MAT01", "MinTemperature": 8, "MaxTemperature": 2})
+	f.mustFail("setTemperatureLimits", map[string]interface{}{"MaterialID": "UNKNOWN", "MaxTemperature": 8})
+	f.mustSucceed("setTemperatureLimits", map[string]interface{}{"MaterialID": "MAT01", "MinTemperature": 2, "MaxTemperature": 8})
+	f.mustSucceed("createSalesOrder", map[string]interface{}{"SalesOrderID": "SO1", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5})
+	f.mustSucceed("createDelivery", map[string]interface{}{"DeliveryNumber": "D1", "SalesOrderID": "SO1", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5, "BatchNumber": "B1"})
+	f.mustSucceed("createShipment", map[string]interface{}{"ShipmentID": "SH1", "DeliveryNumber": "D1", "SalesOrderID": "SO1", "UnsignedReadings": true})
+
+	batch := func() Batch {
+		t.Helper()
+		batch := Batch{}
+		json.Unmarshal(f.mustSucceed("getBatch", map[string]string{"Owner": "GROWER01", "MaterialID": "MAT01", "BatchNumber": "B1"}).Payload, &batch)
+		return batch
+	}
+	f.mustFail("recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "Shock": 1})
+	f.mustFail("recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": 4, "Timestamp": "yesterday"})
+	f.mustSucceed("recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": 4, "Humidity": 55})
+	if batch().PotentialCompromised {
+		t.Fatalf("a reading within the limits must not flag the batch")
+	}
+	f.mustSucceed("recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": -1})
+
+	shipment := Shipment{}
+	json.Unmarshal(f.mustSucceed("getShipment", "SH1").Payload, &shipment)
+	if len(shipment.SensorReadings) != 2 || len(shipment.Excursions) != 1 || shipment.Excursions[0].TemperatureCelsius != -1 {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+	if !batch().PotentialCompromised {
+		t.Fatalf("expected the source batch flagged")
+	}
+}
+
+func TestDeviceSignedSensorReadings(t *testing.T) {
+	f := newFixture(t)
+	f.as("Org2MSP", "importer").enroll("IMPORTER01", "IMPORTER")
+	f.as("Org2MSP", "grower").enroll("GROWER01", "GROWER")
+
+	f.mustSucceed("reportProductionOrderGR", map[string]interface{}{"ProductionOrderID": "PRO1", "MaterialID": "MAT01", "Quantity": 10, "BatchNumber": "B1"})
+	f.mustSucceed("createSalesOrder", map[string]interface{}{"SalesOrderID": "SO1", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5})
+	f.mustSucceed("createDelivery", map[string]interface{}{"DeliveryNumber": "D1", "SalesOrderID": "SO1", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5, "BatchNumber": "B1"})
+	f.mustSucceed("createShipment", map[string]interface{}{"ShipmentID": "SH1", "DeliveryNumber": "D1", "SalesOrderID": "SO1"})
+
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	encoded := base64.StdEncoding.EncodeToString(publicKey)
+	f.mustFail("registerDevice", map[string]string{"DeviceID": "DEV1", "KeyType": "ECDSA", "PublicKey": encoded})
+	f.mustSucceed("registerDevice", map[string]string{"DeviceID": "DEV1", "KeyType": "ED25519", "PublicKey": encoded})
+	f.mustFail("registerDevice", map[string]string{"DeviceID": "DEV1", "KeyType": "ED25519", "PublicKey": encoded})
+
+	f.as("Org2MSP", "importer")
+	f.mustSucceed("registerDevice", map[string]string{"DeviceID": "DEV2", "KeyType": "ED25519", "PublicKey": encoded})
+	f.mustFail("attachDevice", map[string]string{"DeviceID": "DEV2", "ShipmentID": "SH1"})
+	f.as("Org2MSP", "grower")
+	f.mustSucceed("attachDevice", map[string]string{"DeviceID": "DEV1", "ShipmentID": "SH1"})
+	f.mustFail("attachDevice", map[string]string{"DeviceID": "DEV1", "ShipmentID": "SH1"})
+
+	//Without the opt-in an unsigned reading is rejected even before a Device is attached
+	f.mustSucceed("createSalesOrder", map[string]interface{}{"SalesOrderID": "SO2", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5})
+	f.mustSucceed("createDelivery", map[string]interface{}{"DeliveryNumber": "D2", "SalesOrderID": "SO2", "LineItemNumber": "10", "MaterialID": "MAT01", "Quantity": 5, "BatchNumber": "B1"})
+	f.mustSucceed("createShipment", map[string]interface{}{"ShipmentID": "SH2", "DeliveryNumber": "D2", "SalesOrderID": "SO2"})
+	f.mustFail("recordSensorReading", map[string]interface{}{"ShipmentID": "SH2", "TemperatureCelsius": 4})
+
+	reading := common.SensorReading{TemperatureCelsius: 4, Humidity: 55, Timestamp: "2020-01-01T10:00:00Z"}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, common.SignedMessage("DEV1", 1, reading.SignedFields("SH1")...)))
+	signed := map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": 4, "Humidity": 55, "Timestamp": reading.Timestamp, "DeviceID": "DEV1", "Sequence": 1, "Signature": signature}
+	f.mustFail("recordSensorReading", map[string]interface{}{"ShipmentID": "SH1", "TemperatureCelsius": 4})
+	f.mustSucceed("recordSensorReading", signed)
+	f.mustFail("recordSensorReading", signed)
+	signed["Sequence"], signed["Humidity"] = 2, 56
+	f.mustFail("recordSensorReading", signed)
+
+	shipment := Shipment{}
+	json.Unmarshal(f.mustSucceed("getShipment", "SH1").Payload, &shipment)
+	if len(shipment.SensorReadings) != 1 || len(shipment.Devices) != 1 {
+		t.Fatalf("unexpected shipment %+v", shipment)
+	}
+}
@@ -0,0 +1,128 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//********************************************************************************************************
+// Signed Readings of IoT Devices
+//********************************************************************************************************
+
+//Types of device keys
+const (
+	KeyTypeEd25519 = "ED25519"
+	KeyTypeECDSA   = "ECDSA"
+)
+
+//DeviceKey is the public key a device signs its readings with
+type DeviceKey struct {
+	KeyType   string `json:"KeyType"`   // ED25519 or ECDSA
+	PublicKey string `json:"PublicKey"` // PEM or base64 PKIX DER, a base64 raw 32 byte key for ED25519
+}
+
+//DeviceSignature is the signature of a reading by a registered device.
+//Sequence must grow with every reading of the device so that a reading cannot be replayed.
+type DeviceSignature struct {
+	DeviceID  string `json:"DeviceID,omitempty"`
+	Sequence  uint64 `json:"Sequence,omitempty"`
+	Signature string `json:"Signature,omitempty"` // base64, ASN.1 DER over the SHA-256 of the message for ECDSA
+}
+
+//SignedMessage is the canonical message a device signs: the device, the sequence and the reading fields joined by "|"
+func SignedMessage(deviceID string, sequence uint64, fields ...string) []byte {
+	return []byte(strings.Join(append([]string{deviceID, strconv.FormatUint(sequence, 10)}, fields...), "|"))
+}
+
+//FormatNumber writes a reading value in the shortest form that reads back the same value
+func FormatNumber(value float64, bitSize int) string {
+	return strconv.FormatFloat(value, 'f', -1, bitSize)
+}
+
+//publicKey decodes the key, PEM or base64
+func (key DeviceKey) publicKey() (interface{}, error) {
+	var der []byte
+	if block, _ := pem.Decode([]byte(key.PublicKey)); block != nil {
+		der = block.Bytes
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(key.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("PublicKey must be PEM or base64")
+		}
+		der = decoded
+	}
+	if strings.ToUpper(key.KeyType) == KeyTypeEd25519 && len(der) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(der), nil
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("PublicKey is not a PKIX public key: %s", err.Error())
+	}
+	return publicKey, nil
+}
+
+//Normalize checks the key matches its type and stores it as base64 PKIX DER
+func (key DeviceKey) Normalize() (DeviceKey, error) {
+	key.KeyType = strings.ToUpper(key.KeyType)
+	if key.KeyType != KeyTypeEd25519 && key.KeyType != KeyTypeECDSA {
+		return key, fmt.Errorf("KeyType must be ED25519 or ECDSA")
+	}
+	publicKey, err := key.publicKey()
+	if err != nil {
+		return key, err
+	}
+	switch publicKey.(type) {
+	case ed25519.PublicKey:
+		if key.KeyType != KeyTypeEd25519 {
+			return key, fmt.Errorf("PublicKey is not an %s key", key.KeyType)
+		}
+	case *ecdsa.PublicKey:
+		if key.KeyType != KeyTypeECDSA {
+			return key, fmt.Errorf("PublicKey is not an %s key", key.KeyType)
+		}
+	default:
+		return key, fmt.Errorf("PublicKey is not an %s key", key.KeyType)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(publicKey)
+	key.PublicKey = base64.StdEncoding.EncodeToString(der)
+	return key, nil
+}
+
+//Verify checks a base64 signature of a message
+func (key DeviceKey) Verify(message []byte, signature string) error {
+	signatureBytes, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("Signature must be base64")
+	}
+	publicKey, err := key.publicKey()
+	if err != nil {
+		return err
+	}
+	valid := false
+	switch typed := publicKey.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(typed, message, signatureBytes)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		valid = ecdsa.VerifyASN1(typed, digest[:], signatureBytes)
+	}
+	if !valid {
+		return fmt.Errorf("Invalid Signature")
+	}
+	return nil
+}
+
+//Check verifies a reading signed by a device whose last accepted sequence is lastSequence
+func (signature DeviceSignature) Check(key DeviceKey, lastSequence uint64, fields ...string) error {
+	if signature.Sequence <= lastSequence {
+		return fmt.Errorf("Sequence %d of Device %s must be above %d, the reading is replayed or out of order", signature.Sequence, signature.DeviceID, lastSequence)
+	}
+	return key.Verify(SignedMessage(signature.DeviceID, signature.Sequence, fields...), signature.Signature)
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+)
+
+func TestDeviceKeyNormalize(t *testing.T) {
+	edPublic, _, _ := ed25519.GenerateKey(rand.Reader)
+	ecPrivate, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edDER, _ := x509.MarshalPKIXPublicKey(edPublic)
+	ecDER, _ := x509.MarshalPKIXPublicKey(&ecPrivate.PublicKey)
+	ecPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: ecDER}))
+
+	for _, element := range []DeviceKey{
+		{KeyType: "ed25519", PublicKey: base64.StdEncoding.EncodeToString(edPublic)},
+		{KeyType: KeyTypeEd25519, PublicKey: base64.StdEncoding.EncodeToString(edDER)},
+	} {
+		key, err := element.Normalize()
+		if err != nil || key.KeyType != KeyTypeEd25519 || key.PublicKey != base64.StdEncoding.EncodeToString(edDER) {
+			t.Fatalf("unexpected key %+v, error %v", key, err)
+		}
+	}
+	if key, err := (DeviceKey{KeyType: KeyTypeECDSA, PublicKey: ecPEM}).Normalize(); err != nil || key.PublicKey != base64.StdEncoding.EncodeToString(ecDER) {
+		t.Fatalf("unexpected key %+v, error %v", key, err)
+	}
+	for _, element := range []DeviceKey{
+		{KeyType: "RSA", PublicKey: ecPEM},
+		{KeyType: KeyTypeEd25519, PublicKey: ecPEM},
+		{KeyType: KeyTypeECDSA, PublicKey: base64.StdEncoding.EncodeToString(edPublic)},
+		{KeyType: KeyTypeECDSA, PublicKey: "not a key"},
+	} {
+		if _, err := element.Normalize(); err == nil {
+			t.Fatalf("expected %+v rejected", element)
+		}
+	}
+}
+
+func TestDeviceSignatureCheck(t *testing.T) {
+	edPublic, edPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	ecPrivate, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecDER, _ := x509.MarshalPKIXPublicKey(&ecPrivate.PublicKey)
+	fields := []string{"GPS", "SH1", "2020-01-01T10:00:00Z", FormatNumber(51.5, 64), FormatNumber(-0.12, 64), FormatNumber(0, 32)}
+
+	edKey := DeviceKey{KeyType: KeyTypeEd25519, PublicKey: base64.StdEncoding.EncodeToString(edPublic)}
+	signed := DeviceSignature{DeviceID: "D1", Sequence: 3, Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(edPrivate, SignedMessage("D1", 3, fields...)))}
+	if err := signed.Check(edKey, 2, fields...); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if err := signed.Check(edKey, 3, fields...); err == nil {
+		t.Fatalf("expected a replayed sequence rejected")
+	}
+	if err := signed.Check(edKey, 0, append(fields[:5:5], "10")...); err == nil {
+		t.Fatalf("expected a changed reading rejected")
+	}
+
+	digest := sha256.Sum256(SignedMessage("D2", 1, fields...))
+	ecSignature, _ := ecdsa.SignASN1(rand.Reader, ecPrivate, digest[:])
+	ecKey := DeviceKey{KeyType: KeyTypeECDSA, PublicKey: base64.StdEncoding.EncodeToString(ecDER)}
+	if err := (DeviceSignature{DeviceID: "D2", Sequence: 1, Signature: base64.StdEncoding.EncodeToString(ecSignature)}).Check(ecKey, 0, fields...); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if err := (DeviceSignature{DeviceID: "D2", Sequence: 1, Signature: "%%"}).Check(ecKey, 0, fields...); err == nil {
+		t.Fatalf("expected a malformed signature rejected")
+	}
+}
@@ -0,0 +1,104 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+//********************************************************************************************************
+// Geofences around Plants and Storage Locations
+//********************************************************************************************************
+
+//Shapes of a geofence
+const (
+	ShapeCircle  = "CIRCLE"
+	ShapePolygon = "POLYGON"
+)
+
+//Mean radius of the Earth used by Distance
+const earthRadiusMeters = 6371008.8
+
+//GeoPoint is a WGS84 position in degrees
+type GeoPoint struct {
+	Latitude  float64 `json:"Latitude"`
+	Longitude float64 `json:"Longitude"`
+}
+
+//GeoArea is a circle around a center or a polygon of at least three vertices.
+//Polygons are read in the plane of latitudes and longitudes and must not cross the antimeridian.
+type GeoArea struct {
+	Shape        string     `json:"Shape"`                  // CIRCLE or POLYGON
+	Center       *GeoPoint  `json:"Center,omitempty"`       // CIRCLE
+	RadiusMeters float64    `json:"RadiusMeters,omitempty"` // CIRCLE
+	Vertices     []GeoPoint `json:"Vertices,omitempty"`     // POLYGON, the last vertex is joined to the first
+}
+
+//Validate checks the point is on the globe
+func (point GeoPoint) Validate() error {
+	if point.Latitude < -90 || point.Latitude > 90 {
+		return fmt.Errorf("Latitude must be between -90 and 90")
+	}
+	if point.Longitude < -180 || point.Longitude > 180 {
+		return fmt.Errorf("Longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+//Normalize checks the area has what its shape needs and drops the fields of the other shape
+func (area GeoArea) Normalize() (GeoArea, error) {
+	area.Shape = strings.ToUpper(area.Shape)
+	switch area.Shape {
+	case ShapeCircle:
+		if area.Center == nil || area.RadiusMeters <= 0 {
+			return area, fmt.Errorf("A CIRCLE needs a Center and a positive RadiusMeters")
+		}
+		if err := area.Center.Validate(); err != nil {
+			return area, err
+		}
+		area.Vertices = nil
+	case ShapePolygon:
+		if len(area.Vertices) < 3 {
+			return area, fmt.Errorf("A POLYGON needs at least three Vertices")
+		}
+		for _, element := range area.Vertices {
+			if err := element.Validate(); err != nil {
+				return area, err
+			}
+		}
+		area.Center = nil
+		area.RadiusMeters = 0
+	default:
+		return area, fmt.Errorf("Shape must be CIRCLE or POLYGON")
+	}
+	return area, nil
+}
+
+//Distance returns the great-circle distance between two points in meters
+func Distance(from GeoPoint, to GeoPoint) float64 {
+	radians := math.Pi / 180
+	latitude := (to.Latitude - from.Latitude) * radians
+	longitude := (to.Longitude - from.Longitude) * radians
+	haversine := math.Pow(math.Sin(latitude/2), 2) + math.Cos(from.Latitude*radians)*math.Cos(to.Latitude*radians)*math.Pow(math.Sin(longitude/2), 2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(haversine)))
+}
+
+//Contains tells if the point lies within the area
+func (area GeoArea) Contains(point GeoPoint) bool {
+	switch area.Shape {
+	case ShapeCircle:
+		return area.Center != nil && Distance(*area.Center, point) <= area.RadiusMeters
+	case ShapePolygon:
+		//Count the edges crossed by a ray leaving the point eastwards
+		inside := false
+		for index, previous := 0, len(area.Vertices)-1; index < len(area.Vertices); previous, index = index, index+1 {
+			from, to := area.Vertices[previous], area.Vertices[index]
+			if (from.Latitude > point.Latitude) != (to.Latitude > point.Latitude) &&
+				point.Longitude < from.Longitude+(point.Latitude-from.Latitude)*(to.Longitude-from.Longitude)/(to.Latitude-from.Latitude) {
+				inside = !inside
+			}
+		}
+		return inside
+	}
+	return false
+}
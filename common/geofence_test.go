@@ -0,0 +1,44 @@
+package common
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGeoAreaNormalize(t *testing.T) {
+	area, err := GeoArea{Shape: "circle", Center: &GeoPoint{Latitude: 51.5, Longitude: -0.12}, RadiusMeters: 500, Vertices: []GeoPoint{{}}}.Normalize()
+	if err != nil || area.Shape != ShapeCircle || area.Vertices != nil {
+		t.Fatalf("unexpected area %+v, error %v", area, err)
+	}
+	for _, element := range []GeoArea{
+		{Shape: "SQUARE"},
+		{Shape: ShapeCircle, RadiusMeters: 500},
+		{Shape: ShapeCircle, Center: &GeoPoint{Latitude: 91}, RadiusMeters: 500},
+		{Shape: ShapeCircle, Center: &GeoPoint{}, RadiusMeters: 0},
+		{Shape: ShapePolygon, Vertices: []GeoPoint{{}, {Latitude: 1}}},
+		{Shape: ShapePolygon, Vertices: []GeoPoint{{}, {Latitude: 1}, {Longitude: 181}}},
+	} {
+		if _, err := element.Normalize(); err == nil {
+			t.Fatalf("expected %+v rejected", element)
+		}
+	}
+}
+
+func TestGeoAreaContains(t *testing.T) {
+	// One degree of latitude is about 111 km
+	if distance := Distance(GeoPoint{Latitude: 0, Longitude: 0}, GeoPoint{Latitude: 1, Longitude: 0}); math.Abs(distance-111195) > 100 {
+		t.Fatalf("unexpected distance %v", distance)
+	}
+	circle := GeoArea{Shape: ShapeCircle, Center: &GeoPoint{Latitude: 51.5, Longitude: -0.12}, RadiusMeters: 1000}
+	if !circle.Contains(GeoPoint{Latitude: 51.505, Longitude: -0.12}) || circle.Contains(GeoPoint{Latitude: 51.51, Longitude: -0.12}) {
+		t.Fatalf("unexpected circle containment")
+	}
+
+	// An L shaped polygon, its notch is outside
+	polygon := GeoArea{Shape: ShapePolygon, Vertices: []GeoPoint{{0, 0}, {0, 2}, {1, 2}, {1, 1}, {2, 1}, {2, 0}}}
+	for point, inside := range map[GeoPoint]bool{{0.5, 0.5}: true, {0.5, 1.5}: true, {1.5, 0.5}: true, {1.5, 1.5}: false, {-0.5, 0.5}: false, {0.5, 2.5}: false} {
+		if polygon.Contains(point) != inside {
+			t.Fatalf("%+v: expected inside %v", point, inside)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+//HistoryRecord is one version of a key, with its value decoded into the struct of the asset
+type HistoryRecord struct {
+	Key           string      `json:"Key"`
+	TxID          string      `json:"TxID"`
+	Timestamp     string      `json:"Timestamp"` // RFC3339
+	IsDelete      bool        `json:"IsDelete"`
+	Value         interface{} `json:"Value"`                   // nil for a delete, only the changed fields with ChangesOnly
+	ChangedFields []string    `json:"ChangedFields,omitempty"` // set with ChangesOnly
+	time          time.Time
+}
+
+//Time of the transaction that wrote the version
+func (record HistoryRecord) Time() time.Time {
+	return record.time
+}
+
+//HistoryOptions filters a history. A zero From or To leaves that end of the range open.
+type HistoryOptions struct {
+	From        time.Time `json:"From"`
+	To          time.Time `json:"To"`
+	ChangesOnly bool      `json:"ChangesOnly"` // Keep only the fields that changed since the previous version
+}
+
+//HistoryDecoder decodes a stored value into the Go struct of its asset
+type HistoryDecoder func(key string, value []byte) interface{}
+
+//ParseHistoryOptions reads the optional JSON options of a history request
+func ParseHistoryOptions(data string) (HistoryOptions, error) {
+	options := HistoryOptions{}
+	if data == "" {
+		return options, nil
+	}
+	if err := json.Unmarshal([]byte(data), &options); err != nil {
+		return options, fmt.Errorf("options must hold RFC3339 From and To times and a ChangesOnly flag")
+	}
+	if !options.From.IsZero() && !options.To.IsZero() && options.To.Before(options.From) {
+		return options, fmt.Errorf("To must not be before From")
+	}
+	return options, nil
+}
+
+//DecodeValue decodes a stored value into the record built by newRecord.
+//Values that do not fit the record are returned as generic JSON, or as a string when they are not JSON.
+func DecodeValue(value []byte, newRecord func() interface{}) interface{} {
+	if newRecord != nil {
+		record := newRecord()
+		if err := json.Unmarshal(value, record); err == nil {
+			return record
+		}
+	}
+	var generic interface{}
+	if err := json.Unmarshal(value, &generic); err == nil {
+		return generic
+	}
+	return string(value)
+}
+
+//AssetTypeDecoder picks the struct of a value from its Asset_Type
+func AssetTypeDecoder(records map[string]func() interface{}) HistoryDecoder {
+	return func(key string, value []byte) interface{} {
+		asset := struct {
+			Asset_Type string `json:"Asset_Type"`
+		}{}
+		json.Unmarshal(value, &asset)
+		return DecodeValue(value, records[asset.Asset_Type])
+	}
+}
+
+//GetHistory returns the versions of the keys in time order, filtered by the options.
+//Several keys are merged, for an asset that moved from one key to another.
+func GetHistory(stub shim.ChaincodeStubInterface, options HistoryOptions, decode HistoryDecoder, keys ...string) ([]HistoryRecord, error) {
+	records := []HistoryRecord{}
+	for _, key := range keys {
+		resultsIterator, err := stub.GetHistoryForKey(key)
+		if err != nil {
+			return nil, err
+		}
+		for resultsIterator.HasNext() {
+			modification, err := resultsIterator.Next()
+			if err != nil {
+				resultsIterator.Close()
+				return nil, err
+			}
+			record := HistoryRecord{Key: key, TxID: modification.TxId, IsDelete: modification.IsDelete}
+			record.time = time.Unix(modification.Timestamp.GetSeconds(), int64(modification.Timestamp.GetNanos())).UTC()
+			record.Timestamp = record.time.Format(time.RFC3339Nano)
+			if !modification.IsDelete {
+				record.Value = decode(key, modification.Value)
+			}
+			records = append(records, record)
+		}
+		resultsIterator.Close()
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].time.Before(records[j].time) })
+
+	// Changes are computed over every version, before the range drops the older ones
+	if options.ChangesOnly {
+		changedFields(records)
+	}
+	filtered := []HistoryRecord{}
+	for _, record := range records {
+		if (options.From.IsZero() || !record.time.Before(options.From)) && (options.To.IsZero() || !record.time.After(options.To)) {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered, nil
+}
+
+//changedFields replaces each value by the fields that differ from the previous version.
+//Fields removed since the previous version are reported as null.
+func changedFields(records []HistoryRecord) {
+	previous := map[string]json.RawMessage{}
+	for i := range records {
+		if records[i].IsDelete {
+			previous = map[string]json.RawMessage{}
+			continue
+		}
+		current := map[string]json.RawMessage{}
+		jsonBytes, _ := json.Marshal(records[i].Value)
+		if err := json.Unmarshal(jsonBytes, &current); err != nil {
+			// Values that are not JSON objects have no fields, they are kept whole
+			previous = map[string]json.RawMessage{}
+			continue
+		}
+
+		changes := map[string]json.RawMessage{}
+		for field, value := range current {
+			if before, found := previous[field]; !found || !bytes.Equal(before, value) {
+				changes[field] = value
+			}
+		}
+		for field := range previous {
+			if _, found := current[field]; !found {
+				changes[field] = json.RawMessage("null")
+			}
+		}
+		records[i].ChangedFields = []string{}
+		for field := range changes {
+			records[i].ChangedFields = append(records[i].ChangedFields, field)
+		}
+		sort.Strings(records[i].ChangedFields)
+		records[i].Value = changes
+		previous = current
+	}
+}
+
+//VersionAsOf returns the version of the keys that was current at a time, nil if the asset did not exist then
+func VersionAsOf(stub shim.ChaincodeStubInterface, at time.Time, decode HistoryDecoder, keys ...string) (*HistoryRecord, error) {
+	records, err := GetHistory(stub, HistoryOptions{To: at}, decode, keys...)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	current := records[len(records)-1]
+	if current.IsDelete {
+		return nil, nil
+	}
+	return &current, nil
+}
+
+//ParseTime reads an RFC3339 time argument
+func ParseTime(value string) (time.Time, error) {
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return at, fmt.Errorf("time must be in RFC3339 format, for example 2020-01-31T12:00:00Z")
+	}
+	return at, nil
+}
+
+//GetHistoryExecution returns the versions of the keys as a JSON array
+func GetHistoryExecution(stub shim.ChaincodeStubInterface, options HistoryOptions, decode HistoryDecoder, keys ...string) ([]byte, error) {
+	records, err := GetHistory(stub, options, decode, keys...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(records)
+}
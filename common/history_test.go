@@ -0,0 +1,140 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+)
+
+//historyIterator serves a fixed list of key modifications
+type historyIterator struct {
+	modifications []*queryresult.KeyModification
+}
+
+func (iterator *historyIterator) HasNext() bool { return len(iterator.modifications) > 0 }
+func (iterator *historyIterator) Close() error  { return nil }
+func (iterator *historyIterator) Next() (*queryresult.KeyModification, error) {
+	modification := iterator.modifications[0]
+	iterator.modifications = iterator.modifications[1:]
+	return modification, nil
+}
+
+//historyStub answers GetHistoryForKey from a fixed history per key
+type historyStub struct {
+	*shim.MockStub
+	history map[string][]*queryresult.KeyModification
+}
+
+func (stub *historyStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	return &historyIterator{stub.history[key]}, nil
+}
+
+func modification(txID string, minute int, value string) *queryresult.KeyModification {
+	return &queryresult.KeyModification{
+		TxId:      txID,
+		Value:     []byte(value),
+		Timestamp: &timestamp.Timestamp{Seconds: time.Date(2020, 1, 1, 10, minute, 0, 0, time.UTC).Unix()},
+		IsDelete:  value == "",
+	}
+}
+
+type historyAsset struct {
+	Asset_Type string `json:"Asset_Type,omitempty"`
+	ID         string `json:"ID"`
+	Status     string `json:"Status"`
+}
+
+func newHistoryStub() *historyStub {
+	return &historyStub{MockStub: shim.NewMockStub("history", nil), history: map[string][]*queryresult.KeyModification{
+		"asset01": {
+			modification("tx1", 0, `{"Asset_Type":"ASSET","ID":"01","Status":"OPEN","Extra":1}`),
+			modification("tx3", 20, ""),
+			modification("tx2", 10, `{"Asset_Type":"ASSET","ID":"01","Status":"CLOSED"}`),
+		},
+		"legacy-01": {modification("tx0", 5, `{"Asset_Type":"ASSET","ID":"01","Status":"OPEN"}`)},
+	}}
+}
+
+var historyDecoder = AssetTypeDecoder(map[string]func() interface{}{"ASSET": func() interface{} { return &historyAsset{} }})
+
+func TestGetHistoryDecodesVersions(t *testing.T) {
+	result, err := GetHistoryExecution(newHistoryStub(), HistoryOptions{}, historyDecoder, "asset01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"Key":"asset01","TxID":"tx1","Timestamp":"2020-01-01T10:00:00Z","IsDelete":false,"Value":{"Asset_Type":"ASSET","ID":"01","Status":"OPEN"}},` +
+		`{"Key":"asset01","TxID":"tx2","Timestamp":"2020-01-01T10:10:00Z","IsDelete":false,"Value":{"Asset_Type":"ASSET","ID":"01","Status":"CLOSED"}},` +
+		`{"Key":"asset01","TxID":"tx3","Timestamp":"2020-01-01T10:20:00Z","IsDelete":true,"Value":null}]`
+	if string(result) != expected {
+		t.Fatalf("expected %s got %s", expected, result)
+	}
+}
+
+func TestGetHistoryFiltersAndMergesKeys(t *testing.T) {
+	options, err := ParseHistoryOptions(`{"From":"2020-01-01T10:05:00Z","To":"2020-01-01T10:10:00Z","ChangesOnly":true}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := GetHistory(newHistoryStub(), options, historyDecoder, "asset01", "legacy-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].TxID != "tx0" || records[1].TxID != "tx2" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	// tx0 rewrote tx1 unchanged, Extra is not a field of the decoded struct
+	if changes, _ := json.Marshal(records[0].Value); string(changes) != `{}` {
+		t.Fatalf("unexpected changes %s", changes)
+	}
+	changes, _ := json.Marshal(records[1].Value)
+	if string(changes) != `{"Status":"CLOSED"}` || len(records[1].ChangedFields) != 1 {
+		t.Fatalf("unexpected changes %s %v", changes, records[1].ChangedFields)
+	}
+}
+
+func TestDecodeValue(t *testing.T) {
+	if value := DecodeValue([]byte(`"raw"`), nil); value != "raw" {
+		t.Fatalf("expected generic JSON, got %v", value)
+	}
+	if value := DecodeValue([]byte(`GROWER01`), nil); value != "GROWER01" {
+		t.Fatalf("expected a string, got %v", value)
+	}
+	if value, ok := historyDecoder("asset01", []byte(`{"Asset_Type":"ASSET","ID":"01"}`)).(*historyAsset); !ok || value.ID != "01" {
+		t.Fatalf("expected a decoded asset, got %v", value)
+	}
+}
+
+func TestParseHistoryOptions(t *testing.T) {
+	for _, data := range []string{`{"From":"yesterday"}`, `{"From":"2020-01-02T00:00:00Z","To":"2020-01-01T00:00:00Z"}`} {
+		if _, err := ParseHistoryOptions(data); err == nil {
+			t.Fatalf("expected %s to be rejected", data)
+		}
+	}
+	if options, err := ParseHistoryOptions(""); err != nil || !options.From.IsZero() || options.ChangesOnly {
+		t.Fatalf("unexpected default options %+v %v", options, err)
+	}
+}
+
+func TestVersionAsOf(t *testing.T) {
+	stub := newHistoryStub()
+	for minute, txID := range map[int]string{0: "tx1", 9: "tx1", 10: "tx2", 15: "tx2", 20: "", 30: ""} {
+		at := time.Date(2020, 1, 1, 10, minute, 0, 0, time.UTC)
+		version, err := VersionAsOf(stub, at, historyDecoder, "asset01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (txID == "") != (version == nil) || (version != nil && version.TxID != txID) {
+			t.Fatalf("at %s expected version %q, got %+v", at, txID, version)
+		}
+	}
+	if version, _ := VersionAsOf(stub, time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), historyDecoder, "asset01"); version != nil {
+		t.Fatalf("the asset did not exist yet, got %+v", version)
+	}
+	if _, err := ParseTime("2020-01-01 10:00"); err == nil {
+		t.Fatal("expected an error for a time that is not RFC3339")
+	}
+}
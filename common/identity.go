@@ -0,0 +1,55 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/core/chaincode/shim/ext/cid"
+)
+
+//Certificate attribute that names the enrolled user inside its MSP.
+//Fabric CA adds it to every enrollment certificate.
+const IdentityAttribute = "hf.EnrollmentID"
+
+//Certificate attribute that holds the identity type (client, peer, admin ...)
+const TypeAttribute = "hf.Type"
+
+//Identity of the client that created the transaction
+type Identity struct {
+	MSPID        string `json:"MSPID"`
+	EnrollmentID string `json:"EnrollmentID"`
+	Type         string `json:"Type,omitempty"`
+}
+
+//IsAdmin reports whether the identity was registered as an admin by its CA
+func (identity Identity) IsAdmin() bool {
+	return identity.Type == "admin"
+}
+
+//GetIdentity reads the MSP ID and the enrollment attribute of the transaction creator
+//through the client identity library.
+func GetIdentity(stub shim.ChaincodeStubInterface) (Identity, error) {
+	identity := Identity{}
+
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return identity, err
+	}
+	enrollmentID, found, err := cid.GetAttributeValue(stub, IdentityAttribute)
+	if err != nil {
+		return identity, err
+	}
+	if !found || enrollmentID == "" {
+		return identity, fmt.Errorf("certificate attribute %s not found", IdentityAttribute)
+	}
+
+	identityType, _, err := cid.GetAttributeValue(stub, TypeAttribute)
+	if err != nil {
+		return identity, err
+	}
+
+	identity.MSPID = mspID
+	identity.EnrollmentID = enrollmentID
+	identity.Type = identityType
+	return identity, nil
+}
@@ -0,0 +1,94 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/msp"
+)
+
+//creatorStub answers GetCreator with a fixed serialized identity
+type creatorStub struct {
+	*shim.MockStub
+	creator []byte
+}
+
+func (stub *creatorStub) GetCreator() ([]byte, error) {
+	return stub.creator, nil
+}
+
+//newCreatorStub builds a self-signed certificate carrying the Fabric CA attributes extension
+func newCreatorStub(t *testing.T, mspID string, attrs string) *creatorStub {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if attrs != "" {
+		template.ExtraExtensions = []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}, Value: []byte(attrs)}}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creator, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &creatorStub{MockStub: shim.NewMockStub("identity", nil), creator: creator}
+}
+
+func TestGetIdentity(t *testing.T) {
+	stub := newCreatorStub(t, "Org1MSP", `{"attrs":{"hf.EnrollmentID":"user1"}}`)
+	identity, err := GetIdentity(stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.MSPID != "Org1MSP" || identity.EnrollmentID != "user1" || identity.IsAdmin() {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+}
+
+func TestGetIdentityType(t *testing.T) {
+	stub := newCreatorStub(t, "Org1MSP", `{"attrs":{"hf.EnrollmentID":"admin1","hf.Type":"admin"}}`)
+	identity, err := GetIdentity(stub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Type != "admin" || !identity.IsAdmin() {
+		t.Fatalf("expected an admin identity %+v", identity)
+	}
+}
+
+func TestGetIdentityWithoutAttribute(t *testing.T) {
+	if _, err := GetIdentity(newCreatorStub(t, "Org1MSP", "")); err == nil {
+		t.Fatal("expected an error for a certificate without attributes")
+	}
+	if _, err := GetIdentity(newCreatorStub(t, "Org1MSP", `{"attrs":{"role":"admin"}}`)); err == nil {
+		t.Fatal("expected an error for a certificate without the enrollment attribute")
+	}
+}
+
+func TestGetIdentityWithoutCreator(t *testing.T) {
+	if _, err := GetIdentity(shim.NewMockStub("identity", nil)); err == nil {
+		t.Fatal("expected an error when the stub has no creator")
+	}
+}
@@ -0,0 +1,80 @@
+package common
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+)
+
+//********************************************************************************************************
+// CouchDB Index Definitions
+//********************************************************************************************************
+
+//Folder of a chaincode package holding its CouchDB index definitions
+const IndexFolder = "META-INF/statedb/couchdb/indexes"
+
+//CouchIndex is one index definition deployed with a chaincode
+type CouchIndex struct {
+	Index struct {
+		Fields []string `json:"fields"`
+	} `json:"index"`
+	DDoc string `json:"ddoc"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+//ReadIndexes loads every index definition of a folder
+func ReadIndexes(folder string) ([]CouchIndex, error) {
+	files, err := filepath.Glob(filepath.Join(folder, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	indexes := []CouchIndex{}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		index := CouchIndex{}
+		if err := json.Unmarshal(content, &index); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+//CoveringIndex returns the index CouchDB can use for a query string, preferring the index with the most fields.
+//An index is usable when the selector holds all its fields and it holds every sort field.
+func CoveringIndex(queryString string, indexes []CouchIndex) (CouchIndex, bool) {
+	couchQuery := struct {
+		Selector map[string]interface{} `json:"selector"`
+		Sort     []map[string]string    `json:"sort"`
+	}{}
+	if err := json.Unmarshal([]byte(queryString), &couchQuery); err != nil {
+		return CouchIndex{}, false
+	}
+
+	best, found := CouchIndex{}, false
+	for _, index := range indexes {
+		fields := map[string]bool{}
+		usable := true
+		for _, field := range index.Index.Fields {
+			fields[field] = true
+			if _, selected := couchQuery.Selector[field]; !selected {
+				usable = false
+			}
+		}
+		for _, sort := range couchQuery.Sort {
+			for field := range sort {
+				if !fields[field] {
+					usable = false
+				}
+			}
+		}
+		if usable && (!found || len(index.Index.Fields) > len(best.Index.Fields)) {
+			best, found = index, true
+		}
+	}
+	return best, found
+}
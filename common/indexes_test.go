@@ -0,0 +1,49 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadIndexes(t *testing.T) {
+	folder, err := ioutil.TempDir("", "indexes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+	ioutil.WriteFile(filepath.Join(folder, "indexOwner.json"), []byte(`{"index":{"fields":["Asset_Type","Owner"]},"ddoc":"indexOwnerDoc","name":"indexOwner","type":"json"}`), 0644)
+	ioutil.WriteFile(filepath.Join(folder, "README"), []byte("not an index"), 0644)
+
+	indexes, err := ReadIndexes(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indexes) != 1 || indexes[0].Name != "indexOwner" || len(indexes[0].Index.Fields) != 2 {
+		t.Fatalf("unexpected indexes %+v", indexes)
+	}
+}
+
+func TestCoveringIndex(t *testing.T) {
+	index := func(name string, fields ...string) CouchIndex {
+		couchIndex := CouchIndex{Name: name}
+		couchIndex.Index.Fields = fields
+		return couchIndex
+	}
+	indexes := []CouchIndex{index("type", "Asset_Type"), index("owner", "Asset_Type", "Owner"), index("status", "Asset_Type", "Owner", "Status")}
+
+	for queryString, expected := range map[string]string{
+		`{"selector":{"Asset_Type":"BATCH"}}`:                                                              "type",
+		`{"selector":{"Asset_Type":"BATCH","Owner":"p1","Quantity":1}}`:                                    "owner",
+		`{"selector":{"Asset_Type":"BATCH","Owner":"p1","Status":"OPEN"}}`:                                 "status",
+		`{"selector":{"Asset_Type":"BATCH","Owner":"p1","Status":{"$gt":null}},"sort":[{"Status":"asc"}]}`: "status",
+		`{"selector":{"Owner":"p1"}}`:                                                                      "",
+		`{"selector":{"Asset_Type":"BATCH","Quantity":{"$gt":null}},"sort":[{"Quantity":"asc"}]}`:          "",
+	} {
+		covering, found := CoveringIndex(queryString, indexes)
+		if covering.Name != expected || found != (expected != "") {
+			t.Fatalf("%s: expected index %q, got %q", queryString, expected, covering.Name)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package common
+
+import "strings"
+
+//********************************************************************************************************
+// Key Building Conventions
+//********************************************************************************************************
+
+//Separator placed between the parts of a ledger key
+const KeySeparator = "-"
+
+//Key builds the ledger key for an asset by joining its parts with the separator.
+//Keys are always stored in lower case so lookups are case insensitive.
+//U+0000 and U+10FFFF are dropped: they delimit composite keys, which an ID must never pose as.
+//Examples:
+//  Key("PRODUCT01")                            => "product01"
+//  Key("GROWER01", "MAT01")                    => "grower01-mat01"
+//  Key("PURCHASEORDER", "IMPORTER01", "4500")  => "purchaseorder-importer01-4500"
+func Key(parts ...string) string {
+	return strings.Map(func(r rune) rune {
+		if r == 0 || r == 0x10FFFF {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.Join(parts, KeySeparator)))
+}
@@ -0,0 +1,12 @@
+package common
+
+import "testing"
+
+func TestKey(t *testing.T) {
+	if key := Key("PURCHASEORDER", "Importer01", "PO1"); key != "purchaseorder-importer01-po1" {
+		t.Fatalf("unexpected key %s", key)
+	}
+	if key := Key("\x00ACCESSPOLICY\x00deleteAsset\x00\U0010FFFF"); key != "accesspolicydeleteasset" {
+		t.Fatalf("composite key delimiters must be dropped, got %q", key)
+	}
+}
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+//********************************************************************************************************
+// Merkle Trees of off-chain records
+//********************************************************************************************************
+
+//Prefixes keep a leaf from being passed off as an inner node
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+//ProofStep is the sibling hash met on the way from a leaf to the root, Left when the sibling is on the left
+type ProofStep struct {
+	Hash string `json:"Hash"` // Hex encoded SHA-256
+	Left bool   `json:"Left"`
+}
+
+func merkleLeaf(data []byte) []byte {
+	hash := sha256.Sum256(append([]byte{merkleLeafPrefix}, data...))
+	return hash[:]
+}
+
+func merkleNode(left []byte, right []byte) []byte {
+	hash := sha256.Sum256(append(append([]byte{merkleNodePrefix}, left...), right...))
+	return hash[:]
+}
+
+//merkleLevels hashes the leaves and every level above them, the last level holds the root.
+//A node without sibling moves up a level unchanged.
+func merkleLevels(leaves [][]byte) [][][]byte {
+	level := [][]byte{}
+	for _, element := range leaves {
+		level = append(level, merkleLeaf(element))
+	}
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		next := [][]byte{}
+		for index := 0; index < len(level); index += 2 {
+			if index+1 < len(level) {
+				next = append(next, merkleNode(level[index], level[index+1]))
+			} else {
+				next = append(next, level[index])
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+//MerkleRoot returns the hex encoded SHA-256 Merkle root of the leaves, empty without leaves
+func MerkleRoot(leaves [][]byte) string {
+	if len(leaves) == 0 {
+		return ""
+	}
+	levels := merkleLevels(leaves)
+	return hex.EncodeToString(levels[len(levels)-1][0])
+}
+
+//MerkleProof returns the sibling hashes proving the leaf at index against the Merkle root of the leaves
+func MerkleProof(leaves [][]byte, index int) ([]ProofStep, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf %d is out of range", index)
+	}
+	proof := []ProofStep{}
+	for _, level := range merkleLevels(leaves) {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, ProofStep{Hash: hex.EncodeToString(level[sibling]), Left: sibling < index})
+		}
+		index /= 2
+	}
+	return proof, nil
+}
+
+//VerifyMerkleProof tells if a leaf and its proof hash up to the hex encoded root
+func VerifyMerkleProof(leaf []byte, proof []ProofStep, root string) bool {
+	expected, err := hex.DecodeString(root)
+	if err != nil || len(expected) != sha256.Size {
+		return false
+	}
+	hash := merkleLeaf(leaf)
+	for _, step := range proof {
+		sibling, err := hex.DecodeString(step.Hash)
+		if err != nil || len(sibling) != sha256.Size {
+			return false
+		}
+		if step.Left {
+			hash = merkleNode(sibling, hash)
+		} else {
+			hash = merkleNode(hash, sibling)
+		}
+	}
+	return bytes.Equal(hash, expected)
+}
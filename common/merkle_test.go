@@ -0,0 +1,40 @@
+package common
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMerkleProofs(t *testing.T) {
+	if MerkleRoot(nil) != "" {
+		t.Fatalf("expected no root without leaves")
+	}
+	for size := 1; size <= 9; size++ {
+		leaves := [][]byte{}
+		for index := 0; index < size; index++ {
+			leaves = append(leaves, []byte(fmt.Sprintf(`{"Reading":%d}`, index)))
+		}
+		root := MerkleRoot(leaves)
+		for index := range leaves {
+			proof, err := MerkleProof(leaves, index)
+			if err != nil || !VerifyMerkleProof(leaves[index], proof, root) {
+				t.Fatalf("leaf %d of %d does not verify: %v", index, size, err)
+			}
+			if VerifyMerkleProof([]byte(`{"Reading":-1}`), proof, root) {
+				t.Fatalf("a tampered leaf must not verify")
+			}
+			if size > 1 && VerifyMerkleProof(leaves[index], proof[1:], root) {
+				t.Fatalf("a truncated proof must not verify")
+			}
+		}
+	}
+
+	// A single leaf is its own proof, and is not the root of two leaves
+	pair := [][]byte{[]byte("a"), []byte("b")}
+	if VerifyMerkleProof([]byte("a"), nil, MerkleRoot(pair)) || VerifyMerkleProof([]byte("a"), nil, "not hex") {
+		t.Fatalf("unexpected verification")
+	}
+	if _, err := MerkleProof(pair, 2); err == nil {
+		t.Fatalf("expected an out of range leaf rejected")
+	}
+}
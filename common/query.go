@@ -0,0 +1,127 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+//Number of records returned by a paginated query when the caller sets no page size
+const DefaultPageSize int32 = 100
+
+//QueryPage is one page of a paginated rich query, Bookmark fetches the next page
+type QueryPage struct {
+	Records             json.RawMessage `json:"Records"`
+	FetchedRecordsCount int32           `json:"FetchedRecordsCount"`
+	Bookmark            string          `json:"Bookmark"`
+}
+
+//QueryExecution runs a rich query against the state database and returns the matches as a JSON array
+func QueryExecution(stub shim.ChaincodeStubInterface, queryString string) ([]byte, error) {
+	resultsIterator, err := stub.GetQueryResult(queryString)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+	return writeRecords(resultsIterator)
+}
+
+//QueryExecutionWithPagination runs a rich query and returns one page of matches with the bookmark of the next page
+func QueryExecutionWithPagination(stub shim.ChaincodeStubInterface, queryString string, pageSize int32, bookmark string) ([]byte, error) {
+	resultsIterator, metadata, err := stub.GetQueryResultWithPagination(queryString, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	if resultsIterator == nil {
+		return nil, fmt.Errorf("paginated queries are not supported by this state database")
+	}
+	defer resultsIterator.Close()
+
+	records, err := writeRecords(resultsIterator)
+	if err != nil {
+		return nil, err
+	}
+	page := QueryPage{Records: records}
+	if metadata != nil {
+		page.FetchedRecordsCount = metadata.FetchedRecordsCount
+		page.Bookmark = metadata.Bookmark
+	}
+	return json.Marshal(page)
+}
+
+//PrefixExecution lists the records whose key starts with the key built from parts, as a JSON array.
+//It relies on a key range scan only, so it works on LevelDB as well as CouchDB.
+func PrefixExecution(stub shim.ChaincodeStubInterface, parts ...string) ([]byte, error) {
+	prefix := Key(parts...) + KeySeparator
+	resultsIterator, err := stub.GetStateByRange(prefix, prefix+string(utf8.MaxRune))
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+	return writeRecords(resultsIterator)
+}
+
+//PartialKeyExecution lists the records whose composite key starts with the object type and lower case parts, as a JSON array.
+//Like PrefixExecution it works on LevelDB as well as CouchDB.
+func PartialKeyExecution(stub shim.ChaincodeStubInterface, objectType string, parts ...string) ([]byte, error) {
+	attributes := []string{}
+	for _, part := range parts {
+		attributes = append(attributes, strings.ToLower(part))
+	}
+	resultsIterator, err := stub.GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+	return writeRecords(resultsIterator)
+}
+
+//PageArguments reads the optional page size and bookmark that follow the query string in args
+func PageArguments(args []string) (int32, string, error) {
+	pageSize := DefaultPageSize
+	bookmark := ""
+	if len(args) > 1 && args[1] != "" {
+		size, err := strconv.ParseInt(args[1], 10, 32)
+		if err != nil || size <= 0 {
+			return 0, "", fmt.Errorf("page size must be a positive number")
+		}
+		pageSize = int32(size)
+	}
+	if len(args) > 2 {
+		bookmark = args[2]
+	}
+	return pageSize, bookmark, nil
+}
+
+//writeRecords renders the key and value of every result as a JSON array
+func writeRecords(resultsIterator shim.StateQueryIteratorInterface) ([]byte, error) {
+	//JSON Array Buffer
+	var buffer bytes.Buffer
+	buffer.WriteString("[")
+
+	alreadyFetched := false
+	for resultsIterator.HasNext() {
+		output, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if alreadyFetched == true {
+			buffer.WriteString(",")
+		}
+		//Composite keys hold control characters, let the encoder escape them
+		key, _ := json.Marshal(output.Key)
+		buffer.WriteString("{\"Key\":")
+		buffer.WriteString(string(key))
+		buffer.WriteString(", \"Record\":")
+		buffer.WriteString(string(output.Value))
+		buffer.WriteString("}")
+		alreadyFetched = true
+	}
+	buffer.WriteString("]")
+	return buffer.Bytes(), nil
+}
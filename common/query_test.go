@@ -0,0 +1,102 @@
+package common
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+//sliceIterator serves a fixed list of results
+type sliceIterator struct {
+	results []*queryresult.KV
+}
+
+func (iterator *sliceIterator) HasNext() bool { return len(iterator.results) > 0 }
+func (iterator *sliceIterator) Close() error  { return nil }
+func (iterator *sliceIterator) Next() (*queryresult.KV, error) {
+	result := iterator.results[0]
+	iterator.results = iterator.results[1:]
+	return result, nil
+}
+
+//pagedStub pages over its records using the bookmark as the index of the next record
+type pagedStub struct {
+	*shim.MockStub
+	records []*queryresult.KV
+	query   string
+}
+
+func (stub *pagedStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	stub.query = query
+	start := 0
+	json.Unmarshal([]byte(bookmark), &start)
+	end := start + int(pageSize)
+	if end > len(stub.records) {
+		end = len(stub.records)
+	}
+	next, _ := json.Marshal(end)
+	return &sliceIterator{stub.records[start:end]}, &pb.QueryResponseMetadata{FetchedRecordsCount: int32(end - start), Bookmark: string(next)}, nil
+}
+
+func TestQueryExecutionWithPagination(t *testing.T) {
+	stub := &pagedStub{MockStub: shim.NewMockStub("query", nil)}
+	for _, key := range []string{"a", "b", "c"} {
+		stub.records = append(stub.records, &queryresult.KV{Key: key, Value: []byte(`{"ID":"` + key + `"}`)})
+	}
+
+	result, err := QueryExecutionWithPagination(stub, `{"selector":{}}`, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := QueryPage{}
+	if err := json.Unmarshal(result, &page); err != nil {
+		t.Fatal(err)
+	}
+	records := []map[string]interface{}{}
+	json.Unmarshal(page.Records, &records)
+	if page.FetchedRecordsCount != 2 || page.Bookmark != "2" || len(records) != 2 || records[1]["Key"] != "b" {
+		t.Fatalf("unexpected first page %s", result)
+	}
+
+	result, _ = QueryExecutionWithPagination(stub, `{"selector":{}}`, 2, page.Bookmark)
+	json.Unmarshal(result, &page)
+	if page.FetchedRecordsCount != 1 || string(page.Records) != `[{"Key":"c","Record":{"ID":"c"}}]` {
+		t.Fatalf("unexpected last page %s", result)
+	}
+}
+
+func TestPageArguments(t *testing.T) {
+	if pageSize, bookmark, err := PageArguments([]string{"query"}); err != nil || pageSize != DefaultPageSize || bookmark != "" {
+		t.Fatalf("unexpected defaults %d %q %v", pageSize, bookmark, err)
+	}
+	if pageSize, bookmark, err := PageArguments([]string{"query", "25", "next"}); err != nil || pageSize != 25 || bookmark != "next" {
+		t.Fatalf("unexpected arguments %d %q %v", pageSize, bookmark, err)
+	}
+	if _, _, err := PageArguments([]string{"query", "-1"}); err == nil {
+		t.Fatal("expected an error for a negative page size")
+	}
+}
+
+func TestPrefixExecution(t *testing.T) {
+	stub := shim.NewMockStub("query", nil)
+	stub.MockTransactionStart("tx1")
+	for _, key := range []string{Key("BATCH", "GROWER01", "MAT01", "B1"), Key("BATCH", "GROWER01", "MAT02", "B2"), Key("BATCH", "GROWER02", "MAT01", "B3"), Key("BATCHES", "X")} {
+		stub.PutState(key, []byte(`{"ID":"`+key+`"}`))
+	}
+	stub.MockTransactionEnd("tx1")
+
+	for parts, count := range map[string]int{"BATCH": 3, "BATCH/GROWER01": 2, "BATCH/GROWER01/MAT01": 1, "BATCH/GROWER03": 0} {
+		result, err := PrefixExecution(stub, strings.Split(parts, "/")...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records := []map[string]interface{}{}
+		if err := json.Unmarshal(result, &records); err != nil || len(records) != count {
+			t.Fatalf("%s: expected %d records, got %s", parts, count, result)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+//Deloitte Consulting LLP.
+//**************************** MUST BE USED FOR INTERNAL PURPOSE ONLY ************************************
+//****FileName: Shared Chaincode Library
+//****Description: Domain helpers shared by the BlockchainIOT and Testing1 chaincodes
+//****Author: Rom Solanki
+//****Author Email: rosolanki@deloitte.com
+//********************************************************************************************************
+
+package common
+
+import (
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/peer"
+)
+
+//Logger shared by the helpers of this package
+var logger = shim.NewLogger("chaincode")
+
+//********************************************************************************************************
+//REST API Calls Response Format
+//********************************************************************************************************
+func Success(rc int32, msg string, payload []byte) peer.Response {
+	return peer.Response{
+		Status:  rc,
+		Message: msg,
+		Payload: payload,
+	}
+}
+
+func Error(rc int32, msg string) peer.Response {
+	logger.Errorf("Error %d = %s", rc, msg)
+	return peer.Response{
+		Status:  rc,
+		Message: msg,
+	}
+}
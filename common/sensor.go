@@ -0,0 +1,77 @@
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+//********************************************************************************************************
+// Cold Chain Sensor Readings
+//********************************************************************************************************
+
+//SensorReading is one measurement of the sensor travelling with a shipment
+type SensorReading struct {
+	TemperatureCelsius float64 `json:"TemperatureCelsius"`
+	Humidity           float64 `json:"Humidity"` // Relative humidity in percent
+	Shock              float64 `json:"Shock"`    // Peak acceleration in g
+	Timestamp          string  `json:"Timestamp"`
+}
+
+//TemperatureLimits is the range a product must be kept in, an unset limit is not checked
+type TemperatureLimits struct {
+	MinTemperature *float64 `json:"MinTemperature,omitempty"`
+	MaxTemperature *float64 `json:"MaxTemperature,omitempty"`
+}
+
+//Excursion is a reading outside the temperature limits of the product
+type Excursion struct {
+	Timestamp          string            `json:"Timestamp"`
+	TemperatureCelsius float64           `json:"TemperatureCelsius"`
+	Limits             TemperatureLimits `json:"Limits"`
+}
+
+//Validate checks the minimum is not above the maximum
+func (limits TemperatureLimits) Validate() error {
+	if limits.MinTemperature != nil && limits.MaxTemperature != nil && *limits.MinTemperature > *limits.MaxTemperature {
+		return fmt.Errorf("MinTemperature must not be above MaxTemperature")
+	}
+	return nil
+}
+
+//Check returns the excursion of a reading, nil when the reading is within the limits
+func (limits TemperatureLimits) Check(reading SensorReading) *Excursion {
+	if (limits.MinTemperature != nil && reading.TemperatureCelsius < *limits.MinTemperature) ||
+		(limits.MaxTemperature != nil && reading.TemperatureCelsius > *limits.MaxTemperature) {
+		return &Excursion{Timestamp: reading.Timestamp, TemperatureCelsius: reading.TemperatureCelsius, Limits: limits}
+	}
+	return nil
+}
+
+//SignedFields are the fields of the reading a device signs, as it sent them, see SignedMessage
+func (reading SensorReading) SignedFields(shipmentID string) []string {
+	return []string{"SENSOR", shipmentID, reading.Timestamp, FormatNumber(reading.TemperatureCelsius, 64), FormatNumber(reading.Humidity, 64), FormatNumber(reading.Shock, 64)}
+}
+
+//Normalize validates a reading and stores its time in UTC, a reading without time is taken at the transaction time
+func (reading SensorReading) Normalize(stub shim.ChaincodeStubInterface) (SensorReading, error) {
+	if reading.Humidity < 0 || reading.Humidity > 100 {
+		return reading, fmt.Errorf("Humidity must be between 0 and 100")
+	}
+	if reading.Shock < 0 {
+		return reading, fmt.Errorf("Shock must not be negative")
+	}
+	var at time.Time
+	var err error
+	if reading.Timestamp == "" {
+		at, err = TxTime(stub)
+	} else {
+		at, err = ParseTime(reading.Timestamp)
+	}
+	if err != nil {
+		return reading, err
+	}
+	reading.Timestamp = at.UTC().Format(time.RFC3339Nano)
+	return reading, nil
+}
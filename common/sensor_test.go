@@ -0,0 +1,22 @@
+package common
+
+import "testing"
+
+func TestTemperatureLimits(t *testing.T) {
+	low, high := 2.0, 8.0
+	limits := TemperatureLimits{MinTemperature: &low, MaxTemperature: &high}
+	if err := limits.Validate(); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if err := (TemperatureLimits{MinTemperature: &high, MaxTemperature: &low}).Validate(); err == nil {
+		t.Fatalf("expected inverted limits rejected")
+	}
+	for temperature, excursion := range map[float64]bool{1.9: true, 2: false, 8: false, 8.1: true} {
+		if found := limits.Check(SensorReading{TemperatureCelsius: temperature}) != nil; found != excursion {
+			t.Fatalf("%v: expected excursion %v", temperature, excursion)
+		}
+	}
+	if (TemperatureLimits{MaxTemperature: &high}).Check(SensorReading{TemperatureCelsius: -40}) != nil {
+		t.Fatalf("an unset limit must not be checked")
+	}
+}
@@ -0,0 +1,97 @@
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+//********************************************************************************************************
+// Shelf Life of Perishable Batches
+//********************************************************************************************************
+
+//Layout of the dates stored on a batch, UTC so that dates compare as strings
+const DateLayout = "2006-01-02T15:04:05Z"
+
+//ShelfLife holds the dates of a batch. Dates are RFC3339 or plain YYYY-MM-DD, empty when unknown.
+type ShelfLife struct {
+	ProductionDate string `json:"ProductionDate,omitempty"`
+	ExpiryDate     string `json:"ExpiryDate,omitempty"`
+	ShelfLifeDays  int    `json:"ShelfLifeDays,omitempty"`
+}
+
+//ParseDate reads an RFC3339 time or a YYYY-MM-DD date
+func ParseDate(value string) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at.UTC(), nil
+	}
+	at, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return at, fmt.Errorf("%q is not an RFC3339 time or a YYYY-MM-DD date", value)
+	}
+	return at, nil
+}
+
+//Normalize validates the dates, stores them in DateLayout and derives the expiry date
+//from the production date and the shelf life when it is not given
+func (life ShelfLife) Normalize() (ShelfLife, error) {
+	if life.ShelfLifeDays < 0 {
+		return life, fmt.Errorf("ShelfLifeDays must not be negative")
+	}
+	var produced, expires time.Time
+	var err error
+	if life.ProductionDate != "" {
+		if produced, err = ParseDate(life.ProductionDate); err != nil {
+			return life, fmt.Errorf("ProductionDate: %s", err.Error())
+		}
+		life.ProductionDate = produced.Format(DateLayout)
+	}
+	if life.ExpiryDate != "" {
+		if expires, err = ParseDate(life.ExpiryDate); err != nil {
+			return life, fmt.Errorf("ExpiryDate: %s", err.Error())
+		}
+	} else if life.ProductionDate != "" && life.ShelfLifeDays > 0 {
+		expires = produced.AddDate(0, 0, life.ShelfLifeDays)
+	}
+	if !expires.IsZero() {
+		if !produced.IsZero() && expires.Before(produced) {
+			return life, fmt.Errorf("ExpiryDate must not be before ProductionDate")
+		}
+		life.ExpiryDate = expires.Format(DateLayout)
+	}
+	return life, nil
+}
+
+//Expired tells if the batch is past its expiry date at a time, a batch without expiry date never expires
+func (life ShelfLife) Expired(at time.Time) bool {
+	expires, err := ParseDate(life.ExpiryDate)
+	return life.ExpiryDate != "" && err == nil && !at.Before(expires)
+}
+
+//ExpiresWithin tells if the batch expires within days of a time, already expired batches included
+func (life ShelfLife) ExpiresWithin(at time.Time, days int) bool {
+	return life.Expired(at.AddDate(0, 0, days))
+}
+
+//Earliest keeps the dates of the batch expiring first, for quantities mixed into one batch.
+//Dates that are unknown on one side are taken from the other.
+func (life ShelfLife) Earliest(other ShelfLife) ShelfLife {
+	if other.ExpiryDate != "" && (life.ExpiryDate == "" || other.ExpiryDate < life.ExpiryDate) {
+		life.ExpiryDate = other.ExpiryDate
+		life.ShelfLifeDays = other.ShelfLifeDays
+	}
+	if other.ProductionDate != "" && (life.ProductionDate == "" || other.ProductionDate < life.ProductionDate) {
+		life.ProductionDate = other.ProductionDate
+	}
+	return life
+}
+
+//TxTime is the time the client created the transaction, the same on every endorsing peer
+func TxTime(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	timestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(timestamp.GetSeconds(), int64(timestamp.GetNanos())).UTC(), nil
+}
@@ -0,0 +1,45 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShelfLifeNormalize(t *testing.T) {
+	life, err := ShelfLife{ProductionDate: "2020-01-01", ShelfLifeDays: 30}.Normalize()
+	if err != nil || life.ProductionDate != "2020-01-01T00:00:00Z" || life.ExpiryDate != "2020-01-31T00:00:00Z" {
+		t.Fatalf("unexpected shelf life %+v %v", life, err)
+	}
+	if life, err = (ShelfLife{ExpiryDate: "2020-01-31T12:00:00+02:00"}).Normalize(); err != nil || life.ExpiryDate != "2020-01-31T10:00:00Z" {
+		t.Fatalf("expected the expiry in UTC, got %+v %v", life, err)
+	}
+	for _, invalid := range []ShelfLife{
+		{ProductionDate: "31/01/2020"},
+		{ExpiryDate: "tomorrow"},
+		{ShelfLifeDays: -1},
+		{ProductionDate: "2020-02-01", ExpiryDate: "2020-01-01"},
+	} {
+		if _, err := invalid.Normalize(); err == nil {
+			t.Fatalf("expected %+v rejected", invalid)
+		}
+	}
+}
+
+func TestShelfLifeExpiry(t *testing.T) {
+	at := time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC)
+	life := ShelfLife{ExpiryDate: "2020-02-05T00:00:00Z"}
+	if life.Expired(at) || !life.Expired(at.AddDate(0, 0, 5)) || (ShelfLife{}).Expired(at) {
+		t.Fatalf("unexpected expiry of %+v", life)
+	}
+	if life.ExpiresWithin(at, 4) || !life.ExpiresWithin(at, 5) || !life.ExpiresWithin(at.AddDate(0, 1, 0), 0) {
+		t.Fatalf("unexpected expiry window of %+v", life)
+	}
+
+	earliest := life.Earliest(ShelfLife{ProductionDate: "2020-01-01T00:00:00Z", ExpiryDate: "2020-02-01T00:00:00Z", ShelfLifeDays: 31})
+	if earliest.ExpiryDate != "2020-02-01T00:00:00Z" || earliest.ProductionDate != "2020-01-01T00:00:00Z" || earliest.ShelfLifeDays != 31 {
+		t.Fatalf("unexpected earliest shelf life %+v", earliest)
+	}
+	if kept := earliest.Earliest(ShelfLife{}); kept != earliest {
+		t.Fatalf("unknown dates must not change the shelf life %+v", kept)
+	}
+}
@@ -0,0 +1,274 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//********************************************************************************************************
+// Typed Queries
+//********************************************************************************************************
+
+// Comparison operators a typed query filter may use, anything else is rejected.
+// $elemMatch is accepted on arrays of objects only.
+var filterOperators = map[string]bool{
+	"$eq": true, "$ne": true, "$gt": true, "$gte": true, "$lt": true, "$lte": true, "$in": true, "$nin": true,
+}
+
+// SortField orders the results of a typed query by one field
+type SortField struct {
+	Field     string `json:"Field"`
+	Direction string `json:"Direction,omitempty"` // asc (default) or desc
+}
+
+// TypedQuery is a structured query over one asset type.
+// Filters map a field name to a value (equality) or to an object of comparison operators, for example
+//
+//	{"AssetType":"PURCHASE ORDER","Filters":{"Quantity":{"$gte":10}},"Sort":[{"Field":"POID"}],"Limit":20}
+//	{"AssetType":"MATERIAL","Filters":{"Batches":{"$elemMatch":{"IsCompromised":true}}}}
+type TypedQuery struct {
+	AssetType string                 `json:"AssetType"`
+	Filters   map[string]interface{} `json:"Filters,omitempty"`
+	Sort      []SortField            `json:"Sort,omitempty"`
+	Limit     int32                  `json:"Limit,omitempty"`
+	Bookmark  string                 `json:"Bookmark,omitempty"`
+}
+
+// AssetSchema describes an asset type that may be queried
+type AssetSchema struct {
+	AssetType   string                     // Asset_Type value stored on the records
+	Fields      map[string]bool            // JSON names of the record fields
+	Elements    map[string]map[string]bool // JSON names of the element fields of arrays of objects
+	OwnerFields []string                   // Fields naming the Participants that may read a record, empty for shared records
+}
+
+// NewAssetSchema reads the queryable fields of an asset from the JSON tags of its struct
+func NewAssetSchema(assetType string, record interface{}, ownerFields ...string) AssetSchema {
+	schema := AssetSchema{AssetType: assetType, Fields: JSONFields(record), Elements: map[string]map[string]bool{}, OwnerFields: ownerFields}
+	recordType := structType(reflect.TypeOf(record))
+	for i := 0; i < recordType.NumField(); i++ {
+		field := recordType.Field(i)
+		name := jsonName(field)
+		if name != "" && field.Type.Kind() == reflect.Slice && structType(field.Type.Elem()).Kind() == reflect.Struct {
+			schema.Elements[name] = JSONFields(reflect.New(structType(field.Type.Elem())).Interface())
+		}
+	}
+	return schema
+}
+
+// JSONFields returns the JSON names of the exported fields of a struct
+func JSONFields(record interface{}) map[string]bool {
+	fields := map[string]bool{}
+	recordType := structType(reflect.TypeOf(record))
+	for i := 0; i < recordType.NumField(); i++ {
+		if name := jsonName(recordType.Field(i)); name != "" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// jsonName reads the JSON name of a struct field, empty when the field is not encoded
+func jsonName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	name := strings.TrimSpace(strings.Split(field.Tag.Get("json"), ",")[0])
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func structType(recordType reflect.Type) reflect.Type {
+	if recordType.Kind() == reflect.Ptr {
+		return recordType.Elem()
+	}
+	return recordType
+}
+
+// PageSize returns the page size requested by the query, fallback when no Limit is set
+func (query TypedQuery) PageSize(fallback int32) int32 {
+	if query.Limit > 0 {
+		return query.Limit
+	}
+	return fallback
+}
+
+// Selector validates the query against the schemas and builds the CouchDB query string.
+// A non empty participantID limits the results to the records owned by that Participant.
+func (query TypedQuery) Selector(schemas map[string]AssetSchema, participantID string) (string, error) {
+	schema, found := schemas[strings.ToUpper(strings.TrimSpace(query.AssetType))]
+	if !found {
+		return "", fmt.Errorf("unknown asset type %q", query.AssetType)
+	}
+	if query.Limit < 0 {
+		return "", fmt.Errorf("limit must not be negative")
+	}
+
+	selector := map[string]interface{}{"Asset_Type": schema.AssetType}
+	for field, value := range query.Filters {
+		if field == "Asset_Type" {
+			return "", fmt.Errorf("field Asset_Type is set by the asset type")
+		}
+		if !schema.Fields[field] {
+			return "", fmt.Errorf("unknown field %q for asset type %s", field, schema.AssetType)
+		}
+		if err := validateFilter(field, value, schema.Elements[field]); err != nil {
+			return "", err
+		}
+		selector[field] = value
+	}
+
+	// Restrict the results to the records the invoker may see
+	if participantID != "" && len(schema.OwnerFields) > 0 {
+		if _, filtered := selector[schema.OwnerFields[0]]; len(schema.OwnerFields) == 1 && !filtered {
+			selector[schema.OwnerFields[0]] = participantID
+		} else {
+			owners := []interface{}{}
+			for _, field := range schema.OwnerFields {
+				owners = append(owners, map[string]interface{}{field: participantID})
+			}
+			selector["$or"] = owners
+		}
+	}
+
+	sort := []interface{}{}
+	direction := ""
+	for _, element := range query.Sort {
+		if !schema.Fields[element.Field] {
+			return "", fmt.Errorf("unknown sort field %q for asset type %s", element.Field, schema.AssetType)
+		}
+		elementDirection := strings.ToLower(element.Direction)
+		if elementDirection == "" {
+			elementDirection = "asc"
+		}
+		if elementDirection != "asc" && elementDirection != "desc" {
+			return "", fmt.Errorf("sort direction must be asc or desc")
+		}
+		// CouchDB can only sort on one direction
+		if direction != "" && direction != elementDirection {
+			return "", fmt.Errorf("all sort fields must use the same direction")
+		}
+		direction = elementDirection
+		sort = append(sort, map[string]string{element.Field: elementDirection})
+
+		// CouchDB only sorts with an index whose fields are all in the selector
+		if _, filtered := selector[element.Field]; !filtered {
+			selector[element.Field] = map[string]interface{}{"$gt": nil}
+		}
+	}
+
+	couchQuery := map[string]interface{}{"selector": selector}
+	if len(sort) > 0 {
+		couchQuery["sort"] = sort
+	}
+	queryString, err := json.Marshal(couchQuery)
+	if err != nil {
+		return "", err
+	}
+	return string(queryString), nil
+}
+
+// ReadableBy applies the visibility of Selector to a record read by key, an empty participantID reads every record.
+// Records of a type without owner fields are shared, records of a type missing from the schemas are not readable.
+func ReadableBy(schemas map[string]AssetSchema, value []byte, participantID string) bool {
+	if participantID == "" {
+		return true
+	}
+	record := map[string]interface{}{}
+	if err := json.Unmarshal(value, &record); err != nil {
+		return false
+	}
+	assetType, _ := record["Asset_Type"].(string)
+	for _, schema := range schemas {
+		if schema.AssetType != assetType {
+			continue
+		}
+		if len(schema.OwnerFields) == 0 {
+			return true
+		}
+		for _, field := range schema.OwnerFields {
+			if owner, _ := record[field].(string); owner == participantID {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// validateFilter accepts a plain value or an object of comparison operators.
+// elements lists the fields $elemMatch may use, nil when the field is not an array of objects.
+func validateFilter(field string, value interface{}, elements map[string]bool) error {
+	switch filter := value.(type) {
+	case map[string]interface{}:
+		if len(filter) == 0 {
+			return fmt.Errorf("empty filter for field %s", field)
+		}
+		for operator, operand := range filter {
+			if operator == "$elemMatch" && elements != nil {
+				if err := validateElementMatch(field, operand, elements); err != nil {
+					return err
+				}
+				continue
+			}
+			if !filterOperators[operator] {
+				return fmt.Errorf("operator %q is not allowed on field %s", operator, field)
+			}
+			_, isList := operand.([]interface{})
+			if operator == "$in" || operator == "$nin" {
+				if !isList {
+					return fmt.Errorf("operator %s on field %s takes a list", operator, field)
+				}
+			} else if isList {
+				return fmt.Errorf("operator %s on field %s takes a single value", operator, field)
+			}
+			if err := validateOperand(field, operand); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		return fmt.Errorf("filter of field %s must be a value or an operator object", field)
+	default:
+		return nil
+	}
+}
+
+// validateElementMatch checks the conditions on the elements of an array of objects
+func validateElementMatch(field string, operand interface{}, elements map[string]bool) error {
+	conditions, isObject := operand.(map[string]interface{})
+	if !isObject || len(conditions) == 0 {
+		return fmt.Errorf("operator $elemMatch on field %s takes an object of element conditions", field)
+	}
+	for element, value := range conditions {
+		if !elements[element] {
+			return fmt.Errorf("unknown field %q in the elements of %s", element, field)
+		}
+		if err := validateFilter(field+"."+element, value, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateOperand rejects nested selectors inside an operator
+func validateOperand(field string, operand interface{}) error {
+	switch value := operand.(type) {
+	case map[string]interface{}:
+		return fmt.Errorf("nested objects are not allowed in the filter of field %s", field)
+	case []interface{}:
+		for _, element := range value {
+			if err := validateOperand(field, element); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
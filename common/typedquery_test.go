@@ -0,0 +1,160 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type queryLine struct {
+	MaterialID string `json:"MaterialID"`
+	Quantity   int    `json:"Quantity"`
+}
+
+type queryRecord struct {
+	Asset_Type string      `json:"Asset_Type,omitempty"`
+	OrderID    string      `json:"OrderID"`
+	Owner      string      `json:"Owner"`
+	Vendor     string      `json:"Vendor"`
+	Quantity   int         `json:"Quantity"`
+	LineItems  []queryLine `json:"LineItems,omitempty"`
+	internal   string
+}
+
+var querySchemas = map[string]AssetSchema{
+	"ORDER":   NewAssetSchema("ORDER", queryRecord{}, "Owner", "Vendor"),
+	"BATCH":   NewAssetSchema("BATCH", &queryRecord{}, "Owner"),
+	"PRODUCT": NewAssetSchema("PRODUCT", queryRecord{}),
+}
+
+func selectorOf(t *testing.T, queryJSON string, participantID string) (map[string]interface{}, error) {
+	t.Helper()
+	query := TypedQuery{}
+	if err := json.Unmarshal([]byte(queryJSON), &query); err != nil {
+		t.Fatal(err)
+	}
+	queryString, err := query.Selector(querySchemas, participantID)
+	if err != nil {
+		return nil, err
+	}
+	couchQuery := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(queryString), &couchQuery); err != nil {
+		t.Fatal(err)
+	}
+	return couchQuery, nil
+}
+
+func TestJSONFields(t *testing.T) {
+	fields := JSONFields(queryRecord{})
+	for _, name := range []string{"Asset_Type", "OrderID", "Owner", "Vendor", "Quantity", "LineItems"} {
+		if !fields[name] {
+			t.Fatalf("expected field %s in %v", name, fields)
+		}
+	}
+	if len(fields) != 6 {
+		t.Fatalf("unexported fields must be skipped %v", fields)
+	}
+}
+
+func TestTypedQuerySelector(t *testing.T) {
+	couchQuery, err := selectorOf(t, `{"AssetType":"batch","Filters":{"Quantity":{"$gte":10}},"Sort":[{"Field":"OrderID","Direction":"DESC"}]}`, "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, _ := json.Marshal(couchQuery)
+	expected := `{"selector":{"Asset_Type":"BATCH","OrderID":{"$gt":null},"Owner":"p1","Quantity":{"$gte":10}},"sort":[{"OrderID":"desc"}]}`
+	if string(result) != expected {
+		t.Fatalf("expected %s got %s", expected, result)
+	}
+}
+
+func TestTypedQueryElementMatch(t *testing.T) {
+	couchQuery, err := selectorOf(t, `{"AssetType":"PRODUCT","Filters":{"LineItems":{"$elemMatch":{"MaterialID":"MAT01","Quantity":{"$gt":5}}}}}`, "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, _ := json.Marshal(couchQuery["selector"])
+	if string(result) != `{"Asset_Type":"PRODUCT","LineItems":{"$elemMatch":{"MaterialID":"MAT01","Quantity":{"$gt":5}}}}` {
+		t.Fatalf("unexpected selector %s", result)
+	}
+}
+
+func TestTypedQueryVisibility(t *testing.T) {
+	// Several owner fields: the invoker may be any of them
+	couchQuery, err := selectorOf(t, `{"AssetType":"ORDER"}`, "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, _ := json.Marshal(couchQuery["selector"])
+	if string(result) != `{"$or":[{"Owner":"p1"},{"Vendor":"p1"}],"Asset_Type":"ORDER"}` {
+		t.Fatalf("unexpected selector %s", result)
+	}
+
+	// A filter on the owner field cannot widen the results
+	couchQuery, _ = selectorOf(t, `{"AssetType":"BATCH","Filters":{"Owner":"p2"}}`, "p1")
+	result, _ = json.Marshal(couchQuery["selector"])
+	if string(result) != `{"$or":[{"Owner":"p1"}],"Asset_Type":"BATCH","Owner":"p2"}` {
+		t.Fatalf("unexpected selector %s", result)
+	}
+
+	// Shared records and unrestricted callers
+	for _, query := range []struct{ json, participantID string }{{`{"AssetType":"PRODUCT"}`, "p1"}, {`{"AssetType":"ORDER"}`, ""}} {
+		couchQuery, _ = selectorOf(t, query.json, query.participantID)
+		selector := couchQuery["selector"].(map[string]interface{})
+		if len(selector) != 1 {
+			t.Fatalf("expected an unrestricted selector for %s, got %v", query.json, selector)
+		}
+	}
+}
+
+func TestReadableBy(t *testing.T) {
+	for _, test := range []struct {
+		value, participantID string
+		readable             bool
+	}{
+		{`{"Asset_Type":"ORDER","Owner":"p1","Vendor":"p2"}`, "p2", true},
+		{`{"Asset_Type":"ORDER","Owner":"p1","Vendor":"p2"}`, "p3", false},
+		{`{"Asset_Type":"ORDER","Owner":"p1","Vendor":"p2"}`, "", true},
+		{`{"Asset_Type":"PRODUCT","Owner":"p1"}`, "p3", true},
+		{`{"Asset_Type":"UNKNOWN","Owner":"p3"}`, "p3", false},
+		{`not json`, "p1", false},
+	} {
+		if readable := ReadableBy(querySchemas, []byte(test.value), test.participantID); readable != test.readable {
+			t.Fatalf("expected %s readable by %q to be %v", test.value, test.participantID, test.readable)
+		}
+	}
+}
+
+func TestTypedQueryValidation(t *testing.T) {
+	for _, queryJSON := range []string{
+		`{"AssetType":"UNKNOWN"}`,
+		`{"AssetType":"ORDER","Filters":{"Price":1}}`,
+		`{"AssetType":"ORDER","Filters":{"Asset_Type":"BATCH"}}`,
+		`{"AssetType":"ORDER","Filters":{"Owner":{"$regex":".*"}}}`,
+		`{"AssetType":"ORDER","Filters":{"Owner":{"$eq":{"$gt":""}}}}`,
+		`{"AssetType":"ORDER","Filters":{"Owner":{"$in":"p1"}}}`,
+		`{"AssetType":"ORDER","Filters":{"Owner":{"$eq":["p1"]}}}`,
+		`{"AssetType":"ORDER","Filters":{"Owner":{}}}`,
+		`{"AssetType":"ORDER","Filters":{"Owner":["p1"]}}`,
+		`{"AssetType":"ORDER","Filters":{"Owner":{"$elemMatch":{"MaterialID":"MAT01"}}}}`,
+		`{"AssetType":"ORDER","Filters":{"LineItems":{"$elemMatch":{"Price":1}}}}`,
+		`{"AssetType":"ORDER","Filters":{"LineItems":{"$elemMatch":{"MaterialID":{"$elemMatch":{}}}}}}`,
+		`{"AssetType":"ORDER","Filters":{"LineItems":{"$elemMatch":"MAT01"}}}`,
+		`{"AssetType":"ORDER","Sort":[{"Field":"Price"}]}`,
+		`{"AssetType":"ORDER","Sort":[{"Field":"Owner","Direction":"up"}]}`,
+		`{"AssetType":"ORDER","Sort":[{"Field":"Owner"},{"Field":"OrderID","Direction":"desc"}]}`,
+		`{"AssetType":"ORDER","Limit":-1}`,
+	} {
+		if _, err := selectorOf(t, queryJSON, "p1"); err == nil {
+			t.Fatalf("expected %s to be rejected", queryJSON)
+		}
+	}
+}
+
+func TestTypedQueryPageSize(t *testing.T) {
+	if size := (TypedQuery{}).PageSize(DefaultPageSize); size != DefaultPageSize {
+		t.Fatalf("expected the fallback page size, got %d", size)
+	}
+	if size := (TypedQuery{Limit: 5}).PageSize(DefaultPageSize); size != 5 {
+		t.Fatalf("expected the limit as page size, got %d", size)
+	}
+}